@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// directiveFileEnv names the file the shell integration reads directives
+// from once git wt exits. The wrapper creates the file and leaves stdout
+// alone, so output of git wt and its hooks reaches the terminal unchanged.
+// Each line is a tab-separated directive:
+//
+//	cd	<path>			change to path
+//	created	1			the worktree at the cd path was just created
+//	env	<name>	<value>		export name=value
+//
+// Hooks inherit the variable and may append env directives themselves.
+// Without the variable, the cd target is printed as the last line of stdout
+// instead (the protocol of older wrappers).
+const directiveFileEnv = "GIT_WT_DIRECTIVE_FILE"
+
+// printWorktreePath hands path, a worktree git wt switched to or created, to
+// the shell integration. Without a directive file, it is printed to stdout,
+// where 'cd "$(git wt feature)"' and older wrappers expect it.
+func printWorktreePath(path string, created bool) {
+	if writeCdDirective(path, created) {
+		return
+	}
+	fmt.Println(path)
+}
+
+// requestCd asks the shell integration to cd to path, e.g. after the current
+// worktree was deleted or moved. Without a directive file, path is printed
+// only under the shell integration, as stdout is otherwise meant for humans.
+func requestCd(path string) {
+	if writeCdDirective(path, false) {
+		return
+	}
+	if os.Getenv("GIT_WT_SHELL_INTEGRATION") == "1" {
+		fmt.Println(path)
+	}
+}
+
+// writeCdDirective appends a cd directive (and a created directive if
+// created) to the directive file. It reports false if there is no directive
+// file or it cannot be written, so the caller falls back to stdout.
+func writeCdDirective(path string, created bool) bool {
+	name := os.Getenv(directiveFileEnv)
+	if name == "" {
+		return false
+	}
+	if strings.ContainsAny(path, "\t\n") {
+		// Not representable as a directive.
+		return false
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "cd\t%s\n", path)
+	if created {
+		b.WriteString("created\t1\n")
+	}
+	// Append: hooks run before this may have written env directives.
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write directive file: %v\n", err)
+		return false
+	}
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write directive file: %v\n", err)
+		return false
+	}
+	return true
+}
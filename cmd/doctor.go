@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// doctorFix is a planned fix of a problem found by git.Diagnose.
+type doctorFix struct {
+	problem git.Problem
+	skip    string // reason the problem is left alone, empty if it will be fixed
+}
+
+// doctor reports (or, with fix, repairs) orphaned directories under basedir
+// and registrations whose worktree directory is missing. Moved worktrees are
+// repaired, dangling registrations pruned and empty directories removed;
+// orphaned worktree directories are removed only with force (-D), and
+// directories that are not worktrees are never touched.
+func doctor(ctx context.Context, cmd *cobra.Command, fix, force bool) error {
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand basedir: %w", err)
+	}
+	problems, err := git.Diagnose(ctx, baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect worktrees: %w", err)
+	}
+
+	fixes := make([]doctorFix, 0, len(problems))
+	var count int
+	for _, p := range problems {
+		f := doctorFix{problem: p, skip: doctorSkipReason(p, force)}
+		if f.skip == "" {
+			count++
+		}
+		fixes = append(fixes, f)
+	}
+
+	if !fix {
+		if jsonFlag {
+			if problems == nil {
+				problems = []git.Problem{}
+			}
+			return printJSON(os.Stdout, problems)
+		}
+		if len(problems) == 0 {
+			fmt.Println("No problems found.")
+			return nil
+		}
+		printDoctorPlan(os.Stdout, fixes)
+		if count > 0 {
+			fmt.Println("Run 'git wt --gc' to fix them.")
+		}
+		return nil
+	}
+
+	// As with runCleanup, the plan goes to stderr so that it is visible even
+	// when stdout is captured by the shell integration.
+	printDoctorPlan(os.Stderr, fixes)
+	if count == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to fix.")
+		return nil
+	}
+	if dryRunFlag {
+		fmt.Fprintln(os.Stderr, "Dry run: no changes were made.")
+		return nil
+	}
+	if !yesFlag && isTerminal(os.Stdin) {
+		ok, err := confirm(os.Stdin, os.Stderr, fmt.Sprintf("Fix %d problem(s)?", count))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+	}
+
+	// Repair moved worktrees first: pruning would otherwise drop their
+	// registrations.
+	prune := false
+	for _, f := range fixes {
+		if f.skip != "" {
+			continue
+		}
+		switch f.problem.Kind {
+		case git.ProblemMovedWorktree:
+			if err := git.RepairWorktree(ctx, f.problem.Path); err != nil {
+				return fmt.Errorf("failed to repair worktree %q: %w", f.problem.Path, err)
+			}
+			fmt.Printf("Repaired worktree %q (moved from %q)\n", f.problem.Path, f.problem.OldPath)
+		case git.ProblemDanglingRegistration:
+			prune = true
+		}
+	}
+	if prune {
+		if err := git.PruneWorktrees(ctx); err != nil {
+			return fmt.Errorf("failed to prune worktrees: %w", err)
+		}
+		for _, f := range fixes {
+			if f.skip == "" && f.problem.Kind == git.ProblemDanglingRegistration {
+				fmt.Printf("Pruned registration of missing worktree %q\n", f.problem.Path)
+			}
+		}
+	}
+	for _, f := range fixes {
+		if f.skip != "" {
+			continue
+		}
+		switch f.problem.Kind {
+		case git.ProblemEmptyDirectory, git.ProblemOrphanedWorktree:
+			if err := git.RemoveOrphanedDir(f.problem, baseDir); err != nil {
+				return fmt.Errorf("failed to remove %q: %w", f.problem.Path, err)
+			}
+			fmt.Printf("Removed %q\n", f.problem.Path)
+		}
+	}
+	return nil
+}
+
+// doctorSkipReason returns why --gc must leave the problem p alone, or an
+// empty string if it can be fixed.
+func doctorSkipReason(p git.Problem, force bool) string {
+	switch p.Kind {
+	case git.ProblemDanglingRegistration:
+		if p.Locked {
+			if p.LockReason != "" {
+				return fmt.Sprintf("locked: %s", p.LockReason)
+			}
+			return "locked"
+		}
+	case git.ProblemOrphanedWorktree:
+		if !force {
+			return "may contain uncommitted work, use -D to force"
+		}
+	case git.ProblemUnknownDirectory:
+		return "not a worktree, remove it manually if unneeded"
+	}
+	return ""
+}
+
+func printDoctorPlan(w io.Writer, fixes []doctorFix) {
+	if len(fixes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Worktree problems:")
+	for _, f := range fixes {
+		p := f.problem
+		var action, target string
+		switch p.Kind {
+		case git.ProblemDanglingRegistration:
+			action = "prune"
+			target = fmt.Sprintf("registration of missing worktree %q", p.Path)
+			if p.Branch != "" {
+				target += fmt.Sprintf(" (branch %q)", p.Branch)
+			}
+		case git.ProblemMovedWorktree:
+			action = "repair"
+			target = fmt.Sprintf("worktree %q moved from %q", p.Path, p.OldPath)
+		case git.ProblemOrphanedWorktree:
+			action = "remove"
+			target = fmt.Sprintf("orphaned worktree directory %q", p.Path)
+		case git.ProblemEmptyDirectory:
+			action = "remove"
+			target = fmt.Sprintf("empty directory %q", p.Path)
+		default:
+			target = fmt.Sprintf("directory %q", p.Path)
+		}
+		if f.skip != "" {
+			fmt.Fprintf(w, "  skip    %s: %s\n", target, f.skip)
+			continue
+		}
+		fmt.Fprintf(w, "  %-7s %s\n", action, target)
+	}
+}
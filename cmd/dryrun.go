@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/k1LoW/git-wt/internal/git"
+)
+
+// Actions reported by --dry-run.
+const (
+	actionSaveToTrash        = "save_to_trash"
+	actionRunHook            = "run_hook"
+	actionRunRemover         = "run_remover"
+	actionRemoveWorktree     = "remove_worktree"
+	actionDeleteBranch       = "delete_branch"
+	actionKeepBranch         = "keep_branch"
+	actionMoveWorktree       = "move_worktree"
+	actionRemoveEmptyParents = "remove_empty_parents"
+	actionRenameBranch       = "rename_branch"
+	actionChangeDirectory    = "cd"
+)
+
+// plannedChange is what a delete or move would do to one target. It is
+// printed by --dry-run (as text, or as JSON with --json).
+type plannedChange struct {
+	Target    string          `json:"target"`
+	Operation string          `json:"operation"`
+	Path      string          `json:"path,omitempty"`
+	Branch    string          `json:"branch,omitempty"`
+	Actions   []plannedAction `json:"actions"`
+}
+
+// plannedAction is a single step of a plannedChange. Only the fields
+// relevant to Action are set.
+type plannedAction struct {
+	Action    string `json:"action"`
+	Command   string `json:"command,omitempty"`
+	Path      string `json:"path,omitempty"`
+	NewPath   string `json:"new_path,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	NewBranch string `json:"new_branch,omitempty"`
+	Force     bool   `json:"force,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// printPlan prints the planned changes of a dry run, as JSON when asJSON is
+// set.
+func printPlan(w io.Writer, plans []plannedChange, asJSON bool) error {
+	if asJSON {
+		if plans == nil {
+			plans = []plannedChange{}
+		}
+		return printJSON(w, plans)
+	}
+	for _, p := range plans {
+		fmt.Fprintf(w, "Would %s %q:\n", p.Operation, p.Target)
+		for _, a := range p.Actions {
+			fmt.Fprintf(w, "  %s\n", a.describe())
+		}
+	}
+	fmt.Fprintln(w, "Dry run: no changes were made.")
+	return nil
+}
+
+func (a plannedAction) describe() string {
+	switch a.Action {
+	case actionSaveToTrash:
+		return fmt.Sprintf("save branch tip and uncommitted changes of %s to trash", a.Path)
+	case actionRunHook:
+		return fmt.Sprintf("run hook %q in %s", a.Command, a.Path)
+	case actionRunRemover:
+		return fmt.Sprintf("run remover %q on %s, then 'git worktree prune'", a.Command, a.Path)
+	case actionRemoveWorktree:
+		if a.Reason != "" {
+			return fmt.Sprintf("remove worktree %s (%s)", a.Path, a.Reason)
+		}
+		return fmt.Sprintf("remove worktree %s", a.Path)
+	case actionDeleteBranch:
+		if a.Force {
+			return fmt.Sprintf("delete branch %q", a.Branch)
+		}
+		return fmt.Sprintf("delete branch %q if merged ('git branch -d')", a.Branch)
+	case actionKeepBranch:
+		return fmt.Sprintf("keep branch %q (%s)", a.Branch, a.Reason)
+	case actionMoveWorktree:
+		return fmt.Sprintf("move worktree %s to %s", a.Path, a.NewPath)
+	case actionRemoveEmptyParents:
+		return fmt.Sprintf("remove %s and its parents under basedir if empty", a.Path)
+	case actionRenameBranch:
+		return fmt.Sprintf("rename branch %q to %q", a.Branch, a.NewBranch)
+	case actionChangeDirectory:
+		return fmt.Sprintf("change directory to %s (shell integration)", a.Path)
+	}
+	return a.Action
+}
+
+// planWorktreeDelete returns the actions deleteWorktrees takes for a target
+// resolved to worktree wt, mirroring the decisions of the real run.
+func planWorktreeDelete(target string, wt *git.Worktree, cfg git.Config, force, branchExists, isDefault, forceBranch, current bool, mainRoot string) plannedChange {
+	plan := plannedChange{Target: target, Operation: "delete", Path: wt.Path, Branch: wt.Branch}
+	switch {
+	case wt.Prunable:
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionRemoveWorktree, Path: wt.Path, Force: force, Reason: "directory is missing"})
+	default:
+		if force {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionSaveToTrash, Path: wt.Path})
+		}
+		for _, hook := range cfg.DeleteHooks {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionRunHook, Command: hook, Path: wt.Path})
+		}
+		if cfg.Remover != "" {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionRunRemover, Command: cfg.Remover, Path: wt.Path})
+		} else {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionRemoveWorktree, Path: wt.Path, Force: force})
+		}
+	}
+
+	switch {
+	case wt.Branch == "" || wt.Branch == git.DetachedMarker:
+		// Detached HEAD: there is no branch to delete.
+	case !branchExists:
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionKeepBranch, Branch: wt.Branch, Reason: "does not exist locally"})
+	case isDefault && !allowDeleteDefault:
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionKeepBranch, Branch: wt.Branch, Reason: "default branch"})
+	default:
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionDeleteBranch, Branch: wt.Branch, Force: forceBranch})
+	}
+
+	for _, hook := range cfg.PostDeleteHooks {
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionRunHook, Command: hook, Path: mainRoot})
+	}
+
+	if current {
+		plan.Actions = append(plan.Actions, plannedAction{Action: actionChangeDirectory, Path: mainRoot})
+	}
+	return plan
+}
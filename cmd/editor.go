@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/k1LoW/git-wt/internal/git"
+)
+
+// openEditor launches the editor for dir, the worktree git wt switched to or
+// created (a subdirectory of it with wt.relative), with --open. The editor is
+// wt.editor, falling back to $VISUAL and $EDITOR. As stdout may be captured
+// by older shell integrations, the editor writes to stderr unless stdout is a
+// terminal.
+func openEditor(ctx context.Context, cfg git.Config, dir, branch string) error {
+	editor := cfg.Editor
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("no editor to open %s: set wt.editor (or $VISUAL/$EDITOR)", dir)
+	}
+	var stdout io.Writer = os.Stderr
+	if isTerminal(os.Stdout) {
+		stdout = os.Stdout
+	}
+	return git.RunEditor(ctx, editor, dir, branch, stdout, os.Stderr)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// runEphemeral runs the command args in a throwaway worktree on a temporary
+// branch created from startPoint (HEAD if empty), with files copied and hooks
+// run as for any new worktree. Afterwards, the commits made are listed, and
+// the diff from startPoint (commits and uncommitted changes) is printed to
+// stdout or, with --patch, saved to a file. The worktree and its branch are
+// deleted even if the command fails or is interrupted, and git wt exits with
+// the exit code of the command.
+func runEphemeral(ctx context.Context, cmd *cobra.Command, startPoint string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--ephemeral requires a command: git wt --ephemeral [<start-point>] -- <command>")
+	}
+	cfg, err := worktreeConfig(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if startPoint == "" {
+		startPoint = "HEAD"
+	}
+	base, err := git.ResolveCommit(ctx, startPoint)
+	if err != nil {
+		return fmt.Errorf("invalid start-point %q: %w", startPoint, err)
+	}
+	name, err := ephemeralName(time.Now())
+	if err != nil {
+		return err
+	}
+
+	changes, code, err := runInEphemeralWorktree(ctx, cmd, cfg, name, base, args)
+	if changes != nil {
+		if perr := printChanges(name, changes); perr != nil {
+			err = errors.Join(err, perr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		cmd.SilenceErrors = true
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+// runInEphemeralWorktree creates the worktree name on a new branch of the
+// same name from base, runs the command args in it and collects the changes
+// it made. The worktree and branch are deleted before it returns, whatever
+// happened. Ctrl-C and SIGTERM stop the setup, but reach the command itself.
+func runInEphemeralWorktree(ctx context.Context, cmd *cobra.Command, cfg git.Config, name, base string, args []string) (changes *git.WorktreeChanges, code int, err error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Tearing down and collecting the changes must not be cut short by the
+	// signal that ended the command.
+	cleanupCtx := context.WithoutCancel(ctx)
+	defer func() {
+		if terr := removeEphemeral(cleanupCtx, cmd, name); terr != nil {
+			err = errors.Join(err, terr)
+		}
+	}()
+
+	wtPath, err := git.WorktreePathFor(ctx, cfg.BaseDir, name)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to get worktree path: %w", err)
+	}
+	env := createHookEnv(ctx, cfg, wtPath, name, base, true)
+	if err := runPreCreateHooks(ctx, cfg, env); err != nil {
+		return nil, -1, err
+	}
+	if err := git.AddWorktreeWithNewBranch(ctx, wtPath, name, base, copyOptions(cfg)); err != nil {
+		return nil, -1, fmt.Errorf("failed to create worktree with new branch: %w", err)
+	}
+	if err := runCreateHooks(ctx, cfg, wtPath, env, false); err != nil {
+		return nil, -1, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, -1, fmt.Errorf("interrupted before running the command: %w", err)
+	}
+
+	code, err = git.RunForegroundCommand(cleanupCtx, resolveRelative(ctx, wtPath, cfg.Relative), args, os.Stdout, os.Stderr)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to run command in %s: %w", wtPath, err)
+	}
+	changes, err = git.CollectChanges(cleanupCtx, wtPath, base)
+	if err != nil {
+		return nil, code, fmt.Errorf("failed to collect the changes: %w", err)
+	}
+	return changes, code, nil
+}
+
+// removeEphemeral deletes the worktree and branch name, if they were created
+// at all, as 'git wt -D' does, except that deletions are reported to stderr,
+// as stdout is for the patch, and nothing is kept in the trash, as the
+// changes are in the patch already.
+func removeEphemeral(ctx context.Context, cmd *cobra.Command, name string) error {
+	wt, err := git.FindWorktreeByBranch(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to find worktree: %w", err)
+	}
+	exists, err := git.LocalBranchExists(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to check branch existence: %w", err)
+	}
+	if wt == nil && !exists {
+		return nil
+	}
+	return deleteWorktrees(ctx, cmd, []string{name}, true, deleteOptions{out: os.Stderr, noTrash: true})
+}
+
+// printChanges reports the commits and diffstat of changes to stderr, and
+// prints the patch to stdout or saves it to the --patch file.
+func printChanges(name string, changes *git.WorktreeChanges) error {
+	if changes.Patch == "" && len(changes.Commits) == 0 {
+		fmt.Fprintf(os.Stderr, "No changes in %s\n", name)
+	} else {
+		if len(changes.Commits) > 0 {
+			fmt.Fprintf(os.Stderr, "Commits in %s:\n", name)
+			for _, c := range changes.Commits {
+				fmt.Fprintf(os.Stderr, "  %s\n", c)
+			}
+		}
+		fmt.Fprint(os.Stderr, changes.Stat)
+	}
+	if patchFlag == "" {
+		fmt.Print(changes.Patch)
+		return nil
+	}
+	if err := os.WriteFile(patchFlag, []byte(changes.Patch), 0600); err != nil {
+		return fmt.Errorf("failed to save the changes: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Saved the changes to %s (apply with 'git apply %s')\n", patchFlag, patchFlag)
+	return nil
+}
+
+// ephemeralName returns a unique name for an ephemeral worktree created at
+// now, e.g. "ephemeral-20250102-150405-1a2b".
+func ephemeralName(now time.Time) (string, error) {
+	b := make([]byte, 2)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a worktree name: %w", err)
+	}
+	return "ephemeral-" + now.Format("20060102-150405") + "-" + hex.EncodeToString(b), nil
+}
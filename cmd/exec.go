@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeError makes git wt exit with code without printing an error, for
+// commands whose exit code is passed on (see Execute).
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runExec runs the command args in the worktree of target (a branch,
+// worktree name or path), creating it from startPoint like
+// 'git wt <target> [<start-point>]' would, including copying files and
+// running hooks, but without resolving abbreviations. The command runs in
+// the foreground, and git wt exits with its exit code. Neither a directory
+// change nor the switch history is affected, so that scripts can use it
+// without the shell integration.
+func runExec(ctx context.Context, cmd *cobra.Command, target, startPoint string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--exec requires a command: git wt --exec <branch|worktree|path> -- <command>")
+	}
+	cfg, err := worktreeConfig(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	branchName := branchFlag
+	if branchName == "" {
+		branchName = target
+	}
+	wt, err := ensureWorktree(ctx, cfg, target, branchName, startPoint, false, false)
+	if err != nil {
+		return err
+	}
+
+	// The command is not part of the shell integration: a git wt it runs must
+	// not make the calling shell change directories.
+	_ = os.Unsetenv(directiveFileEnv)
+	_ = os.Unsetenv("GIT_WT_SHELL_INTEGRATION")
+	code, err := git.RunForegroundCommand(ctx, resolveRelative(ctx, wt.path, cfg.Relative), args, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to run command in %s: %w", wt.path, err)
+	}
+	if code != 0 {
+		cmd.SilenceErrors = true
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
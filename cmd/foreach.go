@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// foreachResultJSON is an entry of the --foreach --json report.
+type foreachResultJSON struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Branch     string `json:"branch"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// foreachTarget is a worktree --foreach runs the command in.
+type foreachTarget struct {
+	name string // label of the output lines
+	wt   git.Worktree
+}
+
+// foreachResult is the outcome of the command in a worktree.
+type foreachResult struct {
+	exitCode int // -1 if the command could not be run
+	err      error
+	duration time.Duration
+}
+
+// runForeach runs the command args in each worktree (except bare entries)
+// whose name or branch matches the --filter glob, at most parallel at a
+// time. Every output line is prefixed with the worktree name, and a summary
+// of the exit codes follows (JSON with --json, in which case the command
+// output goes to stderr). It fails if the command failed in any worktree.
+func runForeach(ctx context.Context, cmd *cobra.Command, args []string, parallel int, filter string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--foreach requires a command: git wt --foreach -- <command>")
+	}
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1, got %d", parallel)
+	}
+	if filter != "" {
+		if _, err := path.Match(filter, ""); err != nil {
+			return fmt.Errorf("invalid --filter pattern %q: %w", filter, err)
+		}
+	}
+	targets, err := foreachTargets(ctx, cmd, filter)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		if filter != "" {
+			return fmt.Errorf("no worktrees match %q", filter)
+		}
+		return fmt.Errorf("no worktrees to run the command in")
+	}
+
+	// Commands run in their own process groups; stop them on Ctrl-C rather
+	// than leaving them behind.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var stdout io.Writer = os.Stdout
+	if jsonFlag {
+		stdout = os.Stderr
+	}
+	width := 0
+	for _, t := range targets {
+		width = max(width, len(t.name))
+	}
+	var mu sync.Mutex // serializes output lines across worktrees
+	results := make([]foreachResult, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		// Acquire before starting, so that worktrees are run in list order.
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			results[i] = foreachResult{exitCode: -1, err: err}
+			continue
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			prefix := fmt.Sprintf("%-*s | ", width, t.name)
+			out := &prefixWriter{mu: &mu, w: stdout, prefix: prefix}
+			errOut := &prefixWriter{mu: &mu, w: os.Stderr, prefix: prefix}
+			start := time.Now()
+			code, err := git.RunCommand(ctx, t.wt.Path, args, out, errOut)
+			out.Flush()
+			errOut.Flush()
+			results[i] = foreachResult{exitCode: code, err: err, duration: time.Since(start)}
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.exitCode != 0 {
+			failed++
+		}
+	}
+	if jsonFlag {
+		items := make([]foreachResultJSON, len(targets))
+		for i, t := range targets {
+			items[i] = foreachResultJSON{
+				Name:       t.name,
+				Path:       t.wt.Path,
+				Branch:     t.wt.Branch,
+				ExitCode:   results[i].exitCode,
+				DurationMs: results[i].duration.Milliseconds(),
+			}
+			if err := results[i].err; err != nil {
+				items[i].Error = err.Error()
+			}
+		}
+		if err := printJSON(os.Stdout, items); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(os.Stdout)
+		table := newTable(os.Stdout, []string{"WORKTREE", "BRANCH", "RESULT", "DURATION"})
+		for i, t := range targets {
+			if err := table.Append([]string{t.name, t.wt.Branch, results[i].String(), results[i].duration.Round(time.Millisecond).String()}); err != nil {
+				return fmt.Errorf("failed to append row: %w", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			return fmt.Errorf("failed to render table: %w", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d worktree(s)", failed, len(targets))
+	}
+	return nil
+}
+
+func (r foreachResult) String() string {
+	switch {
+	case r.err != nil:
+		return fmt.Sprintf("error: %v", r.err)
+	case r.exitCode != 0:
+		return fmt.Sprintf("exit %d", r.exitCode)
+	}
+	return "ok"
+}
+
+// foreachTargets returns the worktrees --foreach runs in, in the order of
+// 'git worktree list'. A worktree is named by its directory relative to
+// basedir, or its directory name outside basedir (e.g., the main working
+// tree); the filter glob matches the name or the branch.
+func foreachTargets(ctx context.Context, cmd *cobra.Command, filter string) ([]foreachTarget, error) {
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand basedir: %w", err)
+	}
+	worktrees, err := git.ListWorktrees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	var targets []foreachTarget
+	for _, wt := range worktrees {
+		if wt.Bare || wt.Prunable {
+			continue
+		}
+		name := worktreeName(baseDir, wt.Path)
+		if filter != "" && !globMatch(filter, name) && !globMatch(filter, wt.Branch) {
+			continue
+		}
+		targets = append(targets, foreachTarget{name: name, wt: wt})
+	}
+	return targets, nil
+}
+
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// prefixWriter writes complete lines to w, each prefixed with prefix. Lines
+// of writers sharing mu are not interleaved.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return len(b), err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes the last line if it is not terminated by a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		_ = p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
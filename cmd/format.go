@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// formatEscapes expands the escape sequences allowed in --format so that
+// templates can be written in single-quoted shell strings.
+var formatEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// printFormat renders each item with the Go template format, one per line.
+func printFormat(w io.Writer, format string, items []worktreeJSON) error {
+	tmpl, err := template.New("format").Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return fmt.Errorf("invalid format %q: %w", format, err)
+	}
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("failed to execute format %q: %w", format, err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
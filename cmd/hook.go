@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+)
+
+// newHookEnv returns the environment of the hooks run for event on the
+// worktree at path on branch (empty if detached). The worktree git wt was run
+// in is the source of precreate, create and switch hooks. Information that
+// cannot be determined is left empty rather than failing the hooks.
+func newHookEnv(ctx context.Context, cfg git.Config, event, path, branch string) git.HookEnv {
+	env := git.HookEnv{
+		Event:  event,
+		Path:   path,
+		Branch: branch,
+		Name:   filepath.Base(path),
+	}
+	if baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir); err == nil {
+		env.Name = worktreeName(baseDir, path)
+	}
+	if mainRoot, err := git.MainRepoRoot(ctx); err == nil {
+		env.MainRoot = mainRoot
+	}
+	switch event {
+	case git.HookEventPreCreate, git.HookEventCreate, git.HookEventSwitch:
+		if bare, err := git.IsBareRoot(ctx); err == nil && !bare {
+			if src, err := git.CurrentWorktree(ctx); err == nil {
+				env.SourcePath = src
+			}
+		}
+	}
+	return env
+}
+
+// createHookEnv returns the environment of the hooks run for the new
+// worktree at path on branch (see newHookEnv), created from startPoint.
+func createHookEnv(ctx context.Context, cfg git.Config, path, branch, startPoint string, newBranch bool) git.HookEnv {
+	env := newHookEnv(ctx, cfg, git.HookEventCreate, path, branch)
+	env.StartPoint = startPoint
+	env.NewBranch = newBranch
+	return env
+}
+
+// runPreCreateHooks runs the precreate hooks for the worktree described by
+// the create hook environment env, before it is created. They run in the main
+// working tree, as the worktree does not exist yet; if one fails, the
+// worktree must not be created.
+func runPreCreateHooks(ctx context.Context, cfg git.Config, env git.HookEnv) error {
+	env.Event = git.HookEventPreCreate
+	if err := git.RunHooks(ctx, cfg.PreCreateHooks, env.MainRoot, env, cfg.HookTimeout, os.Stderr); err != nil {
+		return fmt.Errorf("pre-create hook rejected worktree %q: %w", env.Name, err)
+	}
+	return nil
+}
+
+// runCreateHooks runs the hooks of the new worktree at dir with env as
+// wt.hookmode says: one after another, concurrently, or in the background.
+// Hooks prefixed with "&" run in the background in any mode. Unless
+// background is set (e.g., for --exec, whose command needs the hooks done),
+// background hooks run in the foreground after the others instead.
+func runCreateHooks(ctx context.Context, cfg git.Config, dir string, env git.HookEnv, background bool) error {
+	foreground, detached := git.SplitHooks(cfg.Hooks, cfg.HookMode)
+	if !background {
+		foreground, detached = append(foreground, detached...), nil
+	}
+	if cfg.HookMode == git.HookModeParallel {
+		if err := git.RunHooksParallel(ctx, foreground, dir, env, cfg.HookTimeout, os.Stderr); err != nil {
+			return err
+		}
+	} else if err := git.RunHooks(ctx, foreground, dir, env, cfg.HookTimeout, os.Stderr); err != nil {
+		return err
+	}
+	if len(detached) == 0 {
+		return nil
+	}
+	started, err := git.StartBackgroundHooks(ctx, detached, dir, env)
+	if err != nil {
+		return fmt.Errorf("failed to start background hooks: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Running %d hook(s) in the background (logs: %s)\n", len(started), filepath.Dir(started[0].Log))
+	return nil
+}
+
+// reportBackgroundHooks warns about the background hooks of the worktree at
+// path (of all worktrees if path is empty) that failed since the last report.
+// Problems only produce a warning, as the command itself can go on.
+func reportBackgroundHooks(ctx context.Context, path string) {
+	results, err := git.CheckBackgroundHooks(ctx, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to check background hooks: %v\n", err)
+		return
+	}
+	for _, r := range results {
+		switch {
+		case r.Expired:
+			fmt.Fprintf(os.Stderr, "warning: background hook %q for %s has not finished since %s, no longer waiting for it (log: %s)\n", r.Hook, r.Path, r.StartedAt.Local().Format(time.DateTime), r.Log)
+		case r.ExitCode < 0:
+			fmt.Fprintf(os.Stderr, "warning: background hook %q for %s was killed (log: %s)\n", r.Hook, r.Path, r.Log)
+		case r.ExitCode > 0:
+			fmt.Fprintf(os.Stderr, "warning: background hook %q for %s failed with exit code %d (log: %s)\n", r.Hook, r.Path, r.ExitCode, r.Log)
+		}
+	}
+}
+
+// deleteHookEnv returns the environment of the delete hooks and the remover
+// run for wt. baseDir is the expanded basedir.
+func deleteHookEnv(wt *git.Worktree, baseDir, mainRoot string) git.HookEnv {
+	branch := wt.Branch
+	if branch == git.DetachedMarker {
+		branch = ""
+	}
+	return git.HookEnv{
+		Event:    git.HookEventDelete,
+		Path:     wt.Path,
+		Branch:   branch,
+		Name:     worktreeName(baseDir, wt.Path),
+		MainRoot: mainRoot,
+	}
+}
+
+// worktreeName names the worktree at path by its directory relative to the
+// expanded baseDir, or by its directory name outside baseDir (e.g., the main
+// working tree).
+func worktreeName(baseDir, path string) string {
+	if rel, err := filepath.Rel(baseDir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// shownHookRuns is the number of the latest hook runs --logs shows.
+const shownHookRuns = 10
+
+// showHookLogs prints the latest hook runs recorded for the worktree of
+// target (a branch, worktree name or path), with their output. The runs of a
+// deleted worktree are found by its name or path. With --json, the metadata of
+// all recorded runs is printed instead.
+func showHookLogs(ctx context.Context, cmd *cobra.Command, target string) error {
+	cfg, err := worktreeConfig(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	path, err := hookLogPath(ctx, cfg, target)
+	if err != nil {
+		return err
+	}
+	// Finished background hooks get their end recorded (and are reported).
+	reportBackgroundHooks(ctx, path)
+	runs, err := git.HookRuns(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to read hook logs: %w", err)
+	}
+
+	if jsonFlag {
+		if runs == nil {
+			runs = []git.HookRun{}
+		}
+		return printJSON(os.Stdout, runs)
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no hook runs recorded for %q", target)
+	}
+	if len(runs) > shownHookRuns {
+		runs = runs[len(runs)-shownHookRuns:]
+	}
+	for i, r := range runs {
+		if i > 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		if err := printHookRun(os.Stdout, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hookLogPath resolves target to the path of the worktree whose hook logs to
+// show. Targets that are not an existing worktree are taken as the path, or
+// the name under basedir, of a deleted one.
+func hookLogPath(ctx context.Context, cfg git.Config, target string) (string, error) {
+	wt, err := git.FindWorktreeByBranchOrDir(ctx, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to find worktree: %w", err)
+	}
+	if wt != nil {
+		return wt.Path, nil
+	}
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target), nil
+	}
+	path, err := git.WorktreePathFor(ctx, cfg.BaseDir, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktree path: %w", err)
+	}
+	return path, nil
+}
+
+// printHookRun prints a header describing r, followed by its output.
+func printHookRun(w io.Writer, r git.HookRun) error {
+	status := fmt.Sprintf("exit code %d", r.ExitCode)
+	switch {
+	case r.Running():
+		status = "running"
+	case r.Error != "":
+		status = r.Error
+	}
+	if !r.EndedAt.IsZero() {
+		status += fmt.Sprintf(", %s", r.EndedAt.Sub(r.StartedAt).Round(time.Millisecond))
+	}
+	kind := r.Event
+	if r.Background {
+		kind += ", background"
+	}
+	if _, err := fmt.Fprintf(w, "==> %s [%s] %s (%s)\n", r.StartedAt.Local().Format(time.DateTime), kind, r.Hook, status); err != nil {
+		return err
+	}
+	b, err := os.ReadFile(r.Log)
+	if err != nil {
+		_, err = fmt.Fprintf(w, "(log unavailable: %v)\n", err)
+		return err
+	}
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// rerunHooks runs the hooks (wt.hook) again on the existing worktree of
+// target, e.g., after one failed when the worktree was created. They run as
+// they would for a new worktree, including in the background.
+func rerunHooks(ctx context.Context, cmd *cobra.Command, target string) error {
+	cfg, err := worktreeConfig(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	wt, err := git.FindWorktreeByBranchOrDir(ctx, target)
+	if err != nil {
+		return fmt.Errorf("failed to find worktree: %w", err)
+	}
+	if wt == nil {
+		return fmt.Errorf("worktree %q not found", target)
+	}
+	if len(cfg.Hooks) == 0 {
+		return fmt.Errorf("no hooks configured (wt.hook)")
+	}
+	branch := wt.Branch
+	if branch == git.DetachedMarker {
+		branch = ""
+	}
+	return runCreateHooks(ctx, cfg, wt.Path, createHookEnv(ctx, cfg, wt.Path, branch, "", false), true)
+}
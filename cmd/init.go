@@ -14,35 +14,32 @@ git() {
         shift
         local nocd_mode=""
         local nocd_flag=false
-        local rename_flag=false
         # Check wt.nocd config (supports: true, all, create, false)
         nocd_mode="$(command git config --get wt.nocd 2>/dev/null || true)"
-        local existing_worktrees=""
-        if [[ "$nocd_mode" == "create" ]]; then
-            # Get existing worktree paths before running git wt
-            existing_worktrees=$(command git worktree list --porcelain 2>/dev/null | grep '^worktree ' | cut -d' ' -f2- || true)
-        fi
-        local args=()
+        local arg
         for arg in "$@"; do
             if [[ "$arg" == "--nocd" || "$arg" == "--no-switch-directory" ]]; then
                 nocd_flag=true
             fi
-            if [[ "$arg" == "-m" || "$arg" == "-M" || "$arg" == "--move" || "$arg" == "--force-move" ]]; then
-                rename_flag=true
-            fi
-            args+=("$arg")
         done
-        local result
-        result=$(GIT_WT_SHELL_INTEGRATION=1 command git wt "${args[@]}")
-        local exit_code=$?
-        # Get the last line for cd target
-        local last_line
-        last_line=$(echo "$result" | tail -n 1)
-        if [[ $exit_code -eq 0 && -d "$last_line" ]]; then
-            # Print all lines except the last (intermediate paths)
-            echo "$result" | sed '$d' | while IFS= read -r line; do
-                [[ -n "$line" ]] && echo "$line"
-            done
+        # git wt writes directives (cd target, env to export) to this file
+        local directive_file
+        directive_file="$(mktemp "${TMPDIR:-/tmp}/git-wt.XXXXXX" 2>/dev/null)" || {
+            command git wt "$@"
+            return
+        }
+        local exit_code=0
+        GIT_WT_SHELL_INTEGRATION=1 GIT_WT_DIRECTIVE_FILE="$directive_file" command git wt "$@" || exit_code=$?
+        local kind key value cd_target="" created=false
+        while IFS=$'\t' read -r kind key value; do
+            case "$kind" in
+                cd) cd_target="$key" ;;
+                created) created=true ;;
+                env) [[ -n "$key" ]] && export "$key=$value" ;;
+            esac
+        done < "$directive_file"
+        rm -f "$directive_file"
+        if [[ $exit_code -eq 0 && -n "$cd_target" && -d "$cd_target" ]]; then
             # Determine whether to cd
             local should_cd=true
             if [[ "$nocd_flag" == "true" ]]; then
@@ -51,25 +48,17 @@ git() {
             elif [[ "$nocd_mode" == "true" || "$nocd_mode" == "all" ]]; then
                 # wt.nocd=true/all prevents cd for all operations
                 should_cd=false
-            elif [[ "$nocd_mode" == "create" ]]; then
+            elif [[ "$nocd_mode" == "create" && "$created" == "true" ]]; then
                 # wt.nocd=create only prevents cd for new worktrees
-                if [[ "$rename_flag" == "true" ]]; then
-                    should_cd=true  # rename targets existing worktree at new path
-                elif echo "$existing_worktrees" | grep -qxF "$last_line"; then
-                    should_cd=true  # existing worktree, allow cd
-                else
-                    should_cd=false  # new worktree, prevent cd
-                fi
+                should_cd=false
             fi
             if [[ "$should_cd" == "true" ]]; then
-                cd "$last_line"
+                cd "$cd_target"
             else
-                echo "$last_line"
+                echo "$cd_target"
             fi
-        else
-            echo "$result"
-            return $exit_code
         fi
+        return $exit_code
     else
         command git "$@"
     fi
@@ -100,35 +89,32 @@ git() {
         shift
         local nocd_mode=""
         local nocd_flag=false
-        local rename_flag=false
         # Check wt.nocd config (supports: true, all, create, false)
         nocd_mode="$(command git config --get wt.nocd 2>/dev/null || true)"
-        local existing_worktrees=""
-        if [[ "$nocd_mode" == "create" ]]; then
-            # Get existing worktree paths before running git wt
-            existing_worktrees=$(command git worktree list --porcelain 2>/dev/null | grep '^worktree ' | cut -d' ' -f2- || true)
-        fi
-        local args=()
+        local arg
         for arg in "$@"; do
             if [[ "$arg" == "--nocd" || "$arg" == "--no-switch-directory" ]]; then
                 nocd_flag=true
             fi
-            if [[ "$arg" == "-m" || "$arg" == "-M" || "$arg" == "--move" || "$arg" == "--force-move" ]]; then
-                rename_flag=true
-            fi
-            args+=("$arg")
         done
-        local result
-        result=$(GIT_WT_SHELL_INTEGRATION=1 command git wt "${args[@]}")
-        local exit_code=$?
-        # Get the last line for cd target
-        local last_line
-        last_line=$(echo "$result" | tail -n 1)
-        if [[ $exit_code -eq 0 && -d "$last_line" ]]; then
-            # Print all lines except the last (intermediate paths)
-            echo "$result" | sed '$d' | while IFS= read -r line; do
-                [[ -n "$line" ]] && echo "$line"
-            done
+        # git wt writes directives (cd target, env to export) to this file
+        local directive_file
+        directive_file="$(mktemp "${TMPDIR:-/tmp}/git-wt.XXXXXX" 2>/dev/null)" || {
+            command git wt "$@"
+            return
+        }
+        local exit_code=0
+        GIT_WT_SHELL_INTEGRATION=1 GIT_WT_DIRECTIVE_FILE="$directive_file" command git wt "$@" || exit_code=$?
+        local kind key value cd_target="" created=false
+        while IFS=$'\t' read -r kind key value; do
+            case "$kind" in
+                cd) cd_target="$key" ;;
+                created) created=true ;;
+                env) [[ -n "$key" ]] && export "$key=$value" ;;
+            esac
+        done < "$directive_file"
+        rm -f "$directive_file"
+        if [[ $exit_code -eq 0 && -n "$cd_target" && -d "$cd_target" ]]; then
             # Determine whether to cd
             local should_cd=true
             if [[ "$nocd_flag" == "true" ]]; then
@@ -137,25 +123,17 @@ git() {
             elif [[ "$nocd_mode" == "true" || "$nocd_mode" == "all" ]]; then
                 # wt.nocd=true/all prevents cd for all operations
                 should_cd=false
-            elif [[ "$nocd_mode" == "create" ]]; then
+            elif [[ "$nocd_mode" == "create" && "$created" == "true" ]]; then
                 # wt.nocd=create only prevents cd for new worktrees
-                if [[ "$rename_flag" == "true" ]]; then
-                    should_cd=true  # rename targets existing worktree at new path
-                elif echo "$existing_worktrees" | grep -qxF "$last_line"; then
-                    should_cd=true  # existing worktree, allow cd
-                else
-                    should_cd=false  # new worktree, prevent cd
-                fi
+                should_cd=false
             fi
             if [[ "$should_cd" == "true" ]]; then
-                cd "$last_line"
+                cd "$cd_target"
             else
-                echo "$last_line"
+                echo "$cd_target"
             fi
-        else
-            echo "$result"
-            return $exit_code
         fi
+        return $exit_code
     else
         command git "$@"
     fi
@@ -205,32 +183,38 @@ const fishGitWrapper = `
 function git --wraps git
     if test "$argv[1]" = "wt"
         set -l nocd_flag false
-        set -l rename_flag false
         # Check wt.nocd config (supports: true, all, create, false)
         set -l nocd_mode (command git config --get wt.nocd 2>/dev/null)
-        set -l existing_worktrees
-        if test "$nocd_mode" = "create"
-            # Get existing worktree paths before running git wt
-            set existing_worktrees (command git worktree list --porcelain 2>/dev/null | grep '^worktree ' | cut -d' ' -f2-)
-        end
         for arg in $argv[2..]
             if string match -q -- "--nocd" "$arg"; or string match -q -- "--no-switch-directory" "$arg"
                 set nocd_flag true
             end
-            if string match -q -- "-m" "$arg"; or string match -q -- "-M" "$arg"; or string match -q -- "--move" "$arg"; or string match -q -- "--force-move" "$arg"
-                set rename_flag true
-            end
         end
-        set -lx GIT_WT_SHELL_INTEGRATION 1
-        set -l result (command git wt $argv[2..])
+        # git wt writes directives (cd target, env to export) to this file
+        set -l tmpdir /tmp
+        set -q TMPDIR; and set tmpdir $TMPDIR
+        set -l directive_file (mktemp "$tmpdir/git-wt.XXXXXX" 2>/dev/null)
+        if test -z "$directive_file"
+            command git wt $argv[2..]
+            return $status
+        end
+        GIT_WT_SHELL_INTEGRATION=1 GIT_WT_DIRECTIVE_FILE=$directive_file command git wt $argv[2..]
         set -l exit_code $status
-        # Get the last line for cd target
-        set -l last_line $result[-1]
-        if test $exit_code -eq 0 -a -d "$last_line"
-            # Print all lines except the last (intermediate paths)
-            for line in $result[1..-2]
-                printf "%s\n" "$line"
+        set -l cd_target
+        set -l created false
+        while read -l line
+            set -l fields (string split -m 2 \t -- $line)
+            switch $fields[1]
+                case cd
+                    set cd_target $fields[2]
+                case created
+                    set created true
+                case env
+                    test -n "$fields[2]"; and set -gx $fields[2] $fields[3]
             end
+        end < $directive_file
+        rm -f $directive_file
+        if test $exit_code -eq 0 -a -n "$cd_target" -a -d "$cd_target"
             # Determine whether to cd
             set -l should_cd true
             if test "$nocd_flag" = "true"
@@ -239,27 +223,17 @@ function git --wraps git
             else if test "$nocd_mode" = "true" -o "$nocd_mode" = "all"
                 # wt.nocd=true/all prevents cd for all operations
                 set should_cd false
-            else if test "$nocd_mode" = "create"
+            else if test "$nocd_mode" = "create" -a "$created" = "true"
                 # wt.nocd=create only prevents cd for new worktrees
-                if test "$rename_flag" = "true"
-                    set should_cd true  # rename targets existing worktree at new path
-                else if contains -- "$last_line" $existing_worktrees
-                    set should_cd true  # existing worktree, allow cd
-                else
-                    set should_cd false  # new worktree, prevent cd
-                end
+                set should_cd false
             end
             if test "$should_cd" = "true"
-                cd "$last_line"
+                cd "$cd_target"
             else
-                printf "%s\n" "$last_line"
-            end
-        else
-            for line in $result
-                printf "%s\n" "$line"
+                printf "%s\n" "$cd_target"
             end
-            return $exit_code
         end
+        return $exit_code
     else
         command git $argv
     end
@@ -304,25 +278,28 @@ const powershellGitWrapper = "" +
 	"    if ($args[0] -eq \"wt\") {\n" +
 	"        $wtArgs = $args[1..($args.Length-1)]\n" +
 	"        $nocdFlag = ($wtArgs -contains \"--nocd\") -or ($wtArgs -contains \"--no-switch-directory\")\n" +
-	"        $renameFlag = ($wtArgs -contains \"-m\") -or ($wtArgs -contains \"-M\") -or ($wtArgs -contains \"--move\") -or ($wtArgs -contains \"--force-move\")\n" +
 	"        # Check wt.nocd config (supports: true, all, create, false)\n" +
 	"        $nocdMode = & git.exe config --get wt.nocd 2>$null\n" +
-	"        $existingWorktrees = @()\n" +
-	"        if ($nocdMode -eq \"create\") {\n" +
-	"            # Get existing worktree paths before running git wt\n" +
-	"            $existingWorktrees = @(& git.exe worktree list --porcelain 2>$null | Where-Object { $_ -match '^worktree ' } | ForEach-Object { $_ -replace '^worktree ', '' })\n" +
-	"        }\n" +
+	"        # git wt writes directives (cd target, env to export) to this file\n" +
+	"        $directiveFile = [System.IO.Path]::GetTempFileName()\n" +
 	"        $env:GIT_WT_SHELL_INTEGRATION = \"1\"\n" +
-	"        $result = & git.exe wt @wtArgs 2>&1\n" +
+	"        $env:GIT_WT_DIRECTIVE_FILE = $directiveFile\n" +
+	"        & git.exe wt @wtArgs\n" +
+	"        $exitCode = $LASTEXITCODE\n" +
 	"        $env:GIT_WT_SHELL_INTEGRATION = $null\n" +
-	"        # Get the last line for cd target\n" +
-	"        $lines = @($result -split \"`n\" | Where-Object { $_ -ne \"\" })\n" +
-	"        $lastLine = $lines[-1]\n" +
-	"        if ($LASTEXITCODE -eq 0 -and (Test-Path $lastLine -PathType Container)) {\n" +
-	"            # Print all lines except the last (intermediate paths)\n" +
-	"            if ($lines.Count -gt 1) {\n" +
-	"                $lines[0..($lines.Count-2)] | ForEach-Object { Write-Output $_ }\n" +
+	"        $env:GIT_WT_DIRECTIVE_FILE = $null\n" +
+	"        $cdTarget = $null\n" +
+	"        $created = $false\n" +
+	"        foreach ($line in @(Get-Content -LiteralPath $directiveFile -Encoding UTF8)) {\n" +
+	"            $fields = $line -split \"`t\", 3\n" +
+	"            switch ($fields[0]) {\n" +
+	"                \"cd\" { $cdTarget = $fields[1] }\n" +
+	"                \"created\" { $created = $true }\n" +
+	"                \"env\" { if ($fields[1]) { Set-Item -LiteralPath \"env:$($fields[1])\" -Value $fields[2] } }\n" +
 	"            }\n" +
+	"        }\n" +
+	"        Remove-Item -LiteralPath $directiveFile -ErrorAction SilentlyContinue\n" +
+	"        if ($exitCode -eq 0 -and $cdTarget -and (Test-Path -LiteralPath $cdTarget -PathType Container)) {\n" +
 	"            # Determine whether to cd\n" +
 	"            $shouldCd = $true\n" +
 	"            if ($nocdFlag) {\n" +
@@ -331,24 +308,18 @@ const powershellGitWrapper = "" +
 	"            } elseif ($nocdMode -eq \"true\" -or $nocdMode -eq \"all\") {\n" +
 	"                # wt.nocd=true/all prevents cd for all operations\n" +
 	"                $shouldCd = $false\n" +
-	"            } elseif ($nocdMode -eq \"create\") {\n" +
+	"            } elseif ($nocdMode -eq \"create\" -and $created) {\n" +
 	"                # wt.nocd=create only prevents cd for new worktrees\n" +
-	"                if ($renameFlag) {\n" +
-	"                    $shouldCd = $true  # rename targets existing worktree at new path\n" +
-	"                } elseif ($existingWorktrees -contains $lastLine) {\n" +
-	"                    $shouldCd = $true  # existing worktree, allow cd\n" +
-	"                } else {\n" +
-	"                    $shouldCd = $false  # new worktree, prevent cd\n" +
-	"                }\n" +
+	"                $shouldCd = $false\n" +
 	"            }\n" +
 	"            if ($shouldCd) {\n" +
-	"                Set-Location $lastLine\n" +
+	"                Set-Location -LiteralPath $cdTarget\n" +
 	"            } else {\n" +
-	"                Write-Output $lastLine\n" +
+	"                Write-Output $cdTarget\n" +
 	"            }\n" +
-	"        } else {\n" +
-	"            Write-Output $result\n" +
-	"            return $LASTEXITCODE\n" +
+	"        }\n" +
+	"        if ($exitCode -ne 0) {\n" +
+	"            return $exitCode\n" +
 	"        }\n" +
 	"    } else {\n" +
 	"        & git.exe @args\n" +
@@ -376,7 +347,208 @@ $scriptBlock = {
 Register-ArgumentCompleter -Native -CommandName git -ScriptBlock $scriptBlock
 `
 
-func runInit(shell string, ignoreSwitchDirectory bool) error {
+// Nushell hooks.
+const nuGitWrapper = `
+# Override git command to cd after 'git wt <branch>'
+def --env --wrapped git [...args: string@"nu-complete git"] {
+    if ($args | get 0? | default "") != "wt" {
+        ^git ...$args
+        return
+    }
+    let wt_args = ($args | skip 1)
+    let nocd_flag = ("--nocd" in $wt_args) or ("--no-switch-directory" in $wt_args)
+    # Check wt.nocd config (supports: true, all, create, false)
+    let nocd_mode = (^git config --get wt.nocd | complete | get stdout | str trim)
+    # git wt writes directives (cd target, env to export) to this file
+    let directive_file = (mktemp -t git-wt.XXXXXX)
+    $env.GIT_WT_SHELL_INTEGRATION = "1"
+    $env.GIT_WT_DIRECTIVE_FILE = $directive_file
+    try { ^git wt ...$wt_args }
+    let exit_code = $env.LAST_EXIT_CODE
+    hide-env GIT_WT_SHELL_INTEGRATION GIT_WT_DIRECTIVE_FILE
+    let directives = (open --raw $directive_file | lines | each {|line| $line | split row --number 3 "\t" })
+    rm -f $directive_file
+    mut cd_target = ""
+    mut created = false
+    mut env_vars = {}
+    for d in $directives {
+        match ($d | first) {
+            "cd" => { $cd_target = ($d | get 1? | default "") }
+            "created" => { $created = true }
+            "env" => {
+                if ($d | length) > 2 {
+                    $env_vars = ($env_vars | upsert ($d | get 1) ($d | get 2))
+                }
+            }
+        }
+    }
+    load-env $env_vars
+    if $exit_code != 0 {
+        # Fail as git wt did, so that scripts and pipelines see the error
+        error make --unspanned {msg: $"git wt exited with code ($exit_code)"}
+    }
+    if $cd_target == "" or ($cd_target | path type) != "dir" {
+        return
+    }
+    if $nocd_flag or $nocd_mode in ["true" "all"] or ($nocd_mode == "create" and $created) {
+        # --nocd, wt.nocd=true/all and (for new worktrees) wt.nocd=create prevent cd
+        print $cd_target
+    } else {
+        cd $cd_target
+    }
+}
+
+# The wrapper completes through the external completer below
+def "nu-complete git" [context: string] {
+    do $env.config.completions.external.completer ($context | split row --regex '\s+')
+}
+`
+
+const nuCompletion = `
+# git wt <branch> completion for nushell
+def __git_wt_complete [args: list<string>] {
+    ^git-wt __complete ...$args
+    | complete
+    | get stdout
+    | lines
+    | where {|line| $line != "" and not ($line | str starts-with ":") }
+    | each {|line|
+        let parts = ($line | split row --number 2 "\t")
+        {value: ($parts | first), description: ($parts | get 1? | default "")}
+    }
+}
+
+# Chain to the external completer configured so far for everything else
+let __git_wt_previous_completer = $env.config?.completions?.external?.completer?
+$env.config.completions.external.completer = {|spans|
+    if ($spans | length) > 2 and $spans.0 == "git" and $spans.1 == "wt" {
+        __git_wt_complete ($spans | skip 2)
+    } else if ($spans | length) > 1 and $spans.0 == "git-wt" {
+        __git_wt_complete ($spans | skip 1)
+    } else if $__git_wt_previous_completer != null {
+        do $__git_wt_previous_completer $spans
+    }
+}
+`
+
+// Elvish hooks.
+const elvishGitWrapper = `
+# Override git command to cd after 'git wt <branch>'
+fn git {|@args|
+  if (or (== (count $args) 0) (not-eq $args[0] wt)) {
+    e:git $@args
+    return
+  }
+  var wt-args = $args[1..]
+  var nocd-flag = (or (has-value $wt-args --nocd) (has-value $wt-args --no-switch-directory))
+  # Check wt.nocd config (supports: true, all, create, false)
+  var nocd-mode = ''
+  try {
+    set nocd-mode = (str:trim-space (e:git config --get wt.nocd | slurp))
+  } catch {
+    # wt.nocd is not set
+  }
+  # git wt writes directives (cd target, env to export) to this file
+  var f = (os:temp-file git-wt.*)
+  var directive-file = $f[name]
+  file:close $f
+  var err = $nil
+  try {
+    tmp E:GIT_WT_SHELL_INTEGRATION = 1
+    tmp E:GIT_WT_DIRECTIVE_FILE = $directive-file
+    e:git wt $@wt-args
+  } catch e {
+    set err = $e
+  }
+  var cd-target = ''
+  var created = $false
+  from-lines < $directive-file | each {|line|
+    var fields = [(str:split &max=3 "\t" $line)]
+    if (and (eq $fields[0] cd) (> (count $fields) 1)) {
+      set cd-target = $fields[1]
+    } elif (eq $fields[0] created) {
+      set created = $true
+    } elif (and (eq $fields[0] env) (> (count $fields) 2)) {
+      set-env $fields[1] $fields[2]
+    }
+  }
+  os:remove $directive-file
+  if (not-eq $err $nil) {
+    fail $err
+  }
+  if (or (eq $cd-target '') (not (os:is-dir $cd-target))) {
+    return
+  }
+  if (or $nocd-flag (eq $nocd-mode true) (eq $nocd-mode all) (and (eq $nocd-mode create) $created)) {
+    # --nocd, wt.nocd=true/all and (for new worktrees) wt.nocd=create prevent cd
+    echo $cd-target
+  } else {
+    cd $cd-target
+  }
+}
+edit:add-var git~ $git~
+`
+
+const elvishCompletion = `
+# git wt <branch> completion for elvish
+fn git-wt-complete {|@args|
+  e:git-wt __complete $@args 2>$os:dev-null | from-lines | each {|line|
+    if (not (str:has-prefix $line :)) {
+      put [(str:split &max=2 "\t" $line)][0]
+    }
+  }
+}
+var git-wt-previous-completer = $nil
+if (has-key $edit:completion:arg-completer git) {
+  set git-wt-previous-completer = $edit:completion:arg-completer[git]
+}
+set edit:completion:arg-completer[git-wt] = {|@words| git-wt-complete (all $words[1..]) }
+set edit:completion:arg-completer[git] = {|@words|
+  if (and (> (count $words) 2) (eq $words[1] wt)) {
+    git-wt-complete (all $words[2..])
+  } elif (not-eq $git-wt-previous-completer $nil) {
+    $git-wt-previous-completer $@words
+  } else {
+    edit:complete-filename $words[-1]
+  }
+}
+`
+
+// Prompt snippets (--init <shell> --prompt).
+const zshPrompt = `
+# git wt prompt segment at the start of PROMPT (see 'git wt --prompt')
+_git_wt_prompt_precmd() {
+    _git_wt_prompt="$(command git-wt --prompt 2>/dev/null)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _git_wt_prompt_precmd
+setopt prompt_subst
+[[ $PROMPT == *_git_wt_prompt* ]] || PROMPT='${_git_wt_prompt:+[${_git_wt_prompt//\%/%%}] }'"$PROMPT"
+`
+
+const starshipPrompt = `# git wt prompt segment for starship
+# Add to ~/.config/starship.toml (see 'git wt --prompt')
+[custom.git_wt]
+description = "git wt worktree status"
+command = "git-wt --prompt"
+when = true
+require_repo = true
+format = "[wt:$output]($style) "
+style = "bold purple"
+`
+
+func runInit(shell string, ignoreSwitchDirectory, prompt bool) error {
+	if prompt {
+		switch shell {
+		case "starship":
+			// A starship.toml snippet, not a shell script.
+			_, err := io.WriteString(os.Stdout, starshipPrompt)
+			return err
+		case "zsh":
+		default:
+			return fmt.Errorf("unsupported shell for --prompt: %s (supported: zsh, starship)", shell)
+		}
+	}
 	var header, wrapper, completion string
 	switch shell {
 	case "bash":
@@ -395,8 +567,19 @@ func runInit(shell string, ignoreSwitchDirectory bool) error {
 		header = "# git-wt shell hook for PowerShell\n"
 		wrapper = powershellGitWrapper
 		completion = powershellCompletion
+	case "nu":
+		header = "# git-wt shell hook for nushell\n"
+		wrapper = nuGitWrapper
+		completion = nuCompletion
+	case "elvish":
+		header = "# git-wt shell hook for elvish\nuse file\nuse os\nuse str\n"
+		wrapper = elvishGitWrapper
+		completion = elvishCompletion
 	default:
-		return fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish, powershell)", shell)
+		if shell == "starship" {
+			return fmt.Errorf("starship is supported with --prompt only (git wt --init starship --prompt)")
+		}
+		return fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish, powershell, nu, elvish)", shell)
 	}
 	if _, err := io.WriteString(os.Stdout, header); err != nil {
 		return err
@@ -406,6 +589,12 @@ func runInit(shell string, ignoreSwitchDirectory bool) error {
 			return err
 		}
 	}
-	_, err := io.WriteString(os.Stdout, completion)
-	return err
+	if _, err := io.WriteString(os.Stdout, completion); err != nil {
+		return err
+	}
+	if prompt {
+		_, err := io.WriteString(os.Stdout, zshPrompt)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/k1LoW/git-wt/internal/picker"
+	"github.com/spf13/cobra"
+)
+
+// pickerCandidate is an entry of the interactive picker. For remote branches
+// without a local branch, startPoint is the remote branch.
+type pickerCandidate struct {
+	candidate
+	startPoint string
+}
+
+// autoInteractive reports whether 'git wt' without arguments should open the
+// picker instead of listing worktrees: wt.interactive is true, no list output
+// option is given, and the user is at a terminal. With the shell integration,
+// stdout may be captured (by wrappers of older versions), so only stdin and
+// stderr are checked.
+func autoInteractive(ctx context.Context, cmd *cobra.Command) (bool, error) {
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.Interactive || jsonFlag || cmd.Flags().Changed("format") {
+		return false, nil
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		return false, nil
+	}
+	return isTerminal(os.Stdout) || os.Getenv("GIT_WT_SHELL_INTEGRATION") == "1", nil
+}
+
+// pickWorktree lets the user choose a worktree or branch with the built-in
+// fuzzy finder and then switches to it (creating the worktree if needed)
+// exactly like 'git wt <branch>', so the shell integration still cds.
+func pickWorktree(ctx context.Context, cmd *cobra.Command) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		return fmt.Errorf("interactive mode requires a terminal")
+	}
+	candidates, err := pickerCandidates(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no worktrees or branches to choose from")
+	}
+
+	items := make([]picker.Item, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, picker.Item{Text: c.name, Desc: c.desc})
+	}
+	i, err := picker.Pick(os.Stdin, os.Stderr, "git wt> ", items)
+	if err != nil {
+		if errors.Is(err, picker.ErrCanceled) {
+			return nil
+		}
+		return err
+	}
+	c := candidates[i]
+	startPoint := c.startPoint
+	if startPoint != "" {
+		// Branches on origin are checked out (and tracked) by name, as with
+		// 'git wt <branch>'; other remotes need an explicit start-point.
+		exists, err := git.BranchExists(ctx, c.name)
+		if err != nil {
+			return fmt.Errorf("failed to check branch: %w", err)
+		}
+		if exists {
+			startPoint = ""
+		}
+	}
+	return handleWorktree(ctx, cmd, c.name, c.name, startPoint)
+}
+
+// pickerCandidates returns the completion targets (worktrees and local
+// branches) followed by remote branches that have no local branch yet.
+func pickerCandidates(ctx context.Context, cmd *cobra.Command) ([]pickerCandidate, error) {
+	targets, err := targetCandidates(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees and branches: %w", err)
+	}
+	candidates := make([]pickerCandidate, 0, len(targets))
+	seen := make(map[string]struct{})
+	for _, t := range targets {
+		seen[t.name] = struct{}{}
+		candidates = append(candidates, pickerCandidate{candidate: t})
+	}
+
+	// Remote branches are offered by their local name, e.g. "feature" for
+	// "origin/feature", and created from the remote branch when chosen.
+	remotes, err := git.ListRemoteBranches(ctx)
+	if err != nil {
+		// Remote branches are optional: offer the local targets only.
+		return candidates, nil
+	}
+	var commitMessages map[string]string
+	if msgs, err := git.BranchCommitMessages(ctx, "refs/remotes"); err == nil {
+		commitMessages = msgs
+	}
+	for _, remote := range remotes {
+		_, name, ok := strings.Cut(remote, "/")
+		if !ok {
+			continue
+		}
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+		desc := fmt.Sprintf("[remote: %s]", remote)
+		if msg := commitMessages[remote]; msg != "" {
+			desc += " " + truncateString(msg, 40)
+		}
+		candidates = append(candidates, pickerCandidate{candidate: candidate{name: name, desc: desc}, startPoint: remote})
+	}
+	return candidates, nil
+}
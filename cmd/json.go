@@ -3,30 +3,68 @@ package cmd
 import (
 	"encoding/json"
 	"io"
+	"path/filepath"
+	"strings"
 
 	"github.com/k1LoW/git-wt/internal/git"
 )
 
+// worktreeJSON is a worktree entry of the list output. It is used both for
+// --json output and as the data passed to --format templates.
 type worktreeJSON struct {
-	Path    string `json:"path"`
-	Branch  string `json:"branch"`
-	Head    string `json:"head"`
-	Bare    bool   `json:"bare"`
-	Current bool   `json:"current"`
+	Path           string `json:"path"`
+	Name           string `json:"name"`
+	Branch         string `json:"branch"`
+	Head           string `json:"head"`
+	Bare           bool   `json:"bare"`
+	Current        bool   `json:"current"`
+	Modified       int    `json:"modified"`
+	Untracked      int    `json:"untracked"`
+	Ahead          int    `json:"ahead"`
+	Behind         int    `json:"behind"`
+	Upstream       string `json:"upstream"`
+	Locked         bool   `json:"locked"`
+	LockReason     string `json:"lock_reason"`
+	Prunable       bool   `json:"prunable"`
+	PrunableReason string `json:"prunable_reason"`
 }
 
-func printJSON(w io.Writer, worktrees []git.Worktree, currentPath string) error {
+// newWorktreeItems builds the list entries for worktrees. statuses must be
+// in the same order as worktrees. Name is the worktree directory relative to
+// baseDir, or empty for worktrees outside baseDir (e.g., the main working tree).
+func newWorktreeItems(worktrees []git.Worktree, statuses []*git.WorktreeStatus, currentPath, baseDir string) []worktreeJSON {
 	items := make([]worktreeJSON, len(worktrees))
 	for i, wt := range worktrees {
 		items[i] = worktreeJSON{
-			Path:    wt.Path,
-			Branch:  wt.Branch,
-			Head:    wt.Head,
-			Bare:    wt.Bare,
-			Current: wt.Path == currentPath,
+			Path:           wt.Path,
+			Branch:         wt.Branch,
+			Head:           wt.Head,
+			Bare:           wt.Bare,
+			Current:        wt.Path == currentPath,
+			Locked:         wt.Locked,
+			LockReason:     wt.LockReason,
+			Prunable:       wt.Prunable,
+			PrunableReason: wt.PrunableReason,
+		}
+		if baseDir != "" {
+			if rel, err := filepath.Rel(baseDir, wt.Path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				items[i].Name = rel
+			}
+		}
+		if st := statuses[i]; st != nil {
+			items[i].Modified = st.Modified
+			items[i].Untracked = st.Untracked
+			items[i].Ahead = st.Ahead
+			items[i].Behind = st.Behind
+			items[i].Upstream = st.Upstream
 		}
 	}
+	return items
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v any) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	return enc.Encode(items)
+	return enc.Encode(v)
 }
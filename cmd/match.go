@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/k1LoW/git-wt/internal/git"
+)
+
+// worktreeMatch is a worktree whose branch or directory name matches a target
+// that is not an exact branch, directory name or path.
+type worktreeMatch struct {
+	wt       git.Worktree
+	name     string // branch, or directory name for detached worktrees
+	frecency float64
+}
+
+// matchWorktrees returns the existing worktrees that query may abbreviate,
+// most frecent first (see git.HistoryEntry.Frecency). Worktrees whose branch
+// or directory name contains query at the start of a word (e.g., "auth" or
+// "123" for "feature/JIRA-123-auth-refresh") match; the main working tree
+// never does. Matching ignores case unless query has an upper-case letter.
+func matchWorktrees(ctx context.Context, cfg git.Config, query string) ([]worktreeMatch, error) {
+	worktrees, err := git.ListWorktrees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand basedir: %w", err)
+	}
+	frecency := make(map[string]float64)
+	if history, err := git.SwitchHistory(ctx); err == nil {
+		now := time.Now()
+		for _, e := range history {
+			frecency[e.Path] = e.Frecency(now)
+		}
+	}
+
+	foldCase := !strings.ContainsFunc(query, unicode.IsUpper)
+	matchesWord := func(name string) bool {
+		if foldCase {
+			name = strings.ToLower(name)
+		}
+		return containsWord(name, query)
+	}
+
+	var matches []worktreeMatch
+	for i, wt := range worktrees {
+		// git lists the main working tree first.
+		if i == 0 || wt.Bare || wt.Prunable {
+			continue
+		}
+		var names []string
+		if wt.Branch != git.DetachedMarker {
+			names = append(names, wt.Branch)
+		}
+		if rel, err := filepath.Rel(baseDir, wt.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			names = append(names, filepath.ToSlash(rel))
+		}
+		if slices.ContainsFunc(names, matchesWord) {
+			matches = append(matches, worktreeMatch{wt: wt, name: names[0], frecency: frecency[filepath.Clean(wt.Path)]})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b worktreeMatch) int {
+		return cmp.Compare(b.frecency, a.frecency)
+	})
+	return matches, nil
+}
+
+// formatMatches lists matches, one per line with its path, each line
+// starting with indent.
+func formatMatches(matches []worktreeMatch, indent string) string {
+	width := 0
+	for _, m := range matches {
+		width = max(width, len(m.name))
+	}
+	var b strings.Builder
+	for _, m := range matches {
+		fmt.Fprintf(&b, "%s%-*s  %s\n", indent, width, m.name, m.wt.Path)
+	}
+	return b.String()
+}
+
+// containsWord reports whether query occurs in name at the start of a word:
+// at the beginning of name or after a character that is neither a letter nor
+// a digit (e.g., "/", "-" or "_").
+func containsWord(name, query string) bool {
+	for i := 0; i <= len(name)-len(query); i++ {
+		if !strings.HasPrefix(name[i:], query) {
+			continue
+		}
+		if i == 0 {
+			return true
+		}
+		if r, _ := utf8.DecodeLastRuneInString(name[:i]); !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/k1LoW/git-wt/internal/multiplexer"
+)
+
+// worktreeWindow is a deleted worktree whose multiplexer window is closed
+// once deletion is done.
+type worktreeWindow struct {
+	name    string
+	path    string
+	current bool // the worktree git wt ran in
+}
+
+// showWorktree shows the worktree at path, which git wt switched to or
+// created, to the user. With wt.multiplexer, inside a session of the
+// multiplexer, the worktree's window is focused (or created) instead of
+// changing the directory of the current shell, and the path is printed only
+// without the shell integration. Otherwise, the path is handed to the shell
+// integration as usual.
+func showWorktree(ctx context.Context, cfg git.Config, path, name string, created bool) {
+	dir := resolveRelative(ctx, path, cfg.Relative)
+	m, ok := activeMultiplexer(cfg)
+	if !ok {
+		printWorktreePath(dir, created)
+		return
+	}
+	opened, err := m.Open(ctx, name, path, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to open %s window: %v\n", m.Name(), err)
+		printWorktreePath(dir, created)
+		return
+	}
+	if opened {
+		fmt.Fprintf(os.Stderr, "Opened %s window %q\n", m.Name(), name)
+	} else {
+		fmt.Fprintf(os.Stderr, "Switched to %s window %q\n", m.Name(), name)
+	}
+	if os.Getenv("GIT_WT_SHELL_INTEGRATION") != "1" {
+		fmt.Println(dir)
+	}
+}
+
+// closeWindows closes the multiplexer windows of deleted worktrees. The
+// window git wt runs in, if any, is closed last, as closing it may end git wt.
+func closeWindows(ctx context.Context, cfg git.Config, windows []worktreeWindow) {
+	if len(windows) == 0 {
+		return
+	}
+	m, ok := activeMultiplexer(cfg)
+	if !ok {
+		return
+	}
+	var current *worktreeWindow
+	for i, w := range windows {
+		if w.current {
+			current = &windows[i]
+			continue
+		}
+		closeWindow(ctx, m, w)
+	}
+	if current != nil {
+		closeWindow(ctx, m, *current)
+	}
+}
+
+func closeWindow(ctx context.Context, m multiplexer.Multiplexer, w worktreeWindow) {
+	closed, err := m.Close(ctx, w.name, w.path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to close %s window %q: %v\n", m.Name(), w.name, err)
+		return
+	}
+	if closed && !w.current {
+		fmt.Fprintf(os.Stderr, "Closed %s window %q\n", m.Name(), w.name)
+	}
+}
+
+// activeMultiplexer returns the multiplexer of wt.multiplexer if git wt runs
+// inside a session of it. A multiplexer requested with --tmux or
+// --multiplexer outside its session is reported, as it was asked for
+// explicitly; the config is silently ignored there, so that it can be set
+// globally.
+func activeMultiplexer(cfg git.Config) (multiplexer.Multiplexer, bool) {
+	m, ok, err := multiplexer.New(cfg.Multiplexer, git.TabJournal{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil, false
+	}
+	if !ok && m != nil && (tmuxFlag || multiplexerFlag != "") {
+		fmt.Fprintf(os.Stderr, "warning: not running inside %s, ignoring --%s\n", m.Name(), multiplexerFlagName())
+	}
+	return m, ok
+}
+
+func multiplexerFlagName() string {
+	if tmuxFlag {
+		return "tmux"
+	}
+	return "multiplexer"
+}
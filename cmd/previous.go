@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// parsePreviousRef parses "-" and "@{-N}" (N >= 1), which refer to the
+// worktree of the N-th previous switch like in 'git switch'. "-" is "@{-1}".
+func parsePreviousRef(arg string) (int, bool) {
+	if arg == "-" {
+		return 1, true
+	}
+	s, ok := strings.CutPrefix(arg, "@{-")
+	if !ok {
+		return 0, false
+	}
+	s, ok = strings.CutSuffix(s, "}")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// switchToPrevious switches to the worktree of the n-th previous switch, as
+// recorded in the switch history. ref is the argument as given by the user.
+func switchToPrevious(ctx context.Context, cmd *cobra.Command, ref string, n int) error {
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	current, err := git.CurrentLocation(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current location: %w", err)
+	}
+	wt, err := git.PreviousWorktree(ctx, current, n)
+	if err != nil {
+		return fmt.Errorf("failed to read switch history: %w", err)
+	}
+	if wt == nil {
+		if n == 1 {
+			return fmt.Errorf("no previous worktree to switch to")
+		}
+		return fmt.Errorf("%s: fewer than %d previous worktrees in the switch history", ref, n)
+	}
+	return switchToWorktree(ctx, cfg, *wt)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat shows the worktree name (the branch in the main working
+// tree), "*" if it has uncommitted work, and the number of other worktrees
+// that have, e.g., "feature-x* +2".
+const defaultPromptFormat = `{{if .Main}}{{.Branch}}{{else}}{{.Name}}{{end}}{{if .Dirty}}*{{end}}{{if .OtherDirty}} +{{.OtherDirty}}{{end}}`
+
+// printPrompt prints the prompt segment of the current worktree with
+// wt.promptformat (or --format). As prompts run before every command line,
+// it spawns only the git processes of git.LoadConfig and git.LoadPrompt (plus
+// 'git status' for the dirty markers the format uses), and prints nothing
+// where there is nothing to tell, e.g., outside a repository.
+func printPrompt(ctx context.Context, cmd *cobra.Command) error {
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	format := cfg.PromptFormat
+	if format == "" {
+		format = defaultPromptFormat
+	}
+	tmpl, err := template.New("prompt").Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return fmt.Errorf("invalid prompt format %q: %w", format, err)
+	}
+	p, err := git.LoadPrompt(ctx, cfg.BaseDir)
+	if err != nil || p == nil {
+		return err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, p); err != nil {
+		return fmt.Errorf("failed to execute prompt format %q: %w", format, err)
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	_, err = fmt.Fprintln(os.Stdout, b.String())
+	return err
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// cleanupCandidate is a worktree or branch considered by a bulk cleanup
+// (--prune-merged, --older-than).
+type cleanupCandidate struct {
+	target string // argument passed to deleteWorktrees
+	branch string
+	path   string // empty for branches without a worktree
+	note   string // extra information shown in the plan
+	skip   string // reason the candidate is kept, empty if it will be deleted
+}
+
+// pruneMerged deletes every worktree (and, with --include-branches, every
+// local branch without a worktree) whose branch is merged into the default
+// branch. Deletion goes through deleteWorktrees so that delete hooks,
+// wt.remover and default-branch protection apply as with -d/-D.
+func pruneMerged(ctx context.Context, cmd *cobra.Command, force bool) error {
+	candidates, err := findPruneCandidates(ctx, includeBranchesFlag, force)
+	if err != nil {
+		return err
+	}
+	return runCleanup(ctx, cmd, "Merged into the default branch", "merged worktree(s)/branch(es)", candidates, force)
+}
+
+// runCleanup prints the plan of a bulk cleanup, asks for confirmation (unless
+// --yes or --dry-run), and deletes the candidates that are not skipped. As
+// the confirmation needs a terminal, it refuses to delete without one unless
+// --yes is given. what describes the candidates in messages.
+func runCleanup(ctx context.Context, cmd *cobra.Command, title, what string, candidates []cleanupCandidate, force bool) error {
+	var targets []string
+	for _, c := range candidates {
+		if c.skip == "" {
+			targets = append(targets, c.target)
+		}
+	}
+
+	// The plan goes to stderr so that it is visible (and the prompt makes
+	// sense) even when stdout is captured by the shell integration.
+	printCleanupPlan(os.Stderr, title, candidates)
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to prune.")
+		return nil
+	}
+
+	if !yesFlag && !dryRunFlag {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("not deleting %d %s without confirmation: stdin is not a terminal, use --yes to skip the prompt", len(targets), what)
+		}
+		ok, err := confirm(os.Stdin, os.Stderr, fmt.Sprintf("Delete %d %s?", len(targets), what))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+	}
+
+	return deleteWorktrees(ctx, cmd, targets, force, deleteOptions{})
+}
+
+// findPruneCandidates lists the worktrees (and optionally branches without a
+// worktree) whose branch is merged into the default branch. Candidates that
+// cannot be deleted safely are returned with a skip reason.
+func findPruneCandidates(ctx context.Context, includeBranches, force bool) ([]cleanupCandidate, error) {
+	merged, err := git.MergedBranches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merged branches: %w", err)
+	}
+	defaultBranch, err := git.DefaultBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	mainRoot, err := git.MainRepoRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get main repository root: %w", err)
+	}
+	worktrees, err := git.ListWorktrees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var candidates []cleanupCandidate
+	checkedOut := make(map[string]struct{})
+	for _, wt := range worktrees {
+		if wt.Bare || wt.Branch == "" || wt.Branch == git.DetachedMarker {
+			continue
+		}
+		checkedOut[wt.Branch] = struct{}{}
+		if wt.Branch == defaultBranch || !isMerged(ctx, merged, wt.Branch) {
+			continue
+		}
+		skip, err := skipReason(ctx, wt, mainRoot, force)
+		if err != nil {
+			return nil, err
+		}
+		if skip == "" {
+			if skip, err = unstartedReason(ctx, wt.Branch); err != nil {
+				return nil, err
+			}
+		}
+		candidates = append(candidates, cleanupCandidate{target: wt.Branch, branch: wt.Branch, path: wt.Path, skip: skip})
+	}
+
+	if !includeBranches {
+		return candidates, nil
+	}
+
+	// In bare repositories, HEAD of the bare root is "checked out" without
+	// appearing as a branch in the worktree list.
+	if isBare, err := git.IsBareRepository(ctx); err == nil && isBare {
+		if head, err := git.HeadBranch(ctx); err == nil {
+			checkedOut[head] = struct{}{}
+		}
+	}
+	branches, err := git.ListBranches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	for _, branch := range branches {
+		if _, ok := checkedOut[branch]; ok || branch == defaultBranch {
+			continue
+		}
+		if !isMerged(ctx, merged, branch) {
+			continue
+		}
+		skip, err := unstartedReason(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, cleanupCandidate{target: branch, branch: branch, skip: skip})
+	}
+	return candidates, nil
+}
+
+// isMerged reports whether branch is in merged (merged by ancestry) or its
+// changes were squash- or rebase-merged into the default branch.
+func isMerged(ctx context.Context, merged map[string]struct{}, branch string) bool {
+	if _, ok := merged[branch]; ok {
+		return true
+	}
+	ok, err := git.IsBranchIntegrated(ctx, branch)
+	return err == nil && ok
+}
+
+// skipReason returns why a bulk cleanup must keep the worktree wt, or an
+// empty string if it can be deleted: the main working tree and locked
+// worktrees are always kept, dirty worktrees unless force is set.
+func skipReason(ctx context.Context, wt git.Worktree, mainRoot string, force bool) (string, error) {
+	switch {
+	case git.SamePath(wt.Path, mainRoot):
+		return "main working tree", nil
+	case wt.Locked:
+		if wt.LockReason != "" {
+			return fmt.Sprintf("locked: %s", wt.LockReason), nil
+		}
+		return "locked", nil
+	case !force && !wt.Prunable:
+		st, err := git.GetWorktreeStatus(ctx, wt.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to get status of worktree %q: %w", wt.Path, err)
+		}
+		if st.Dirty() {
+			return "has modified or untracked files, use -D to force", nil
+		}
+	}
+	return "", nil
+}
+
+// unstartedReason returns why --prune-merged must keep branch even though it
+// counts as merged: a branch without commits of its own (e.g., one just
+// created with git wt) is reachable from the default branch because work on
+// it has not started yet, not because it was merged.
+func unstartedReason(ctx context.Context, branch string) (string, error) {
+	ok, err := git.HasOwnCommits(ctx, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to check commits of branch %q: %w", branch, err)
+	}
+	if !ok {
+		return "no commits of its own yet", nil
+	}
+	return "", nil
+}
+
+func printCleanupPlan(w io.Writer, title string, candidates []cleanupCandidate) {
+	if len(candidates) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, c := range candidates {
+		target := fmt.Sprintf("branch %q (no worktree)", c.branch)
+		switch {
+		case c.path != "" && c.branch == "":
+			target = fmt.Sprintf("worktree %q (detached HEAD)", c.path)
+		case c.path != "":
+			target = fmt.Sprintf("worktree %q (branch %q)", c.path, c.branch)
+		}
+		if c.note != "" {
+			target += ", " + c.note
+		}
+		if c.skip != "" {
+			fmt.Fprintf(w, "  skip    %s: %s\n", target, c.skip)
+			continue
+		}
+		fmt.Fprintf(w, "  delete  %s\n", target)
+	}
+}
+
+// isTerminal reports whether f is connected to a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// confirm asks a yes/no question on w and reads the answer from r.
+// Only "y" and "yes" (case-insensitive) are treated as consent.
+func confirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -23,9 +23,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/k1LoW/git-wt/internal/git"
@@ -44,19 +47,51 @@ var (
 	nocd            bool
 	branchFlag      string
 	// Config override flags.
-	basedirFlag       string
-	copyignoredFlag   bool
-	copyuntrackedFlag bool
-	copymodifiedFlag  bool
-	nocopyFlag        []string
-	copyFlag           []string
-	symlinkFlag        []string
-	hookFlag           []string
-	deleteHookFlag     []string
-	removerFlag        string
-	allowDeleteDefault bool
-	relativeFlag       bool
-	jsonFlag           bool
+	basedirFlag         string
+	copyignoredFlag     bool
+	copyuntrackedFlag   bool
+	copymodifiedFlag    bool
+	nocopyFlag          []string
+	copyFlag            []string
+	symlinkFlag         []string
+	hookFlag            []string
+	deleteHookFlag      []string
+	preCreateHookFlag   []string
+	switchHookFlag      []string
+	moveHookFlag        []string
+	postDeleteHookFlag  []string
+	hookModeFlag        string
+	hookTimeoutFlag     string
+	removerFlag         string
+	allowDeleteDefault  bool
+	relativeFlag        bool
+	abbrevFlag          bool
+	jsonFlag            bool
+	formatFlag          string
+	pruneMergedFlag     bool
+	includeBranchesFlag bool
+	yesFlag             bool
+	dryRunFlag          bool
+	restoreFlag         string
+	trashFlag           bool
+	expireTrashFlag     string
+	olderThanFlag       string
+	doctorFlag          bool
+	gcFlag              bool
+	interactiveFlag     bool
+	multiplexerFlag     string
+	tmuxFlag            bool
+	openFlag            bool
+	editorFlag          string
+	promptFlag          bool
+	foreachFlag         bool
+	parallelFlag        int
+	filterFlag          string
+	execFlag            string
+	ephemeralFlag       bool
+	patchFlag           string
+	logsFlag            string
+	rerunHooksFlag      string
 )
 
 var rootCmd = &cobra.Command{
@@ -66,13 +101,30 @@ var rootCmd = &cobra.Command{
 
 Examples:
   git wt                                         List all worktrees
+  git wt --format '{{.Branch}}'                  List worktrees using a Go template
+  git wt -i                                      Choose a worktree or branch with a fuzzy finder
   git wt <branch|worktree|path>                  Switch to worktree (create worktree/branch if needed)
   git wt <branch|worktree|path> <start-point>    Create worktree from start-point (e.g., origin/main)
+  git wt - | @{-N}                               Switch back to the previous (N-th previous) worktree
+  git wt --tmux <branch|worktree|path>           Open worktree in a tmux window (--multiplexer zellij for tabs)
+  git wt --open <branch|worktree|path>           Switch to worktree and open it in the editor (wt.editor)
+  git wt --prompt [--format <template>]          Print a short status of the current worktree for shell prompts
+  git wt --foreach [--parallel N] -- <command>   Run a command in every worktree (--filter <glob> to select)
+  git wt --exec <branch|worktree|path> -- <cmd>  Run a command in a worktree (created if needed) and exit with its code
+  git wt --ephemeral [<start-point>] -- <cmd>    Run a command in a throwaway worktree and print the resulting diff
+  git wt --logs <branch|worktree|path>           Show the latest hook runs of a worktree with their output
+  git wt --rerun-hooks <branch|worktree|path>    Run the hooks (wt.hook) again on an existing worktree
   git wt -b <branch> <worktree>                  Create worktree with a different branch name
   git wt -d <branch|worktree|path>...            Delete worktree and branch (safe)
   git wt -D <branch|worktree|path>...            Force delete worktree and branch
   git wt -m [<old>] <new>                        Rename worktree directory and branch (safe)
   git wt -M [<old>] <new>                        Force rename (overwrite existing branch, allow moving dirty/locked worktrees)
+  git wt --prune-merged [--include-branches]     Delete worktrees (and branches) merged into the default branch
+  git wt --older-than <age> [-d|-D]              List (or delete) worktrees inactive for longer than age
+  git wt -d --dry-run [--json] <branch|...>...   Show what -d/-D/-m/-M/--prune-merged would do without doing it
+  git wt --doctor | --gc [-D]                    Find / fix orphaned basedir directories and missing worktrees
+  git wt --restore <worktree>                    Restore a deleted worktree (with its uncommitted changes)
+  git wt --trash | --expire-trash <age>          List deleted worktrees kept for --restore / drop those older than age
 
 Note: The default branch (e.g., main, master) is protected from accidental deletion or rename.
       Pass --allow-delete-default to override the protection in any of the cases below.
@@ -94,6 +146,20 @@ Shell Integration:
   # powershell ($PROFILE)
   Invoke-Expression (git-wt --init powershell | Out-String)
 
+  # nushell (save to an autoload directory)
+  git-wt --init nu | save -f ($nu.data-dir | path join "vendor/autoload/git-wt.nu")
+
+  # elvish (~/.config/elvish/rc.elv)
+  eval (git-wt --init elvish | slurp)
+
+  Add the status of the current worktree (git wt --prompt) to your prompt:
+
+  # zsh (~/.zshrc), in RPROMPT
+  eval "$(git-wt --init zsh --prompt)"
+
+  # starship
+  git-wt --init starship --prompt >> ~/.config/starship.toml
+
 Configuration:
   Configuration is done via git config. All config options can be overridden
   with flags for a single invocation.
@@ -139,16 +205,66 @@ Configuration:
   wt.hook (--hook)
     Commands to run after creating a new worktree.
     Can be specified multiple times. Hooks run in the new worktree directory.
+    Prefix a hook with "&" to run it in the background (see wt.hookmode).
     Note: Hooks do NOT run when switching to an existing worktree.
     Example: git config --add wt.hook "npm install"
-             git config --add wt.hook "go generate ./..."
+             git config --add wt.hook "&go generate ./..."
+
+  wt.hookmode (--hookmode)
+    How wt.hook hooks run:
+      - serial: One after another; git wt waits for them (default)
+      - parallel: All at once; git wt waits for all of them
+      - background: Detached, so that git wt returns right away
+    Background hooks write their output to a log per worktree, and failures
+    are reported the next time git wt lists or switches to the worktree.
+    With --exec and --ephemeral, all hooks run before the command.
+    Example: git config wt.hookmode parallel
+
+  wt.hooktimeout (--hooktimeout)
+    Kill hooks (with all their processes) that run longer than this, e.g., 90s
+    or 5m; a plain number is seconds. A hook that times out has failed.
+    Does not apply to background hooks.
+    Default: 0 (no timeout)
+    Example: git config wt.hooktimeout 10m
+
+  Hooks may start with a failure policy in brackets, after "&" if any:
+    [abort]    Fail, and stop running hooks (default)
+    [warn]     Print a warning and go on (delete hooks: delete anyway)
+    [retry=N]  Run the hook up to N more times first (e.g., [retry=2,warn])
+    Example: git config --add wt.deletehook '[warn] git push origin --delete "$GIT_WT_BRANCH"'
+
+  Every hook run (command, start and end time, exit code and output) is kept
+  in a log per worktree under the git common dir, also after the worktree is
+  deleted; git wt --logs <worktree> shows the latest runs, and
+  git wt --rerun-hooks <worktree> runs wt.hook again, e.g., after a failure.
 
   wt.deletehook (--deletehook)
     Commands to run before deleting a worktree.
     Can be specified multiple times. Hooks run in the worktree directory
     before it is removed, so you can perform cleanup (e.g., push branches).
     Note: Hooks do NOT run when deleting a branch without a worktree.
-    Example: git config --add wt.deletehook "git push origin --delete $(git branch --show-current)"
+    Example: git config --add wt.deletehook 'git push origin --delete "$GIT_WT_BRANCH"'
+
+  wt.precreatehook (--precreatehook)
+    Commands to run before creating a new worktree, in the main working tree.
+    If one fails, the worktree and its branch are not created, so these can
+    enforce policies such as branch naming.
+    Example: git config --add wt.precreatehook 'case "$GIT_WT_BRANCH" in feature/*|fix/*) ;; *) exit 1 ;; esac'
+
+  wt.switchhook (--switchhook)
+    Commands to run when switching to an existing worktree, in that worktree.
+    If one fails, git wt exits with an error and does not cd.
+    Example: git config --add wt.switchhook "git fetch --quiet"
+
+  wt.movehook (--movehook)
+    Commands to run after renaming a worktree with -m/-M, in the renamed worktree.
+    GIT_WT_OLD_PATH and GIT_WT_OLD_BRANCH hold the path and branch before the rename.
+    Example: git config --add wt.movehook 'tmux rename-window "$GIT_WT_BRANCH"'
+
+  wt.postdeletehook (--postdeletehook)
+    Commands to run after a worktree and its branch are deleted, in the main
+    working tree.
+    Example: git config --add wt.postdeletehook 'rm -rf "$HOME/.cache/myapp/$GIT_WT_NAME"'
 
   wt.remover (--remover)
     Custom command to remove the worktree directory instead of 'git worktree remove'.
@@ -158,6 +274,18 @@ Configuration:
     Default: (not set, uses 'git worktree remove')
     Example: git config wt.remover "trash-put"
 
+  Hooks and the remover get these environment variables (empty if not applicable):
+    GIT_WT_EVENT        precreate, create, switch, move, delete or postdelete
+    GIT_WT_PATH         worktree directory
+    GIT_WT_BRANCH       branch of the worktree (empty if detached)
+    GIT_WT_NAME         worktree directory relative to wt.basedir
+    GIT_WT_MAIN_ROOT    root of the main working tree
+    GIT_WT_SOURCE_PATH  worktree git wt was run in, which files are copied from (precreate, create, switch)
+    GIT_WT_START_POINT  start-point of the new branch (precreate, create)
+    GIT_WT_NEW_BRANCH   1 if the branch is created with the worktree, else 0 (precreate, create)
+    GIT_WT_OLD_PATH     worktree directory before the rename (move only)
+    GIT_WT_OLD_BRANCH   branch before the rename (move only)
+
   wt.nocd (--nocd)
     Do not change directory to the worktree. Only print the worktree path.
     Supported values:
@@ -174,7 +302,58 @@ Configuration:
     subdirectory relative to the repository root (like git diff --relative).
     Falls back to worktree root if the subdirectory does not exist in the worktree.
     Default: false
-    Example: git config wt.relative true`,
+    Example: git config wt.relative true
+
+  wt.abbrev (--abbrev)
+    Take a target that is neither a worktree nor a branch as an abbreviation:
+    switch to the worktree whose branch or directory name contains it at the
+    start of a word (e.g., "auth" for feature/JIRA-123-auth-refresh). If
+    several match, they are listed and nothing happens. Without it, the target
+    is created as a new branch and matching worktrees are only pointed out.
+    Default: false
+    Example: git config wt.abbrev true
+
+  wt.listformat (--format)
+    Go template used to print each worktree when listing (instead of the table).
+    Fields: .Path, .Name (directory relative to wt.basedir), .Branch, .Head,
+    .Bare, .Current, .Modified, .Untracked, .Ahead, .Behind, .Upstream,
+    .Locked, .LockReason, .Prunable, .PrunableReason
+    The escape sequences \t and \n are expanded. --json takes precedence.
+    Default: (not set, prints a table)
+    Example: git config wt.listformat '{{.Branch}}\t{{.Path}}'
+
+  wt.multiplexer (--multiplexer, --tmux)
+    Open worktrees in a window of the terminal multiplexer git wt runs in,
+    instead of changing the directory of the current shell. An existing window
+    for the worktree is focused, otherwise one named after the branch is
+    created in the worktree. Deleting a worktree closes its window. Only
+    windows git wt opened are focused or closed.
+    Supported values: tmux, zellij, none
+    Outside a tmux/zellij session, the setting is ignored.
+    Default: (not set)
+    Example: git config wt.multiplexer tmux
+
+  wt.editor (--editor)
+    Editor command run by --open on the worktree git wt switched to or created
+    (with wt.relative, the resolved subdirectory). {path} and {branch} are
+    replaced with the directory and branch; without {path}, the directory is
+    appended. Falls back to $VISUAL and $EDITOR.
+    Default: (not set)
+    Example: git config wt.editor 'code --reuse-window {path}'
+
+  wt.promptformat (--format with --prompt)
+    Go template for git wt --prompt. Fields: .Name (worktree directory
+    relative to basedir), .Branch, .Path, .Main, .Dirty, .OtherDirty (number
+    of other worktrees with uncommitted changes).
+    Default: {{if .Main}}{{.Branch}}{{else}}{{.Name}}{{end}}{{if .Dirty}}*{{end}}{{if .OtherDirty}} +{{.OtherDirty}}{{end}}
+    Example: git config wt.promptformat '{{.Branch}}{{if .Dirty}}*{{end}}'
+
+  wt.trashexpire
+    Age after which worktrees deleted with -D are dropped from the trash,
+    checked whenever a worktree is deleted (e.g., 30d, 2w, 12h; never keeps
+    them).
+    Default: 30d
+    Example: git config wt.trashexpire 2w`,
 	RunE:              runRoot,
 	Args:              cobra.ArbitraryArgs,
 	ValidArgsFunction: completeBranches,
@@ -184,6 +363,10 @@ Configuration:
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -199,7 +382,7 @@ func init() {
 	rootCmd.Flags().BoolVarP(&forceDeleteFlag, "force-delete", "D", false, "Force delete worktree and branch by name or path")
 	rootCmd.Flags().BoolVarP(&moveFlag, "move", "m", false, "Rename worktree directory and branch (safe rename)")
 	rootCmd.Flags().BoolVarP(&forceMoveFlag, "force-move", "M", false, "Force rename worktree directory and branch (allow overwriting existing branch and moving dirty/locked worktrees)")
-	rootCmd.Flags().StringVar(&initShell, "init", "", "Output shell initialization script (bash, zsh, fish, powershell)")
+	rootCmd.Flags().StringVar(&initShell, "init", "", "Output shell initialization script (bash, zsh, fish, powershell, nu, elvish; starship with --prompt)")
 	rootCmd.Flags().BoolVar(&nocd, "nocd", false, "Do not change directory to the worktree (also disables git() wrapper when used with --init)")
 	rootCmd.Flags().BoolVar(&nocd, "no-switch-directory", false, "")
 	if err := rootCmd.Flags().MarkDeprecated("no-switch-directory", "use --nocd instead"); err != nil {
@@ -216,10 +399,42 @@ func init() {
 	rootCmd.Flags().StringArrayVar(&symlinkFlag, "symlink", nil, "Symlink directories matching pattern instead of copying (can be specified multiple times)")
 	rootCmd.Flags().StringArrayVar(&hookFlag, "hook", nil, "Run command after creating new worktree (can be specified multiple times)")
 	rootCmd.Flags().StringArrayVar(&deleteHookFlag, "deletehook", nil, "Run command before deleting a worktree (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&preCreateHookFlag, "precreatehook", nil, "Run command before creating a worktree; a failure cancels the creation (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&switchHookFlag, "switchhook", nil, "Run command after switching to an existing worktree (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&moveHookFlag, "movehook", nil, "Run command after renaming a worktree with -m/-M (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&postDeleteHookFlag, "postdeletehook", nil, "Run command in the main working tree after deleting a worktree (can be specified multiple times)")
+	rootCmd.Flags().StringVar(&hookModeFlag, "hookmode", "", "How to run hooks after creating a worktree: serial, parallel or background")
+	rootCmd.Flags().StringVar(&hookTimeoutFlag, "hooktimeout", "", "Kill hooks running longer than this (e.g., 90s, 5m; 0 for no timeout)")
 	rootCmd.Flags().StringVar(&removerFlag, "remover", "", "Custom command to remove worktree directory (e.g., trash-put)")
 	rootCmd.Flags().BoolVar(&allowDeleteDefault, "allow-delete-default", false, "Allow deletion of the default branch (main, master)")
 	rootCmd.Flags().BoolVar(&relativeFlag, "relative", false, "Append current subdirectory to worktree path (like git diff --relative)")
+	rootCmd.Flags().BoolVar(&abbrevFlag, "abbrev", false, "Switch to the worktree a target that is neither a worktree nor a branch abbreviates")
 	rootCmd.Flags().BoolVar(&jsonFlag, "json", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&pruneMergedFlag, "prune-merged", false, "Delete worktrees whose branches are merged into the default branch")
+	rootCmd.Flags().BoolVar(&includeBranchesFlag, "include-branches", false, "With --prune-merged, also delete merged branches that have no worktree")
+	rootCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Do not ask for confirmation")
+	rootCmd.Flags().StringVar(&restoreFlag, "restore", "", "Restore a deleted worktree (and its uncommitted changes) from the trash by name, branch or ID")
+	rootCmd.Flags().BoolVar(&trashFlag, "trash", false, "List deleted worktrees kept in the trash")
+	rootCmd.Flags().StringVar(&expireTrashFlag, "expire-trash", "", "Remove trash entries older than the given age (e.g., 30d, 2w, 12h; 0 removes all)")
+	rootCmd.Flags().StringVar(&olderThanFlag, "older-than", "", "List worktrees without activity for longer than the given age (e.g., 30d); delete them with -d/-D")
+	rootCmd.Flags().BoolVar(&doctorFlag, "doctor", false, "Report orphaned directories under basedir and registrations of missing worktrees")
+	rootCmd.Flags().BoolVar(&gcFlag, "gc", false, "Fix the problems reported by --doctor (repair moved worktrees, prune registrations, remove empty directories; -D also removes orphaned worktree directories)")
+	rootCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "With -d/-D/-m/-M/--prune-merged/--older-than/--gc, print the planned actions without changing anything (JSON with --json)")
+	rootCmd.Flags().BoolVarP(&interactiveFlag, "interactive", "i", false, "Override wt.interactive config (choose a worktree or branch with a fuzzy finder)")
+	rootCmd.Flags().StringVar(&multiplexerFlag, "multiplexer", "", "Override wt.multiplexer config (open worktrees in tmux windows or zellij tabs; none to disable)")
+	rootCmd.Flags().BoolVar(&tmuxFlag, "tmux", false, "Open the worktree in a tmux window (same as --multiplexer tmux)")
+	rootCmd.Flags().BoolVar(&openFlag, "open", false, "Open the worktree in the editor (wt.editor, $VISUAL or $EDITOR) after switching to it")
+	rootCmd.Flags().StringVar(&editorFlag, "editor", "", "Override wt.editor config (editor command for --open, e.g. 'code {path}')")
+	rootCmd.Flags().StringVar(&formatFlag, "format", "", "Override wt.listformat config (format list output with a Go template, e.g. '{{.Branch}}\t{{.Path}}'; wt.promptformat with --prompt)")
+	rootCmd.Flags().BoolVar(&foreachFlag, "foreach", false, "Run the command after -- in each worktree and summarize the exit codes (JSON with --json)")
+	rootCmd.Flags().IntVar(&parallelFlag, "parallel", 1, "With --foreach, run the command in up to N worktrees at a time")
+	rootCmd.Flags().StringVar(&filterFlag, "filter", "", "With --foreach, only run in worktrees whose name or branch matches the glob pattern")
+	rootCmd.Flags().StringVar(&execFlag, "exec", "", "Run the command after -- in the worktree of the branch/worktree/path (created if needed) and exit with its exit code")
+	rootCmd.Flags().BoolVar(&ephemeralFlag, "ephemeral", false, "Run the command after -- in a temporary worktree (from the start-point argument, default HEAD), print the resulting diff and delete the worktree")
+	rootCmd.Flags().StringVar(&patchFlag, "patch", "", "With --ephemeral, save the resulting diff to the file instead of printing it")
+	rootCmd.Flags().StringVar(&logsFlag, "logs", "", "Show the latest hook runs of the branch/worktree/path with their output (JSON metadata with --json)")
+	rootCmd.Flags().StringVar(&rerunHooksFlag, "rerun-hooks", "", "Run the hooks (wt.hook) again on the existing worktree of the branch/worktree/path")
+	rootCmd.Flags().BoolVar(&promptFlag, "prompt", false, "Print a short status of the current worktree for shell prompts (with --init, output a prompt snippet)")
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
@@ -227,7 +442,16 @@ func runRoot(cmd *cobra.Command, args []string) error {
 
 	// Handle init flag (only respects --nocd flag, not wt.nocd config)
 	if initShell != "" {
-		return runInit(initShell, nocd)
+		return runInit(initShell, nocd, promptFlag)
+	}
+
+	// Prompt segment: before repository detection, as it must stay fast and
+	// silent outside repositories
+	if promptFlag {
+		if len(args) > 0 {
+			return fmt.Errorf("--prompt does not take arguments")
+		}
+		return printPrompt(ctx, cmd)
 	}
 
 	// Detect repo context once and thread it through context.
@@ -239,9 +463,163 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	}
 	ctx = git.WithRepoContext(ctx, rc)
 
+	// Run a command across worktrees
+	if foreachFlag {
+		if dash := cmd.ArgsLenAtDash(); dash > 0 {
+			return fmt.Errorf("--foreach takes the command after --: git wt --foreach -- <command>")
+		}
+		if branchFlag != "" || deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag || execFlag != "" || ephemeralFlag {
+			return fmt.Errorf("cannot combine --foreach with -b/-d/-D/-m/-M/--exec/--ephemeral")
+		}
+		return runForeach(ctx, cmd, args, parallelFlag, filterFlag)
+	}
+	if cmd.Flags().Changed("parallel") || filterFlag != "" {
+		return fmt.Errorf("--parallel and --filter require --foreach")
+	}
+
+	// Run a command in a single worktree: git wt --exec <target> [<start-point>] -- <command>
+	if execFlag != "" {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 {
+			return fmt.Errorf("--exec requires a command after --: git wt --exec <branch|worktree|path> -- <command>")
+		}
+		if dash > 1 {
+			return fmt.Errorf("too many arguments before --: expected [<start-point>], got %d arguments", dash)
+		}
+		if deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag || openFlag || ephemeralFlag {
+			return fmt.Errorf("cannot combine --exec with -d/-D/-m/-M/--open/--ephemeral")
+		}
+		var startPoint string
+		if dash == 1 {
+			startPoint = args[0]
+		}
+		return runExec(ctx, cmd, execFlag, startPoint, args[dash:])
+	}
+
+	// Run a command in a throwaway worktree: git wt --ephemeral [<start-point>] -- <command>
+	if ephemeralFlag {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 {
+			return fmt.Errorf("--ephemeral requires a command after --: git wt --ephemeral [<start-point>] -- <command>")
+		}
+		if dash > 1 {
+			return fmt.Errorf("too many arguments before --: expected [<start-point>], got %d arguments", dash)
+		}
+		if branchFlag != "" || deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag || openFlag || dryRunFlag {
+			return fmt.Errorf("cannot combine --ephemeral with -b/-d/-D/-m/-M/--open/--dry-run")
+		}
+		var startPoint string
+		if dash == 1 {
+			startPoint = args[0]
+		}
+		return runEphemeral(ctx, cmd, startPoint, args[dash:])
+	}
+	if patchFlag != "" {
+		return fmt.Errorf("--patch requires --ephemeral")
+	}
+
+	// Hook logs, and running the hooks of an existing worktree again
+	if logsFlag != "" || rerunHooksFlag != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--logs and --rerun-hooks do not take arguments")
+		}
+		if logsFlag != "" && rerunHooksFlag != "" {
+			return fmt.Errorf("cannot combine --logs with --rerun-hooks")
+		}
+		if branchFlag != "" || deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag || openFlag || dryRunFlag {
+			return fmt.Errorf("cannot combine --logs/--rerun-hooks with -b/-d/-D/-m/-M/--open/--dry-run")
+		}
+		if logsFlag != "" {
+			return showHookLogs(ctx, cmd, logsFlag)
+		}
+		return rerunHooks(ctx, cmd, rerunHooksFlag)
+	}
+
+	// Trash of deleted worktrees
+	if restoreFlag != "" || trashFlag || expireTrashFlag != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--restore, --trash and --expire-trash do not take arguments")
+		}
+		switch {
+		case restoreFlag != "":
+			return restoreWorktree(ctx, restoreFlag)
+		case expireTrashFlag != "":
+			return expireTrash(ctx, expireTrashFlag)
+		default:
+			return listTrash(ctx)
+		}
+	}
+
+	// Bulk cleanup of merged worktrees (-D allows deleting dirty ones)
+	if pruneMergedFlag {
+		if len(args) > 0 {
+			return fmt.Errorf("--prune-merged does not take arguments")
+		}
+		if branchFlag != "" || moveFlag || forceMoveFlag {
+			return fmt.Errorf("cannot combine --prune-merged with -b/-m/-M")
+		}
+		return pruneMerged(ctx, cmd, forceDeleteFlag)
+	}
+	if includeBranchesFlag {
+		return fmt.Errorf("--include-branches requires --prune-merged")
+	}
+
+	// Health check of basedir and worktree registrations
+	if doctorFlag || gcFlag {
+		if len(args) > 0 {
+			return fmt.Errorf("--doctor and --gc do not take arguments")
+		}
+		if branchFlag != "" || moveFlag || forceMoveFlag || deleteFlag {
+			return fmt.Errorf("cannot combine --doctor/--gc with -b/-d/-m/-M")
+		}
+		if dryRunFlag && !gcFlag {
+			return fmt.Errorf("--dry-run requires -d/-D, -m/-M, --prune-merged, --gc or --older-than with -d/-D")
+		}
+		return doctor(ctx, cmd, gcFlag, forceDeleteFlag)
+	}
+
+	// Report (or, with -d/-D, delete) worktrees without recent activity
+	if olderThanFlag != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--older-than does not take arguments")
+		}
+		if branchFlag != "" || moveFlag || forceMoveFlag {
+			return fmt.Errorf("cannot combine --older-than with -b/-m/-M")
+		}
+		if dryRunFlag && !deleteFlag && !forceDeleteFlag {
+			return fmt.Errorf("--dry-run requires -d/-D, -m/-M, --prune-merged, --gc or --older-than with -d/-D")
+		}
+		return staleWorktrees(ctx, cmd, olderThanFlag, deleteFlag || forceDeleteFlag, forceDeleteFlag)
+	}
+	if dryRunFlag && !deleteFlag && !forceDeleteFlag && !moveFlag && !forceMoveFlag {
+		return fmt.Errorf("--dry-run requires -d/-D, -m/-M, --prune-merged, --gc or --older-than with -d/-D")
+	}
+
+	// Interactive selection (-i, or wt.interactive in a terminal)
+	if interactiveFlag {
+		if len(args) > 0 {
+			return fmt.Errorf("-i/--interactive does not take arguments")
+		}
+		if branchFlag != "" || deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag {
+			return fmt.Errorf("cannot combine -i/--interactive with -b/-d/-D/-m/-M")
+		}
+		return pickWorktree(ctx, cmd)
+	}
+
 	// No arguments: list worktrees
 	if len(args) == 0 {
-		return listWorktrees(ctx)
+		if ok, err := autoInteractive(ctx, cmd); err != nil {
+			return err
+		} else if ok {
+			return pickWorktree(ctx, cmd)
+		}
+		if openFlag {
+			return fmt.Errorf("--open requires a worktree to switch to")
+		}
+		return listWorktrees(ctx, cmd)
+	}
+	if openFlag && (deleteFlag || forceDeleteFlag || moveFlag || forceMoveFlag) {
+		return fmt.Errorf("cannot combine --open with -d/-D/-m/-M")
 	}
 
 	// Handle delete flags (multiple arguments allowed)
@@ -253,7 +631,7 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("cannot combine -m/-M with -d/-D")
 		}
 		args = uniqueArgs(args)
-		return deleteWorktrees(ctx, cmd, args, true)
+		return deleteWorktrees(ctx, cmd, args, true, deleteOptions{})
 	}
 	if deleteFlag {
 		if branchFlag != "" {
@@ -263,7 +641,7 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("cannot combine -m/-M with -d/-D")
 		}
 		args = uniqueArgs(args)
-		return deleteWorktrees(ctx, cmd, args, false)
+		return deleteWorktrees(ctx, cmd, args, false, deleteOptions{})
 	}
 
 	// Handle move/rename flags
@@ -280,6 +658,14 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("too many arguments: expected <branch> [<start-point>], got %d arguments", len(args))
 	}
 
+	// git wt - / git wt @{-N}: switch back to a previous worktree
+	if n, ok := parsePreviousRef(args[0]); ok {
+		if len(args) > 1 || branchFlag != "" {
+			return fmt.Errorf("%s switches to an existing worktree and takes no start-point or -b/--branch", args[0])
+		}
+		return switchToPrevious(ctx, cmd, args[0], n)
+	}
+
 	wtName := args[0]
 	var startPoint string
 	if len(args) == 2 {
@@ -330,12 +716,56 @@ func loadConfig(ctx context.Context, cmd *cobra.Command) (git.Config, error) {
 	if cmd.Flags().Changed("deletehook") {
 		cfg.DeleteHooks = deleteHookFlag
 	}
+	if cmd.Flags().Changed("precreatehook") {
+		cfg.PreCreateHooks = preCreateHookFlag
+	}
+	if cmd.Flags().Changed("switchhook") {
+		cfg.SwitchHooks = switchHookFlag
+	}
+	if cmd.Flags().Changed("movehook") {
+		cfg.MoveHooks = moveHookFlag
+	}
+	if cmd.Flags().Changed("postdeletehook") {
+		cfg.PostDeleteHooks = postDeleteHookFlag
+	}
+	if cmd.Flags().Changed("hookmode") {
+		cfg.HookMode = hookModeFlag
+	}
+	if cmd.Flags().Changed("hooktimeout") {
+		timeout, err := git.ParseHookTimeout(hookTimeoutFlag)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid --hooktimeout: %w", err)
+		}
+		cfg.HookTimeout = timeout
+	}
 	if cmd.Flags().Changed("remover") {
 		cfg.Remover = removerFlag
 	}
 	if cmd.Flags().Changed("relative") {
 		cfg.Relative = relativeFlag
 	}
+	if cmd.Flags().Changed("abbrev") {
+		cfg.Abbrev = abbrevFlag
+	}
+	if cmd.Flags().Changed("format") {
+		if promptFlag {
+			cfg.PromptFormat = formatFlag
+		} else {
+			cfg.ListFormat = formatFlag
+		}
+	}
+	if cmd.Flags().Changed("interactive") {
+		cfg.Interactive = interactiveFlag
+	}
+	if cmd.Flags().Changed("multiplexer") {
+		cfg.Multiplexer = multiplexerFlag
+	}
+	if tmuxFlag {
+		cfg.Multiplexer = "tmux"
+	}
+	if cmd.Flags().Changed("editor") {
+		cfg.Editor = editorFlag
+	}
 
 	return cfg, nil
 }
@@ -357,19 +787,41 @@ func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]s
 
 	// For delete flags, allow multiple arguments (same completion as first arg)
 	// For first argument or delete mode, complete with worktrees and local branches
+	candidates, err := targetCandidates(ctx, cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	completions := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		completions = append(completions, fmt.Sprintf("%s\t%s", c.name, c.desc))
+	}
 
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// candidate is a target offered by shell completion and the interactive
+// picker: a branch or worktree name with a description of what it refers to.
+type candidate struct {
+	name string
+	desc string
+}
+
+// targetCandidates returns the branches and worktree directory names that
+// can be passed as the first argument, marked with the worktree they belong
+// to and the subject of their last commit.
+func targetCandidates(ctx context.Context, cmd *cobra.Command) ([]candidate, error) {
 	// Collect unique branch names and worktree directory names
 	seen := make(map[string]struct{})
-	var completions []string
+	var candidates []candidate
 
 	// Get worktree base directory for relative path calculation
 	cfg, err := loadConfig(ctx, cmd)
 	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
+		return nil, err
 	}
 	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
 	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
+		return nil, err
 	}
 
 	// Track which names are worktrees
@@ -414,7 +866,7 @@ func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]s
 							desc = fmt.Sprintf("[branch: worktree=%s] %s", wtInfo, truncateString(msg, 40))
 						}
 					}
-					completions = append(completions, fmt.Sprintf("%s\t%s", wt.Branch, desc))
+					candidates = append(candidates, candidate{name: wt.Branch, desc: desc})
 				}
 			}
 
@@ -440,7 +892,7 @@ func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]s
 							desc = fmt.Sprintf("[worktree: branch=%s] %s", branchInfo, truncateString(msg, 40))
 						}
 					}
-					completions = append(completions, fmt.Sprintf("%s\t%s", wtDirName, desc))
+					candidates = append(candidates, candidate{name: wtDirName, desc: desc})
 				}
 			}
 		}
@@ -456,12 +908,12 @@ func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]s
 				if msg := commitMessages[branch]; msg != "" {
 					desc = "[branch] " + truncateString(msg, 40)
 				}
-				completions = append(completions, fmt.Sprintf("%s\t%s", branch, desc))
+				candidates = append(candidates, candidate{name: branch, desc: desc})
 			}
 		}
 	}
 
-	return completions, cobra.ShellCompDirectiveNoFileComp
+	return candidates, nil
 }
 
 // completeStartPoint returns completion for start-point (second argument).
@@ -520,7 +972,11 @@ func truncateString(s string, maxLen int) string {
 	return string(r[:maxLen-3]) + "..."
 }
 
-func listWorktrees(ctx context.Context) error {
+func listWorktrees(ctx context.Context, cmd *cobra.Command) error {
+	if jsonFlag && cmd.Flags().Changed("format") {
+		return fmt.Errorf("cannot combine --json with --format")
+	}
+
 	worktrees, err := git.ListWorktrees(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
@@ -531,12 +987,53 @@ func listWorktrees(ctx context.Context) error {
 		return fmt.Errorf("failed to get current location: %w", err)
 	}
 
+	statuses := git.WorktreeStatuses(ctx, worktrees)
+
+	cfg, err := loadConfig(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand basedir: %w", err)
+	}
+	reportBackgroundHooks(ctx, "")
+
+	// --json takes precedence over a wt.listformat default.
 	if jsonFlag {
-		return printJSON(os.Stdout, worktrees, currentPath)
+		return printJSON(os.Stdout, newWorktreeItems(worktrees, statuses, currentPath, baseDir))
+	}
+	if cfg.ListFormat != "" {
+		return printFormat(os.Stdout, cfg.ListFormat, newWorktreeItems(worktrees, statuses, currentPath, baseDir))
 	}
 
-	table := tablewriter.NewTable(os.Stdout,
-		tablewriter.WithHeader([]string{"", "PATH", "BRANCH", "HEAD"}),
+	table := newTable(os.Stdout, []string{"", "PATH", "BRANCH", "HEAD", "STATUS", "UPSTREAM"})
+
+	for i, wt := range worktrees {
+		marker := ""
+		if wt.Path == currentPath {
+			marker = "*"
+		}
+		branch := wt.Branch
+		if wt.Bare {
+			branch = "(bare)"
+		}
+		if err := table.Append([]string{marker, wt.Path, branch, wt.Head, formatStatus(wt, statuses[i]), formatUpstream(statuses[i])}); err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
+
+// newTable returns a borderless table with the given header, as used by the
+// list output.
+func newTable(w io.Writer, header []string) *tablewriter.Table {
+	return tablewriter.NewTable(w,
+		tablewriter.WithHeader(header),
 		tablewriter.WithHeaderAlignment(tw.AlignLeft),
 		tablewriter.WithHeaderPaddingPerColumn([]tw.Padding{tw.PaddingNone}),
 		tablewriter.WithRowPaddingPerColumn([]tw.Padding{tw.PaddingNone}),
@@ -562,28 +1059,74 @@ func listWorktrees(ctx context.Context) error {
 				},
 			},
 		}))
+}
 
-	for _, wt := range worktrees {
-		marker := ""
-		if wt.Path == currentPath {
-			marker = "*"
-		}
-		branch := wt.Branch
-		if wt.Bare {
-			branch = "(bare)"
-		}
-		if err := table.Append([]string{marker, wt.Path, branch, wt.Head}); err != nil {
-			return fmt.Errorf("failed to append row: %w", err)
-		}
+// formatStatus renders the state of a worktree for the list table
+// (e.g., "!2 ?1" for 2 modified and 1 untracked files, prefixed with
+// "locked" or "prunable" when applicable). Clean worktrees render as an
+// empty string.
+func formatStatus(wt git.Worktree, st *git.WorktreeStatus) string {
+	var parts []string
+	if wt.Locked {
+		parts = append(parts, "locked")
+	}
+	if wt.Prunable {
+		parts = append(parts, "prunable")
+	}
+	if st == nil {
+		return strings.Join(parts, " ")
+	}
+	if st.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("!%d", st.Modified))
 	}
+	if st.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("?%d", st.Untracked))
+	}
+	return strings.Join(parts, " ")
+}
 
-	if err := table.Render(); err != nil {
-		return fmt.Errorf("failed to render table: %w", err)
+// formatUpstream renders the upstream branch and ahead/behind counts of a
+// worktree for the list table (e.g., "origin/main ↑1 ↓2").
+func formatUpstream(st *git.WorktreeStatus) string {
+	if st == nil || st.Upstream == "" {
+		return ""
 	}
-	return nil
+	parts := []string{st.Upstream}
+	if st.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", st.Ahead))
+	}
+	if st.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", st.Behind))
+	}
+	return strings.Join(parts, " ")
 }
 
-func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string, force bool) error {
+// lockedWorktreeError returns the error reported when a command refuses to
+// touch a locked worktree, including the lock reason when one was given.
+func lockedWorktreeError(name string, wt *git.Worktree) error {
+	if wt.LockReason != "" {
+		return fmt.Errorf("worktree %q is locked (reason: %s): run 'git worktree unlock %s' first", name, wt.LockReason, wt.Path)
+	}
+	return fmt.Errorf("worktree %q is locked: run 'git worktree unlock %s' first", name, wt.Path)
+}
+
+// deleteOptions adjusts deleteWorktrees for callers other than -d/-D.
+type deleteOptions struct {
+	out     io.Writer // where deletions are reported, stdout if nil
+	noTrash bool      // do not keep forced deletes in the trash
+}
+
+func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string, force bool, opts deleteOptions) error {
+	out := opts.out
+	if out == nil {
+		out = os.Stdout
+	}
+	// git's own "Deleted branch" message goes to stdout.
+	deleteBranch := git.DeleteBranchInDir
+	if out != os.Stdout {
+		deleteBranch = git.DeleteBranchQuietly
+	}
+
 	// Get main repo root before any deletion (needed for running git commands after worktree removal)
 	mainRoot, err := git.MainRepoRoot(ctx)
 	if err != nil {
@@ -594,6 +1137,10 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	baseDir, err := git.ExpandBaseDir(ctx, cfg.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand basedir: %w", err)
+	}
 
 	// Check if current directory is one of the worktrees being deleted
 	currentWt, err := git.CurrentWorktree(ctx)
@@ -601,7 +1148,22 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 		currentWt = "" // Not in a worktree, continue
 	}
 
+	if !dryRunFlag {
+		autoExpireTrash(ctx, cfg)
+	}
+
 	var needCdToMain bool
+	var plans []plannedChange
+	var windows []worktreeWindow
+	var postDeleteErrs []error
+	defer func() { closeWindows(ctx, cfg, windows) }()
+	// If we deleted the current worktree, have the shell integration cd to
+	// the main repo, also when a later target fails
+	defer func() {
+		if needCdToMain && !dryRunFlag {
+			requestCd(mainRoot)
+		}
+	}()
 
 	for _, branch := range branches {
 		// Find worktree by branch or directory name
@@ -612,6 +1174,18 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 
 		// Case 1: Worktree exists - remove worktree and optionally branch
 		if wt != nil {
+			// Locked worktrees cannot be removed (even with --force, git requires
+			// it twice). Surface the lock reason instead of git's failure.
+			if wt.Locked {
+				return lockedWorktreeError(branch, wt)
+			}
+
+			// 'git worktree remove' refuses the main working tree, but only
+			// after delete hooks have run; refuse up front instead.
+			if git.SamePath(wt.Path, mainRoot) {
+				return fmt.Errorf("cannot delete the main working tree at %q", wt.Path)
+			}
+
 			// Check if we're deleting the current worktree
 			if currentWt != "" && wt.Path == currentWt {
 				needCdToMain = true
@@ -638,8 +1212,17 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 				}
 			}
 
-			// Check for modified or untracked files (only for safe delete)
-			if !force {
+			// Check whether the branch is merged into the default branch (also
+			// before worktree removal, as the cwd may be the removed worktree).
+			forceBranch := force
+			if branchExists && !force && !isDefault {
+				forceBranch = isMergedIntoDefault(ctx, wt.Branch)
+			}
+
+			// Check for modified or untracked files (only for safe delete).
+			// A prunable worktree's directory is already gone, so there is
+			// nothing to check, run hooks in, or hand to the remover.
+			if !force && !wt.Prunable {
 				modifiedFiles, err := git.ListModifiedFiles(ctx, wt.Path)
 				if err != nil {
 					return fmt.Errorf("failed to check for modified files: %w", err)
@@ -657,14 +1240,51 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 				}
 			}
 
+			if dryRunFlag {
+				plans = append(plans, planWorktreeDelete(branch, wt, cfg, force, branchExists, isDefault, forceBranch, wt.Path == currentWt, mainRoot))
+				continue
+			}
+
+			// Snapshot the branch tip and uncommitted changes so that the
+			// worktree can be brought back with --restore. Only forced
+			// deletes can lose work (a safe delete refuses dirty worktrees
+			// and unmerged branches). -D is the way out of a broken worktree
+			// (e.g., with an unborn HEAD), so it goes on without a snapshot
+			// if none can be taken. The snapshot is dropped again if the
+			// worktree is still there after a delete hook, the remover or
+			// 'git worktree remove' failed.
+			var trashed *git.TrashEntry
+			if force && !wt.Prunable && !opts.noTrash {
+				trashed, err = git.TrashWorktree(ctx, wt, wtDir)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to save worktree %q to trash, deleting it anyway: %v\n", branch, err)
+				}
+			}
+			discardTrash := func() {
+				if trashed == nil {
+					return
+				}
+				if err := git.DropTrashEntry(ctx, trashed.ID); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to drop trash entry %q: %v\n", trashed.ID, err)
+				}
+			}
+
 			// Run delete hooks before worktree removal (directory still exists)
-			if err := git.RunHooks(ctx, cfg.DeleteHooks, wt.Path, os.Stderr); err != nil {
-				return fmt.Errorf("delete hook failed for worktree %q: %w", branch, err)
+			if !wt.Prunable {
+				if err := git.RunHooks(ctx, cfg.DeleteHooks, wt.Path, deleteHookEnv(wt, baseDir, mainRoot), cfg.HookTimeout, os.Stderr); err != nil {
+					discardTrash()
+					return fmt.Errorf("delete hook failed for worktree %q: %w", branch, err)
+				}
 			}
 
 			// Remove worktree
-			if cfg.Remover != "" {
-				if err := git.RunRemover(ctx, cfg.Remover, wt.Path, mainRoot, os.Stderr); err != nil {
+			if cfg.Remover != "" && !wt.Prunable {
+				if err := git.RunRemover(ctx, cfg.Remover, wt.Path, mainRoot, deleteHookEnv(wt, baseDir, mainRoot), os.Stderr); err != nil {
+					// A remover that got the directory out of the way keeps
+					// the snapshot, as the worktree is as good as gone.
+					if _, serr := os.Stat(wt.Path); serr == nil {
+						discardTrash()
+					}
 					return fmt.Errorf("remover failed for worktree %q: %w", branch, err)
 				}
 				if err := git.PruneWorktrees(ctx); err != nil {
@@ -672,9 +1292,25 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 				}
 			} else {
 				if err := git.RemoveWorktree(ctx, wt.Path, force); err != nil {
+					discardTrash()
 					return fmt.Errorf("failed to remove worktree: %w", err)
 				}
 			}
+			// The cwd is gone with the current worktree: go on from the main
+			// working tree, so that git can still run for further targets.
+			if wt.Path == currentWt {
+				if err := os.Chdir(mainRoot); err != nil {
+					return fmt.Errorf("failed to change directory to %s: %w", mainRoot, err)
+				}
+			}
+			if trashed != nil && trashed.Changes != "" {
+				fmt.Fprintf(os.Stderr, "Saved uncommitted changes of %q to trash (restore with 'git wt --restore %s')\n", wtDir, wtDir)
+			}
+			windowName := wt.Branch
+			if windowName == git.DetachedMarker {
+				windowName = filepath.Base(wt.Path)
+			}
+			windows = append(windows, worktreeWindow{name: windowName, path: wt.Path, current: wt.Path == currentWt})
 
 			// Delete branch (only if it exists as a local branch)
 			// Let git branch -d/-D handle the merge check
@@ -688,26 +1324,35 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 				if isDefault && !allowDeleteDefault {
 					// Default branch is protected - only delete worktree
 					if wtDir == wt.Branch {
-						fmt.Printf("Deleted worktree %q (branch is default, not deleted)\n", wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree %q (branch is default, not deleted)\n", wt.Branch)
 					} else {
-						fmt.Printf("Deleted worktree %q (branch %q is default, not deleted)\n", wtDir, wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree %q (branch %q is default, not deleted)\n", wtDir, wt.Branch)
 					}
-				} else if err := git.DeleteBranchInDir(ctx, wt.Branch, force, dir); err != nil {
+				} else if err := deleteBranch(ctx, wt.Branch, forceBranch, dir); err != nil {
 					// Treat as non-fatal since worktree removal succeeded
 					if wtDir == wt.Branch {
-						fmt.Printf("Deleted worktree, but failed to delete branch %q (use -D to force)\n", wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree, but failed to delete branch %q (use -D to force)\n", wt.Branch)
 					} else {
-						fmt.Printf("Deleted worktree %q, but failed to delete branch %q (use -D to force)\n", wtDir, wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree %q, but failed to delete branch %q (use -D to force)\n", wtDir, wt.Branch)
 					}
 				} else {
 					if wtDir == wt.Branch {
-						fmt.Printf("Deleted worktree and branch %q\n", wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree and branch %q\n", wt.Branch)
 					} else {
-						fmt.Printf("Deleted worktree %q and branch %q\n", wtDir, wt.Branch)
+						fmt.Fprintf(out, "Deleted worktree %q and branch %q\n", wtDir, wt.Branch)
 					}
 				}
 			} else {
-				fmt.Printf("Deleted worktree %q (branch %q did not exist locally)\n", wtDir, wt.Branch)
+				fmt.Fprintf(out, "Deleted worktree %q (branch %q did not exist locally)\n", wtDir, wt.Branch)
+			}
+
+			// Post-delete hooks run from the main working tree, as the
+			// worktree is gone. They cannot undo the deletion, so a failure
+			// does not stop the remaining targets; it is reported at the end.
+			env := deleteHookEnv(wt, baseDir, mainRoot)
+			env.Event = git.HookEventPostDelete
+			if err := git.RunHooks(ctx, cfg.PostDeleteHooks, mainRoot, env, cfg.HookTimeout, os.Stderr); err != nil {
+				postDeleteErrs = append(postDeleteErrs, fmt.Errorf("post-delete hook failed for worktree %q: %w", branch, err))
 			}
 			continue
 		}
@@ -740,19 +1385,46 @@ func deleteWorktrees(ctx context.Context, cmd *cobra.Command, branches []string,
 			return fmt.Errorf("cannot delete default branch %q: use --allow-delete-default to override", branch)
 		}
 
-		if err := git.DeleteBranch(ctx, branch, force); err != nil {
+		if dryRunFlag {
+			plans = append(plans, plannedChange{
+				Target:    branch,
+				Operation: "delete",
+				Branch:    branch,
+				Actions: []plannedAction{{
+					Action: actionDeleteBranch,
+					Branch: branch,
+					Force:  force || isMergedIntoDefault(ctx, branch),
+				}},
+			})
+			continue
+		}
+
+		if err := deleteBranch(ctx, branch, force || isMergedIntoDefault(ctx, branch), ""); err != nil {
 			return fmt.Errorf("failed to delete branch (use -D to force): %w", err)
 		}
-		fmt.Printf("Deleted branch %q (no worktree was associated)\n", branch)
+		fmt.Fprintf(out, "Deleted branch %q (no worktree was associated)\n", branch)
 	}
 
-	// If we deleted the current worktree, print main repo path for shell integration to cd
-	// Only output if shell integration is active (GIT_WT_SHELL_INTEGRATION=1)
-	if needCdToMain && os.Getenv("GIT_WT_SHELL_INTEGRATION") == "1" {
-		fmt.Println(mainRoot)
+	if dryRunFlag {
+		return printPlan(os.Stdout, plans, jsonFlag)
 	}
 
-	return nil
+	return errors.Join(postDeleteErrs...)
+}
+
+// isMergedIntoDefault reports whether branch is merged into the default
+// branch, including squash and rebase merges. 'git branch -d' only accepts
+// branches whose tip is reachable from HEAD or their upstream, which refuses
+// merged branches when run from another feature worktree or after a squash
+// merge; a branch merged into the default branch can be deleted with -D
+// without losing work. Errors are treated as "not merged" so that the
+// decision falls back to 'git branch -d'.
+func isMergedIntoDefault(ctx context.Context, branch string) bool {
+	merged, err := git.IsBranchMerged(ctx, branch)
+	if err != nil {
+		return false
+	}
+	return merged
 }
 
 // moveWorktree renames a worktree's directory and its associated branch in
@@ -834,6 +1506,18 @@ func moveWorktree(ctx context.Context, cmd *cobra.Command, args []string, force
 		return fmt.Errorf("cannot rename worktree at %q: it has no branch (detached HEAD)", src.Path)
 	}
 
+	if src.Prunable {
+		if src.PrunableReason != "" {
+			return fmt.Errorf("cannot rename worktree at %q: its directory is missing (%s); run 'git worktree prune' to clean it up", src.Path, src.PrunableReason)
+		}
+		return fmt.Errorf("cannot rename worktree at %q: its directory is missing; run 'git worktree prune' to clean it up", src.Path)
+	}
+	// -M moves locked worktrees (see git.MoveWorktree); -m refuses up front
+	// so the lock reason is reported instead of git's failure.
+	if src.Locked && !force {
+		return fmt.Errorf("%w (or use -M to force)", lockedWorktreeError(src.Branch, src))
+	}
+
 	// Reject the main working tree explicitly. The 1-arg form already blocks
 	// this via rc.IsLinkedWorktree(), but the 2-arg form can resolve the
 	// main worktree (e.g. `git wt -m . new`, or by passing its path), so
@@ -940,6 +1624,29 @@ func moveWorktree(ctx context.Context, cmd *cobra.Command, args []string, force
 		inside = curWt == oldPath
 	}
 
+	if dryRunFlag {
+		plan := plannedChange{Target: src.Branch, Operation: "rename", Path: oldPath, Branch: src.Branch}
+		if oldQuery != "" {
+			plan.Target = oldQuery
+		}
+		if !samePath {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionMoveWorktree, Path: oldPath, NewPath: newPath, Force: force})
+			if oldParent := filepath.Dir(oldPath); strings.HasPrefix(oldParent, baseDir+string(filepath.Separator)) {
+				plan.Actions = append(plan.Actions, plannedAction{Action: actionRemoveEmptyParents, Path: oldParent})
+			}
+		}
+		if src.Branch != newName {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionRenameBranch, Branch: src.Branch, NewBranch: newName, Force: force})
+		}
+		if inside {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionChangeDirectory, Path: newPath})
+		}
+		for _, hook := range cfg.MoveHooks {
+			plan.Actions = append(plan.Actions, plannedAction{Action: actionRunHook, Command: hook, Path: newPath})
+		}
+		return printPlan(os.Stdout, []plannedChange{plan}, jsonFlag)
+	}
+
 	// Move the directory first. Skip when source and target paths are
 	// identical (only the branch changes).
 	if !samePath {
@@ -972,105 +1679,240 @@ func moveWorktree(ctx context.Context, cmd *cobra.Command, args []string, force
 		fmt.Fprintf(os.Stderr, "Renamed worktree to %q and branch to %q\n", newPath, newName)
 	}
 
-	// Shell integration: when the current worktree was renamed, print the new
-	// path so the shell wrapper can cd there. The shell wrapper inspects
-	// -m/-M to keep this consistent with wt.nocd=create (existing worktree,
-	// not a fresh creation).
-	if inside && os.Getenv("GIT_WT_SHELL_INTEGRATION") == "1" {
-		fmt.Println(newPath)
+	// Shell integration: when the current worktree was renamed, have the
+	// shell wrapper cd to the new path. It is an existing worktree, not a
+	// fresh creation, so wt.nocd=create does not prevent the cd.
+	if inside {
+		requestCd(newPath)
+	}
+
+	// Move hooks run in the renamed worktree. The rename is not undone if
+	// one fails, and the shell still follows it.
+	env := git.HookEnv{
+		Event:     git.HookEventMove,
+		Path:      newPath,
+		Branch:    newName,
+		Name:      worktreeName(baseDir, newPath),
+		MainRoot:  mainRoot,
+		OldPath:   oldPath,
+		OldBranch: src.Branch,
+	}
+	if err := git.RunHooks(ctx, cfg.MoveHooks, newPath, env, cfg.HookTimeout, os.Stderr); err != nil {
+		return fmt.Errorf("move hook failed for worktree %q: %w", newName, err)
 	}
 	return nil
 }
 
 func handleWorktree(ctx context.Context, cmd *cobra.Command, wtName, branchName, startPoint string) error {
+	cfg, err := worktreeConfig(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	wt, err := ensureWorktree(ctx, cfg, wtName, branchName, startPoint, true, true)
+	if err != nil {
+		if wt.created {
+			// Hooks failed: print path but return error so shell integration won't cd
+			fmt.Println(resolveRelative(ctx, wt.path, cfg.Relative))
+		}
+		return err
+	}
+
+	// Hand the path to the shell integration (or print it to stdout)
+	return enterWorktree(ctx, cfg, wt)
+}
+
+// worktreeConfig loads the config with flag overrides for creating or
+// switching to a worktree.
+func worktreeConfig(ctx context.Context, cmd *cobra.Command) (git.Config, error) {
 	// Load config with flag overrides
 	cfg, err := loadConfig(ctx, cmd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return git.Config{}, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Check for legacy basedir migration (only if --basedir flag is not set)
 	if !cmd.Flags().Changed("basedir") {
 		newBaseDir, err := checkLegacyBaseDir(ctx, cfg.BaseDir)
 		if err != nil {
-			return fmt.Errorf("failed to check legacy basedir: %w", err)
+			return git.Config{}, fmt.Errorf("failed to check legacy basedir: %w", err)
 		}
 		if newBaseDir != "" {
 			cfg.BaseDir = newBaseDir
 		}
 	}
-
-	// Build copy options from config
-	copyOpts := git.CopyOptions{
-		CopyIgnored:   cfg.CopyIgnored,
-		CopyUntracked: cfg.CopyUntracked,
-		CopyModified:  cfg.CopyModified,
-		NoCopy:        cfg.NoCopy,
-		Copy:          cfg.Copy,
-		Symlink:       cfg.Symlink,
+	if !slices.Contains(git.HookModes(), cfg.HookMode) {
+		return git.Config{}, fmt.Errorf("unsupported hook mode %q (supported: %s)", cfg.HookMode, strings.Join(git.HookModes(), ", "))
 	}
+	return cfg, nil
+}
+
+// preparedWorktree is a worktree ensureWorktree found or created.
+type preparedWorktree struct {
+	path    string
+	name    string // branch, or directory name if detached
+	branch  string // empty if detached
+	created bool
+}
+
+// ensureWorktree returns the worktree of branchName (or directory wtName),
+// creating it, and the branch if needed, from startPoint. New worktrees get
+// the configured files copied and hooks run (background hooks are left
+// running only if background is set). With abbrev, a target that is neither a
+// worktree nor a branch is looked up as an abbreviation (see matchWorktrees):
+// with wt.abbrev, the single worktree it abbreviates is returned instead (and
+// several are an error); otherwise the new worktree is created as usual and
+// the matches are only pointed out. If a hook fails, the created worktree is
+// returned along with the error.
+func ensureWorktree(ctx context.Context, cfg git.Config, wtName, branchName, startPoint string, background, abbrev bool) (preparedWorktree, error) {
+	copyOpts := copyOptions(cfg)
 
 	// Check if worktree already exists for this branch or directory name
 	wt, err := git.FindWorktreeByBranchOrDir(ctx, branchName)
 	if err != nil {
-		return fmt.Errorf("failed to find worktree: %w", err)
+		return preparedWorktree{}, fmt.Errorf("failed to find worktree: %w", err)
 	}
 	if wt == nil && branchName != wtName {
 		// Also try finding by worktree directory name
 		wt, err = git.FindWorktreeByBranchOrDir(ctx, wtName)
 		if err != nil {
-			return fmt.Errorf("failed to find worktree: %w", err)
+			return preparedWorktree{}, fmt.Errorf("failed to find worktree: %w", err)
 		}
 	}
 
 	if wt != nil {
 		if startPoint != "" {
-			return fmt.Errorf("worktree for branch %q already exists at %s (start-point %q is not allowed when switching to an existing worktree)", wt.Branch, wt.Path, startPoint)
+			return preparedWorktree{}, fmt.Errorf("worktree for branch %q already exists at %s (start-point %q is not allowed when switching to an existing worktree)", wt.Branch, wt.Path, startPoint)
+		}
+		// Worktree exists
+		return existingWorktree(*wt), nil
+	}
+
+	// Check if branch exists
+	exists, err := git.BranchExists(ctx, branchName)
+	if err != nil {
+		return preparedWorktree{}, fmt.Errorf("failed to check branch: %w", err)
+	}
+
+	// Neither a worktree nor a branch: the target may abbreviate an existing
+	// worktree (e.g., "auth" for "feature/JIRA-123-auth-refresh"). With a
+	// start-point or -b, a new branch is asked for, so the target is taken
+	// literally.
+	if abbrev && !exists && startPoint == "" && branchName == wtName {
+		matches, err := matchWorktrees(ctx, cfg, branchName)
+		if err != nil {
+			return preparedWorktree{}, err
+		}
+		switch {
+		case len(matches) == 0:
+			// A new branch, as for any other name.
+		case !cfg.Abbrev:
+			fmt.Fprintf(os.Stderr, "hint: %q also matches existing worktrees (set wt.abbrev to switch to a single match):\n%s", branchName, formatMatches(matches, "hint:   "))
+		case len(matches) == 1:
+			fmt.Fprintf(os.Stderr, "Using %s (matched %q)\n", matches[0].name, branchName)
+			return existingWorktree(matches[0].wt), nil
+		default:
+			return preparedWorktree{}, fmt.Errorf("%q matches several worktrees:\n%suse a longer name, or give a start-point to create branch %q (e.g., 'git wt %s HEAD')", branchName, formatMatches(matches, "  "), branchName, branchName)
 		}
-		// Worktree exists, switch to it
-		fmt.Println(resolveRelative(ctx, wt.Path, cfg.Relative))
-		return nil
 	}
 
 	// Get worktree path using the worktree name (not the branch name)
 	wtPath, err := git.WorktreePathFor(ctx, cfg.BaseDir, wtName)
 	if err != nil {
-		return fmt.Errorf("failed to get worktree path: %w", err)
+		return preparedWorktree{}, fmt.Errorf("failed to get worktree path: %w", err)
 	}
 
-	// Check if branch exists
-	exists, err := git.BranchExists(ctx, branchName)
-	if err != nil {
-		return fmt.Errorf("failed to check branch: %w", err)
+	if exists && startPoint != "" {
+		return preparedWorktree{}, fmt.Errorf("branch %q already exists (start-point %q is not allowed for existing branches)", branchName, startPoint)
+	}
+
+	// Pre-create hooks may veto the new worktree
+	env := createHookEnv(ctx, cfg, wtPath, branchName, startPoint, !exists)
+	if err := runPreCreateHooks(ctx, cfg, env); err != nil {
+		return preparedWorktree{}, err
 	}
 
 	if exists {
-		if startPoint != "" {
-			return fmt.Errorf("branch %q already exists (start-point %q is not allowed for existing branches)", branchName, startPoint)
-		}
 		// Branch exists, create worktree with existing branch
 		if err := git.AddWorktree(ctx, wtPath, branchName, copyOpts); err != nil {
-			return fmt.Errorf("failed to create worktree: %w", err)
+			return preparedWorktree{}, fmt.Errorf("failed to create worktree: %w", err)
 		}
 	} else {
 		// Branch doesn't exist, create new branch and worktree
 		if err := git.AddWorktreeWithNewBranch(ctx, wtPath, branchName, startPoint, copyOpts); err != nil {
-			return fmt.Errorf("failed to create worktree with new branch: %w", err)
+			return preparedWorktree{}, fmt.Errorf("failed to create worktree with new branch: %w", err)
 		}
 	}
 
 	// Run hooks after creating new worktree
-	if err := git.RunHooks(ctx, cfg.Hooks, wtPath, os.Stderr); err != nil {
-		// Print path but return error so shell integration won't cd
-		fmt.Println(resolveRelative(ctx, wtPath, cfg.Relative))
-		return err
+	created := preparedWorktree{path: wtPath, name: branchName, branch: branchName, created: true}
+	if err := runCreateHooks(ctx, cfg, wtPath, env, background); err != nil {
+		return created, err
 	}
+	return created, nil
+}
 
-	// Print path to stdout
-	fmt.Println(resolveRelative(ctx, wtPath, cfg.Relative))
+// copyOptions builds the options for copying files to new worktrees from cfg.
+func copyOptions(cfg git.Config) git.CopyOptions {
+	return git.CopyOptions{
+		CopyIgnored:   cfg.CopyIgnored,
+		CopyUntracked: cfg.CopyUntracked,
+		CopyModified:  cfg.CopyModified,
+		NoCopy:        cfg.NoCopy,
+		Copy:          cfg.Copy,
+		Symlink:       cfg.Symlink,
+	}
+}
+
+// existingWorktree returns wt as a preparedWorktree, named by its branch (or
+// its directory name if detached).
+func existingWorktree(wt git.Worktree) preparedWorktree {
+	if wt.Branch == git.DetachedMarker {
+		return preparedWorktree{path: wt.Path, name: filepath.Base(wt.Path)}
+	}
+	return preparedWorktree{path: wt.Path, name: wt.Branch, branch: wt.Branch}
+}
+
+// switchToWorktree switches to the existing worktree wt (see enterWorktree).
+func switchToWorktree(ctx context.Context, cfg git.Config, wt git.Worktree) error {
+	return enterWorktree(ctx, cfg, existingWorktree(wt))
+}
+
+// enterWorktree finishes a switch to the worktree wt: switch hooks run if it
+// already existed, the switch is recorded in the history, the worktree shown
+// (see showWorktree) and, with --open, opened in the editor.
+func enterWorktree(ctx context.Context, cfg git.Config, wt preparedWorktree) error {
+	if !wt.created {
+		reportBackgroundHooks(ctx, wt.path)
+	}
+	// Switching is frequent: gather the hook environment only if needed
+	if !wt.created && len(cfg.SwitchHooks) > 0 {
+		env := newHookEnv(ctx, cfg, git.HookEventSwitch, wt.path, wt.branch)
+		if err := git.RunHooks(ctx, cfg.SwitchHooks, wt.path, env, cfg.HookTimeout, os.Stderr); err != nil {
+			// Print path but return error so shell integration won't cd
+			fmt.Println(resolveRelative(ctx, wt.path, cfg.Relative))
+			return err
+		}
+	}
+	recordSwitch(ctx, wt.path)
+	showWorktree(ctx, cfg, wt.path, wt.name, wt.created)
+	if openFlag {
+		return openEditor(ctx, cfg, resolveRelative(ctx, wt.path, cfg.Relative), wt.name)
+	}
 	return nil
 }
 
+// recordSwitch records a switch to the worktree at path in the switch
+// history. Failures only produce a warning, as the switch itself succeeded.
+func recordSwitch(ctx context.Context, path string) {
+	from, err := git.CurrentLocation(ctx)
+	if err != nil {
+		from = "" // the switch is still recorded, without where it came from
+	}
+	if err := git.RecordSwitch(ctx, from, path); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record switch history: %v\n", err)
+	}
+}
+
 func resolveRelative(ctx context.Context, wtPath string, relative bool) string {
 	if !relative {
 		return wtPath
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// staleWorktreeJSON is an entry of the --older-than --json report.
+type staleWorktreeJSON struct {
+	Path         string     `json:"path"`
+	Branch       string     `json:"branch"`
+	LastActivity time.Time  `json:"last_activity"`
+	LastCommit   *time.Time `json:"last_commit"`
+	LastModified *time.Time `json:"last_modified"`
+	LastSwitch   *time.Time `json:"last_switch"`
+}
+
+// staleWorktree is a worktree without activity for longer than the
+// --older-than threshold.
+type staleWorktree struct {
+	wt       git.Worktree
+	activity *git.WorktreeActivity
+}
+
+// staleWorktrees reports (or, with -d/-D, deletes) the worktrees whose last
+// activity is older than age. The main working tree is never reported.
+// Deletion goes through runCleanup, so locked and dirty worktrees are kept
+// unless -D is given, exactly as for --prune-merged.
+func staleWorktrees(ctx context.Context, cmd *cobra.Command, age string, remove, force bool) error {
+	d, err := parseAge(age)
+	if err != nil {
+		return err
+	}
+	stale, err := findStaleWorktrees(ctx, time.Now().Add(-d))
+	if err != nil {
+		return err
+	}
+
+	if remove {
+		mainRoot, err := git.MainRepoRoot(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get main repository root: %w", err)
+		}
+		candidates := make([]cleanupCandidate, 0, len(stale))
+		for _, s := range stale {
+			skip, err := skipReason(ctx, s.wt, mainRoot, force)
+			if err != nil {
+				return err
+			}
+			c := cleanupCandidate{target: s.wt.Path, path: s.wt.Path, note: "last activity " + formatAge(s.activity.Last()), skip: skip}
+			if s.wt.Branch != git.DetachedMarker {
+				c.branch = s.wt.Branch
+			}
+			candidates = append(candidates, c)
+		}
+		return runCleanup(ctx, cmd, fmt.Sprintf("Inactive for more than %s", age), "inactive worktree(s)", candidates, force)
+	}
+
+	if jsonFlag {
+		items := make([]staleWorktreeJSON, 0, len(stale))
+		for _, s := range stale {
+			items = append(items, staleWorktreeJSON{
+				Path:         s.wt.Path,
+				Branch:       s.wt.Branch,
+				LastActivity: s.activity.Last(),
+				LastCommit:   nonZeroTime(s.activity.LastCommit),
+				LastModified: nonZeroTime(s.activity.LastModified),
+				LastSwitch:   nonZeroTime(s.activity.LastSwitch),
+			})
+		}
+		return printJSON(os.Stdout, items)
+	}
+
+	table := newTable(os.Stdout, []string{"PATH", "BRANCH", "LAST ACTIVITY", "AGE"})
+	for _, s := range stale {
+		last := s.activity.Last()
+		if err := table.Append([]string{s.wt.Path, s.wt.Branch, last.Local().Format(time.DateTime), formatAge(last)}); err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
+
+// findStaleWorktrees returns the linked worktrees whose last activity is
+// before threshold, least recently active first.
+func findStaleWorktrees(ctx context.Context, threshold time.Time) ([]staleWorktree, error) {
+	worktrees, err := git.ListWorktrees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	mainRoot, err := git.MainRepoRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get main repository root: %w", err)
+	}
+
+	activities := git.WorktreeActivities(ctx, worktrees)
+	var stale []staleWorktree
+	for i, wt := range worktrees {
+		a := activities[i]
+		if a == nil || git.SamePath(wt.Path, mainRoot) {
+			continue
+		}
+		if a.Last().Before(threshold) {
+			stale = append(stale, staleWorktree{wt: wt, activity: a})
+		}
+	}
+	slices.SortStableFunc(stale, func(a, b staleWorktree) int {
+		return a.activity.Last().Compare(b.activity.Last())
+	})
+	return stale, nil
+}
+
+// formatAge renders how long ago t was, in days (e.g., "45 days ago").
+func formatAge(t time.Time) string {
+	days := int(time.Since(t).Hours() / 24)
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	}
+	return fmt.Sprintf("%d days ago", days)
+}
+
+func nonZeroTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
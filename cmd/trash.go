@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/git-wt/internal/git"
+)
+
+// restoreWorktree recreates a deleted worktree from the trash and prints its
+// path (for the shell integration to cd into).
+func restoreWorktree(ctx context.Context, query string) error {
+	entry, err := git.FindTrashEntry(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to read trash: %w", err)
+	}
+	if entry == nil {
+		return fmt.Errorf("no trash entry found for %q (see 'git wt --trash')", query)
+	}
+	if err := git.RestoreTrashEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to restore %q: %w", query, err)
+	}
+	if entry.Changes != "" {
+		fmt.Fprintf(os.Stderr, "Restored worktree %q with its uncommitted changes\n", entry.Name)
+	} else {
+		fmt.Fprintf(os.Stderr, "Restored worktree %q\n", entry.Name)
+	}
+	printWorktreePath(entry.Path, true)
+	return nil
+}
+
+// listTrash prints the trash entries, most recently deleted first.
+func listTrash(ctx context.Context) error {
+	entries, err := git.ListTrash(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read trash: %w", err)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	if jsonFlag {
+		if entries == nil {
+			entries = []git.TrashEntry{}
+		}
+		return printJSON(os.Stdout, entries)
+	}
+
+	table := newTable(os.Stdout, []string{"ID", "NAME", "BRANCH", "DELETED", "CHANGES"})
+	for _, e := range entries {
+		changes := ""
+		if e.Changes != "" {
+			changes = "yes"
+		}
+		if err := table.Append([]string{e.ID, e.Name, e.Branch, e.DeletedAt.Local().Format(time.DateTime), changes}); err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
+
+// expireTrash removes trash entries older than age (e.g., "30d").
+func expireTrash(ctx context.Context, age string) error {
+	d, err := parseAge(age)
+	if err != nil {
+		return err
+	}
+	expired, err := git.ExpireTrash(ctx, time.Now().Add(-d))
+	for _, e := range expired {
+		fmt.Fprintf(os.Stderr, "Expired trash entry %q (%s)\n", e.ID, e.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to expire trash: %w", err)
+	}
+	return nil
+}
+
+// autoExpireTrash drops the trash entries older than wt.trashexpire ("never"
+// keeps them), so that the trash does not keep objects alive forever.
+// Problems only produce a warning, as the deletion can go on.
+func autoExpireTrash(ctx context.Context, cfg git.Config) {
+	if cfg.TrashExpire == "never" {
+		return
+	}
+	d, err := parseAge(cfg.TrashExpire)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: invalid wt.trashexpire: %v\n", err)
+		return
+	}
+	if _, err := git.ExpireTrash(ctx, time.Now().Add(-d)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to expire trash: %v\n", err)
+	}
+}
+
+// parseAge parses an age such as "30d", "2w" or any time.ParseDuration
+// value (e.g., "12h"). Days and weeks are 24 and 168 hours.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid age %q: expected e.g. 30d, 2w or 12h", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: expected e.g. 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
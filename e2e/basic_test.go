@@ -175,6 +175,199 @@ func TestE2E_ListWorktrees(t *testing.T) {
 		}
 	})
 
+	t.Run("status", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "dirty")
+		if err != nil {
+			t.Fatalf("failed to create worktree dirty: %v", err)
+		}
+		dirtyPath := worktreePath(out)
+		if err := os.WriteFile(filepath.Join(dirtyPath, "README.md"), []byte("# Modified"), 0600); err != nil {
+			t.Fatalf("failed to modify file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dirtyPath, "untracked.txt"), []byte("new"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--json")
+		if err != nil {
+			t.Fatalf("git-wt --json failed: %v\nstderr: %s", err, stderr)
+		}
+		var items []struct {
+			Branch    string `json:"branch"`
+			Modified  int    `json:"modified"`
+			Untracked int    `json:"untracked"`
+			Ahead     int    `json:"ahead"`
+			Behind    int    `json:"behind"`
+			Upstream  string `json:"upstream"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+			t.Fatalf("failed to parse JSON output: %v\noutput: %s", err, stdout)
+		}
+		for _, item := range items {
+			switch item.Branch {
+			case "main":
+				if item.Modified != 0 || item.Untracked != 0 {
+					t.Errorf("main worktree should be clean, got %+v", item)
+				}
+			case "dirty":
+				if item.Modified != 1 || item.Untracked != 1 {
+					t.Errorf("dirty worktree should have 1 modified and 1 untracked file, got %+v", item)
+				}
+			}
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root)
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		for _, header := range []string{"STATUS", "UPSTREAM"} {
+			if !strings.Contains(out, header) {
+				t.Errorf("table should contain %q header, got: %s", header, out)
+			}
+		}
+		if !strings.Contains(out, "!1 ?1") {
+			t.Errorf("table should contain status %q for dirty worktree, got: %s", "!1 ?1", out)
+		}
+	})
+
+	t.Run("locked_and_prunable", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "locked-wt")
+		if err != nil {
+			t.Fatalf("failed to create worktree locked-wt: %v", err)
+		}
+		repo.Git("worktree", "lock", "--reason", "keep me", worktreePath(out))
+		out, err = runGitWt(t, binPath, repo.Root, "gone-wt")
+		if err != nil {
+			t.Fatalf("failed to create worktree gone-wt: %v", err)
+		}
+		if err := os.RemoveAll(worktreePath(out)); err != nil {
+			t.Fatalf("failed to remove worktree directory: %v", err)
+		}
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--json")
+		if err != nil {
+			t.Fatalf("git-wt --json failed: %v\nstderr: %s", err, stderr)
+		}
+		var items []struct {
+			Branch         string `json:"branch"`
+			Locked         bool   `json:"locked"`
+			LockReason     string `json:"lock_reason"`
+			Prunable       bool   `json:"prunable"`
+			PrunableReason string `json:"prunable_reason"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+			t.Fatalf("failed to parse JSON output: %v\noutput: %s", err, stdout)
+		}
+		for _, item := range items {
+			switch item.Branch {
+			case "locked-wt":
+				if !item.Locked || item.LockReason != "keep me" {
+					t.Errorf("locked-wt should be locked with reason, got %+v", item)
+				}
+			case "gone-wt":
+				if !item.Prunable || item.PrunableReason == "" {
+					t.Errorf("gone-wt should be prunable with reason, got %+v", item)
+				}
+			}
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root)
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		for _, s := range []string{"locked", "prunable"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("table should contain %q, got: %s", s, out)
+			}
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/format", "format-dir")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--format", `{{.Branch}}\t{{.Name}}\t{{.Path}}\t{{.Current}}`)
+		if err != nil {
+			t.Fatalf("git-wt --format failed: %v\nstderr: %s", err, stderr)
+		}
+		want := strings.Join([]string{
+			strings.Join([]string{"main", "", repo.Root, "true"}, "\t"),
+			strings.Join([]string{"feature/format", "format-dir", wtPath, "false"}, "\t"),
+		}, "\n")
+		if stdout != want {
+			t.Errorf("unexpected --format output:\ngot:  %q\nwant: %q", stdout, want)
+		}
+	})
+
+	t.Run("format_config", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.Git("config", "wt.listformat", "branch={{.Branch}}")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root)
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != "branch=main" {
+			t.Errorf("expected output %q from wt.listformat, got %q", "branch=main", stdout)
+		}
+
+		// --format overrides wt.listformat
+		stdout, stderr, err = runGitWtStdout(t, binPath, repo.Root, "--format", "{{.Head}}")
+		if err != nil {
+			t.Fatalf("git-wt --format failed: %v\nstderr: %s", err, stderr)
+		}
+		if strings.Contains(stdout, "branch=") || stdout == "" {
+			t.Errorf("--format should override wt.listformat, got %q", stdout)
+		}
+
+		// --json takes precedence over wt.listformat
+		stdout, stderr, err = runGitWtStdout(t, binPath, repo.Root, "--json")
+		if err != nil {
+			t.Fatalf("git-wt --json failed: %v\nstderr: %s", err, stderr)
+		}
+		if !json.Valid([]byte(stdout)) {
+			t.Errorf("--json should take precedence over wt.listformat, got %q", stdout)
+		}
+	})
+
+	t.Run("format_invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		for _, args := range [][]string{
+			{"--format", "{{.Branch"},
+			{"--format", "{{.NoSuchField}}"},
+			{"--format", "{{.Branch}}", "--json"},
+		} {
+			if out, err := runGitWt(t, binPath, repo.Root, args...); err == nil {
+				t.Errorf("git-wt %v should fail, got: %s", args, out)
+			}
+		}
+	})
+
 	// Regression test for PR #14 which fixed fish hook output formatting
 	t.Run("table_format_shell", func(t *testing.T) {
 		t.Parallel()
@@ -2,10 +2,11 @@
 //   - TestE2E_CopyOptions: copy options tests (copyignored config/flag, copyuntracked, copymodified, multiple flags, flag overrides)
 //   - TestE2E_Basedir: basedir tests (config, flag)
 //   - TestE2E_Nocd: nocd tests (config, config_with_init, create_config)
-//   - TestE2E_Hooks: hook tests (flag, config, multiple, not_run_on_existing, flag_overrides_config, failure, output_to_stderr)
-//   - TestE2E_DeleteHooks: delete hook tests (flag, config, multiple, not_run_on_branch_only, flag_overrides_config, failure_prevents_deletion, hook_runs_in_worktree_directory, output_to_stderr)
-//   - TestE2E_Remover: custom worktree remover tests (flag, config, flag_overrides_config, failure_prevents_deletion, prune_cleans_up)
+//   - TestE2E_Hooks: hook tests (flag, config, multiple, not_run_on_existing, flag_overrides_config, failure, output_to_stderr, environment)
+//   - TestE2E_DeleteHooks: delete hook tests (flag, config, multiple, not_run_on_branch_only, flag_overrides_config, failure_prevents_deletion, hook_runs_in_worktree_directory, output_to_stderr, environment)
+//   - TestE2E_Remover: custom worktree remover tests (flag, config, flag_overrides_config, failure_prevents_deletion, prune_cleans_up, environment)
 //   - TestE2E_Complete: __complete command output tests
+//   - TestE2E_Interactive: -i/--interactive and wt.interactive without a terminal
 package e2e
 
 import (
@@ -771,6 +772,45 @@ func TestE2E_Hooks(t *testing.T) {
 			t.Errorf("hook output should be in stderr, got stderr: %s", stderr)
 		}
 	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		base := strings.TrimSpace(repo.Git("rev-parse", "HEAD"))
+
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		out, err := runGitWt(t, binPath, repo.Root, "--hook", hookEnvScript(envFile), "-b", "feature/env", "env-test", base)
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "env-test")
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":       "create",
+			"GIT_WT_PATH":        wtPath,
+			"GIT_WT_BRANCH":      "feature/env",
+			"GIT_WT_NAME":        "env-test",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": repo.Root,
+			"GIT_WT_START_POINT": base,
+			"GIT_WT_NEW_BRANCH":  "1",
+		})
+
+		// An existing branch, created from a linked worktree
+		repo.Git("branch", "existing")
+		out, err = runGitWt(t, binPath, wtPath, "--hook", hookEnvScript(envFile), "existing")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_BRANCH":      "existing",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": wtPath,
+			"GIT_WT_START_POINT": "",
+			"GIT_WT_NEW_BRANCH":  "0",
+		})
+	})
 }
 
 func TestE2E_DeleteHooks(t *testing.T) {
@@ -988,6 +1028,36 @@ func TestE2E_DeleteHooks(t *testing.T) {
 			t.Errorf("hook output should be in stderr, got stderr: %s", stderr)
 		}
 	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/env", "env-test"); err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "env-test")
+
+		// Values inherited from the environment are not passed through.
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		_, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, []string{"GIT_WT_START_POINT=inherited"},
+			"-D", "--deletehook", hookEnvScript(envFile), "env-test")
+		if err != nil {
+			t.Fatalf("git-wt -D --deletehook failed: %v\nstderr: %s", err, stderr)
+		}
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":       "delete",
+			"GIT_WT_PATH":        wtPath,
+			"GIT_WT_BRANCH":      "feature/env",
+			"GIT_WT_NAME":        "env-test",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": "",
+			"GIT_WT_START_POINT": "",
+			"GIT_WT_NEW_BRANCH":  "",
+		})
+	})
 }
 
 func TestE2E_Relative(t *testing.T) {
@@ -1488,3 +1558,53 @@ func TestE2E_Complete(t *testing.T) {
 		}
 	})
 }
+
+func TestE2E_Interactive(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("flag_requires_terminal", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-i")
+		if err == nil {
+			t.Fatalf("-i should fail without a terminal, got: %s", out)
+		}
+		if !strings.Contains(out, "requires a terminal") {
+			t.Errorf("error should mention the terminal, got: %s", out)
+		}
+	})
+
+	t.Run("flag_rejects_arguments", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-i", "feature"); err == nil {
+			t.Errorf("-i with arguments should fail, got: %s", out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "-i", "-d"); err == nil {
+			t.Errorf("-i with -d should fail, got: %s", out)
+		}
+	})
+
+	t.Run("config_lists_without_terminal", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.Git("config", "wt.interactive", "true")
+
+		out, err := runGitWt(t, binPath, repo.Root)
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "PATH") || !strings.Contains(out, repo.Root) {
+			t.Errorf("wt.interactive should fall back to the list when not in a terminal, got: %s", out)
+		}
+	})
+}
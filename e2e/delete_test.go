@@ -1,5 +1,6 @@
 // delete_test.go contains worktree/branch deletion tests:
-//   - TestE2E_DeleteWorktree: worktree deletion (safe, force, unmerged, multiple)
+//   - TestE2E_DeleteWorktree: worktree deletion (safe, force, unmerged, squash-merged, multiple)
+//   - TestE2E_DeleteLockedAndPrunable: locked and prunable worktree deletion
 //   - TestE2E_DeleteBranch: branch-only deletion
 //   - TestE2E_DeleteCurrentWorktree: deleting worktree while inside it
 package e2e
@@ -275,6 +276,39 @@ func TestE2E_DeleteWorktree(t *testing.T) {
 		}
 	})
 
+	// A branch squash-merged into the default branch (e.g., by a forge) is
+	// not an ancestor of it, but safe delete should still delete it.
+	t.Run("squash_merged_branch", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "squashed")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v", err)
+		}
+		wtPath := worktreePath(out)
+		commitUnmergedChange(t, wtPath)
+
+		repo.CreateFile("main.txt", "main")
+		repo.Commit("commit on main")
+		repo.Git("merge", "--squash", "squashed")
+		repo.Commit("squash merge")
+
+		out, err = runGitWt(t, binPath, repo.Root, "-d", "squashed")
+		if err != nil {
+			t.Fatalf("git-wt -d failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, wtPath)
+		if strings.Contains(out, "failed to delete branch") {
+			t.Errorf("branch deletion should succeed since squashed is squash-merged, got: %s", out)
+		}
+		if branches := repo.Git("branch", "--list", "squashed"); branches != "" {
+			t.Errorf("squashed branch should have been deleted, got: %s", branches)
+		}
+	})
+
 	// PR #64 fix: shell integration works correctly when branch deletion fails
 	t.Run("with_unmerged_branch_shell_integration", func(t *testing.T) {
 		t.Parallel()
@@ -441,6 +475,88 @@ pwd
 			t.Error("stop-c should NOT have been deleted (execution should stop on error)")
 		}
 	})
+
+	t.Run("main_working_tree", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		marker := filepath.Join(repo.ParentDir(), "hook-ran")
+		repo.Git("config", "wt.deletehook", "touch "+marker)
+
+		out, err := runGitWt(t, binPath, repo.Root, "-D", "main")
+		if err == nil {
+			t.Fatalf("deleting the main working tree should fail, got: %s", out)
+		}
+		if !strings.Contains(out, "main working tree") {
+			t.Errorf("error should mention 'main working tree', got: %s", out)
+		}
+		if _, err := os.Stat(marker); !os.IsNotExist(err) {
+			t.Error("delete hook should not run for the main working tree")
+		}
+	})
+}
+
+func TestE2E_DeleteLockedAndPrunable(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("locked_reports_reason", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "locked-wt")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		repo.Git("worktree", "lock", "--reason", "on usb drive", wtPath)
+
+		for _, flag := range []string{"-d", "-D"} {
+			out, err = runGitWt(t, binPath, repo.Root, flag, "locked-wt")
+			if err == nil {
+				t.Fatalf("git-wt %s should fail for a locked worktree, got: %s", flag, out)
+			}
+			if !strings.Contains(out, "is locked") || !strings.Contains(out, "on usb drive") {
+				t.Errorf("error should mention the lock and its reason, got: %s", out)
+			}
+			assertWorktreeExists(t, wtPath)
+		}
+	})
+
+	t.Run("prunable_is_removed", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "gone-wt")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		if err := os.RemoveAll(wtPath); err != nil {
+			t.Fatalf("failed to remove worktree directory: %v", err)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "-d", "--deletehook", "echo should-not-run", "gone-wt")
+		if err != nil {
+			t.Fatalf("git-wt -d failed for prunable worktree: %v\noutput: %s", err, out)
+		}
+		if strings.Contains(out, "should-not-run") {
+			t.Errorf("delete hook should not run for a prunable worktree, got: %s", out)
+		}
+
+		list := repo.Git("worktree", "list")
+		if strings.Contains(list, wtPath) {
+			t.Errorf("prunable worktree should have been unregistered, got: %s", list)
+		}
+		if branches := repo.Git("branch", "--list", "gone-wt"); branches != "" {
+			t.Errorf("branch should have been deleted, got: %s", branches)
+		}
+	})
 }
 
 func TestE2E_DeleteBranch(t *testing.T) {
@@ -0,0 +1,156 @@
+// doctor_test.go contains basedir health check tests:
+//   - TestE2E_Doctor: --doctor report, --gc repair/prune/cleanup and -D for orphaned worktree directories
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Doctor(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// setup breaks worktrees the way rm -rf, mv or a failing wt.remover do:
+	// "missing" has no directory, "moved" was renamed by hand and "orphaned"
+	// lost its registration. An empty feat/ directory is also left behind.
+	setup := func(t *testing.T) (repo *testutil.TestRepo, paths map[string]string) {
+		t.Helper()
+		repo = testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		paths = map[string]string{}
+		for _, name := range []string{"missing", "moved", "orphaned", "feat/gone"} {
+			out, err := runGitWt(t, binPath, repo.Root, name)
+			if err != nil {
+				t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+			}
+			paths[name] = worktreePath(out)
+		}
+		if err := os.RemoveAll(paths["missing"]); err != nil {
+			t.Fatal(err)
+		}
+		paths["moved-by-hand"] = paths["moved"] + "-by-hand"
+		if err := os.Rename(paths["moved"], paths["moved-by-hand"]); err != nil {
+			t.Fatal(err)
+		}
+		repo.Git("worktree", "remove", "--force", paths["feat/gone"])
+		repo.Git("worktree", "remove", "--force", paths["orphaned"])
+		// Simulate a remover that only unregistered the worktree.
+		repo.Git("worktree", "add", paths["orphaned"], "orphaned")
+		if err := os.RemoveAll(filepath.Join(repo.Root, ".git", "worktrees", "orphaned")); err != nil {
+			t.Fatal(err)
+		}
+		return repo, paths
+	}
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+		repo, paths := setup(t)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--doctor")
+		if err != nil {
+			t.Fatalf("git-wt --doctor failed: %v\noutput: %s", err, out)
+		}
+		for _, want := range []string{
+			fmt.Sprintf("prune   registration of missing worktree %q", paths["missing"]),
+			fmt.Sprintf("repair  worktree %q", paths["moved-by-hand"]),
+			fmt.Sprintf("skip    orphaned worktree directory %q", paths["orphaned"]),
+			fmt.Sprintf("remove  empty directory %q", filepath.Dir(paths["feat/gone"])),
+			"git wt --gc",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("report should contain %q, got: %s", want, out)
+			}
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--doctor", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --doctor --json failed: %v\noutput: %s", err, out)
+		}
+		var problems []struct {
+			Kind string `json:"kind"`
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal([]byte(out), &problems); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(problems) != 4 {
+			t.Errorf("expected 4 problems, got %+v", problems)
+		}
+
+		// The dry run changes nothing.
+		if out, err := runGitWt(t, binPath, repo.Root, "--gc", "--dry-run"); err != nil || !strings.Contains(out, "Dry run") {
+			t.Fatalf("git-wt --gc --dry-run failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeExists(t, filepath.Dir(paths["feat/gone"]))
+		if list := repo.Git("worktree", "list"); !strings.Contains(list, paths["missing"]) {
+			t.Errorf("dry run should not prune registrations, got: %s", list)
+		}
+	})
+
+	t.Run("gc", func(t *testing.T) {
+		t.Parallel()
+		repo, paths := setup(t)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--gc")
+		if err != nil {
+			t.Fatalf("git-wt --gc failed: %v\noutput: %s", err, out)
+		}
+		list := repo.Git("worktree", "list", "--porcelain")
+		if strings.Contains(list, paths["missing"]+"\n") {
+			t.Errorf("registration of missing worktree should be pruned, got: %s", list)
+		}
+		if !strings.Contains(list, paths["moved-by-hand"]) {
+			t.Errorf("moved worktree should be repaired, got: %s", list)
+		}
+		if got := repo.Git("-C", paths["moved-by-hand"], "status", "--short"); got != "" {
+			t.Errorf("repaired worktree should be usable and clean, got: %q", got)
+		}
+		assertWorktreeDeleted(t, filepath.Dir(paths["feat/gone"]))
+		assertWorktreeExists(t, paths["orphaned"])
+
+		out, err = runGitWt(t, binPath, repo.Root, "--gc", "-D")
+		if err != nil {
+			t.Fatalf("git-wt --gc -D failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, paths["orphaned"])
+
+		out, err = runGitWt(t, binPath, repo.Root, "--doctor")
+		if err != nil {
+			t.Fatalf("git-wt --doctor failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "No problems found.") {
+			t.Errorf("nothing should be left to fix, got: %s", out)
+		}
+	})
+
+	t.Run("keeps_unknown_directories", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.CreateFile(".wt/notes/todo.txt", "keep me")
+		repo.Git("init", filepath.Join(repo.Root, ".wt", "other-repo"))
+
+		out, err := runGitWt(t, binPath, repo.Root, "--gc", "-D")
+		if err != nil {
+			t.Fatalf("git-wt --gc -D failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "not a worktree") || !strings.Contains(out, "Nothing to fix.") {
+			t.Errorf("unknown directory should be reported but kept, got: %s", out)
+		}
+		if strings.Contains(out, "other-repo") {
+			t.Errorf("repositories of their own should be ignored, got: %s", out)
+		}
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "notes"))
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "other-repo"))
+	})
+}
@@ -0,0 +1,199 @@
+// dryrun_test.go contains --dry-run tests:
+//   - TestE2E_DryRun: planned actions of delete and move without changing anything
+package e2e
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_DryRun(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	type action struct {
+		Action  string `json:"action"`
+		Command string `json:"command"`
+		Path    string `json:"path"`
+		NewPath string `json:"new_path"`
+		Branch  string `json:"branch"`
+		Force   bool   `json:"force"`
+		Reason  string `json:"reason"`
+	}
+	type change struct {
+		Target    string   `json:"target"`
+		Operation string   `json:"operation"`
+		Path      string   `json:"path"`
+		Branch    string   `json:"branch"`
+		Actions   []action `json:"actions"`
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		marker := filepath.Join(repo.Root, "hook-ran")
+		repo.Git("config", "wt.deletehook", "touch "+marker)
+
+		out, err := runGitWt(t, binPath, repo.Root, "feature")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+
+		out, err = runGitWt(t, binPath, repo.Root, "-d", "--dry-run", "feature")
+		if err != nil {
+			t.Fatalf("git-wt -d --dry-run failed: %v\noutput: %s", err, out)
+		}
+		for _, want := range []string{
+			`Would delete "feature"`,
+			`run hook "touch ` + marker + `"`,
+			"remove worktree " + wtPath,
+			`delete branch "feature"`,
+			"Dry run: no changes were made.",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output should contain %q, got: %s", want, out)
+			}
+		}
+
+		assertWorktreeExists(t, wtPath)
+		if _, err := os.Stat(marker); !os.IsNotExist(err) {
+			t.Error("delete hook should not run in dry-run mode")
+		}
+		if branches := repo.Git("branch", "--list", "feature"); branches == "" {
+			t.Error("branch should not be deleted in dry-run mode")
+		}
+	})
+
+	t.Run("delete_json", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		// Check out the default branch in a linked worktree.
+		repo.Git("checkout", "--detach")
+
+		out, err := runGitWt(t, binPath, repo.Root, "main")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+
+		out, err = runGitWt(t, binPath, repo.Root, "-D", "--dry-run", "--json", "main")
+		if err != nil {
+			t.Fatalf("git-wt -D --dry-run --json failed: %v\noutput: %s", err, out)
+		}
+		var changes []change
+		if err := json.Unmarshal([]byte(out), &changes); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("expected 1 planned change, got %d: %s", len(changes), out)
+		}
+		c := changes[0]
+		if c.Operation != "delete" || c.Branch != "main" {
+			t.Errorf("unexpected change: %+v", c)
+		}
+		var removed, kept bool
+		for _, a := range c.Actions {
+			switch a.Action {
+			case "remove_worktree":
+				removed = a.Path == wtPath && a.Force
+			case "keep_branch":
+				kept = a.Branch == "main" && a.Reason == "default branch"
+			case "delete_branch":
+				t.Errorf("default branch must not be planned for deletion: %+v", a)
+			}
+		}
+		if !removed || !kept {
+			t.Errorf("expected forced removal of %s and kept default branch, got: %+v", wtPath, c.Actions)
+		}
+		assertWorktreeExists(t, wtPath)
+	})
+
+	t.Run("delete_reports_errors", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "dirty")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		if err := os.WriteFile(filepath.Join(wtPath, "untracked.txt"), []byte("wip"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+
+		// The same checks as the real run apply.
+		out, err = runGitWt(t, binPath, repo.Root, "-d", "--dry-run", "dirty")
+		if err == nil {
+			t.Fatalf("dry run of a dirty worktree should fail like the real run, got: %s", out)
+		}
+		if !strings.Contains(out, "untracked files") {
+			t.Errorf("error should mention untracked files, got: %s", out)
+		}
+		assertWorktreeExists(t, wtPath)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "feat/old")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		oldPath := worktreePath(out)
+
+		out, err = runGitWt(t, binPath, repo.Root, "-m", "--dry-run", "--json", "feat/old", "new")
+		if err != nil {
+			t.Fatalf("git-wt -m --dry-run failed: %v\noutput: %s", err, out)
+		}
+		var changes []change
+		if err := json.Unmarshal([]byte(out), &changes); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("expected 1 planned change, got %d: %s", len(changes), out)
+		}
+		var got []string
+		for _, a := range changes[0].Actions {
+			got = append(got, a.Action)
+		}
+		want := []string{"move_worktree", "remove_empty_parents", "rename_branch"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("actions = %v, want %v", got, want)
+		}
+
+		assertWorktreeExists(t, oldPath)
+		if branches := repo.Git("branch", "--list", "new"); branches != "" {
+			t.Error("branch should not be renamed in dry-run mode")
+		}
+	})
+
+	t.Run("requires_operation", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "--dry-run", "feature")
+		if err == nil {
+			t.Fatalf("--dry-run without -d/-m should fail, got: %s", out)
+		}
+		if _, err := os.Stat(filepath.Join(repo.Root, ".wt", "feature")); !os.IsNotExist(err) {
+			t.Error("worktree should not be created")
+		}
+	})
+}
@@ -0,0 +1,177 @@
+// editor_test.go contains tests for opening worktrees in an editor:
+//   - TestE2E_Open: --open runs wt.editor (or $VISUAL/$EDITOR) with {path}/{branch} (also quoted) on created and existing worktrees
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Open(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// noEditor clears the editor environment variables of the test runner.
+	noEditor := []string{"VISUAL=", "EDITOR="}
+
+	// setup creates a repository and returns it along with a file the
+	// editor commands of the test write to.
+	setup := func(t *testing.T) (*testutil.TestRepo, string) {
+		t.Helper()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.CreateFile("some/path/file.txt", "content")
+		repo.Commit("initial commit")
+		return repo, filepath.Join(t.TempDir(), "opened")
+	}
+
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("editor was not run: %v", err)
+		}
+		return strings.TrimSpace(string(b))
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		repo.Git("config", "wt.editor", `printf '%s|%s|%s\n' {path} {branch} "$PWD" > `+opened)
+		wtPath := filepath.Join(repo.Root, ".wt", "feature-x")
+
+		stdout, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "feature-x")
+		if err != nil {
+			t.Fatalf("git-wt --open feature-x failed: %v\nstderr: %s", err, stderr)
+		}
+		assertWorktreeExists(t, wtPath)
+		if stdout != wtPath {
+			t.Errorf("stdout should still be the worktree path, got %q", stdout)
+		}
+		if got, want := read(t, opened), wtPath+"|feature-x|"+wtPath; got != want {
+			t.Errorf("editor should get the path and branch and run in the worktree, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("existing", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		if out, err := runGitWt(t, binPath, repo.Root, "feature-x"); err != nil {
+			t.Fatalf("git-wt feature-x failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("config", "wt.editor", "printf '%s|%s' {path} {branch} > "+opened)
+
+		if _, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "feature-x"); err != nil {
+			t.Fatalf("git-wt --open feature-x failed: %v\nstderr: %s", err, stderr)
+		}
+		if got, want := read(t, opened), filepath.Join(repo.Root, ".wt", "feature-x")+"|feature-x"; got != want {
+			t.Errorf("editor got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("quoted_placeholders", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		repo.Git("config", "wt.editor", `printf '%s|%s' '{path}' "{branch}" > `+opened)
+
+		if _, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "feature-x"); err != nil {
+			t.Fatalf("git-wt --open feature-x failed: %v\nstderr: %s", err, stderr)
+		}
+		if got, want := read(t, opened), filepath.Join(repo.Root, ".wt", "feature-x")+"|feature-x"; got != want {
+			t.Errorf("quoted placeholders should be replaced too, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("relative", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		repo.Git("config", "wt.relative", "true")
+		repo.Git("config", "wt.editor", "printf '%s' {path} > "+opened)
+
+		subdir := filepath.Join(repo.Root, "some", "path")
+		if _, stderr, err := runGitWtWithEnv(t, binPath, subdir, noEditor, "--open", "feature-x"); err != nil {
+			t.Fatalf("git-wt --open feature-x failed: %v\nstderr: %s", err, stderr)
+		}
+		if got, want := read(t, opened), filepath.Join(repo.Root, ".wt", "feature-x", "some", "path"); got != want {
+			t.Errorf("editor should open the resolved subdirectory, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("editor_flag", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		repo.Git("config", "wt.editor", "false")
+
+		if _, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "--editor", "echo {branch} {path} > "+opened, "feature-x"); err != nil {
+			t.Fatalf("git-wt --open --editor failed: %v\nstderr: %s", err, stderr)
+		}
+		if got, want := read(t, opened), "feature-x "+filepath.Join(repo.Root, ".wt", "feature-x"); got != want {
+			t.Errorf("--editor should override wt.editor, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("env_fallback", func(t *testing.T) {
+		t.Parallel()
+		repo, opened := setup(t)
+		script := filepath.Join(t.TempDir(), "editor")
+		if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s' \"$1\" > "+opened+"\n"), 0700); err != nil { //#nosec G306
+			t.Fatalf("failed to write editor script: %v", err)
+		}
+
+		// Without {path}, the path is appended to the command.
+		if _, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, []string{"VISUAL=", "EDITOR=" + script}, "--open", "feature-x"); err != nil {
+			t.Fatalf("git-wt --open feature-x failed: %v\nstderr: %s", err, stderr)
+		}
+		if got, want := read(t, opened), filepath.Join(repo.Root, ".wt", "feature-x"); got != want {
+			t.Errorf("$EDITOR should get the path, got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("no_editor", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := setup(t)
+
+		_, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "feature-x")
+		if err == nil {
+			t.Fatal("git-wt --open should fail without an editor")
+		}
+		if !strings.Contains(stderr, "set wt.editor") {
+			t.Errorf("stderr should tell how to configure an editor, got: %s", stderr)
+		}
+		// The worktree is still created.
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "feature-x"))
+	})
+
+	t.Run("editor_fails", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := setup(t)
+		repo.Git("config", "wt.editor", "exit 3")
+
+		_, stderr, err := runGitWtWithEnv(t, binPath, repo.Root, noEditor, "--open", "feature-x")
+		if err == nil {
+			t.Fatal("git-wt --open should fail when the editor fails")
+		}
+		if !strings.Contains(stderr, `editor "exit 3" failed`) {
+			t.Errorf("stderr should report the failed editor, got: %s", stderr)
+		}
+	})
+
+	t.Run("invalid_combinations", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := setup(t)
+
+		for _, args := range [][]string{
+			{"--open"},
+			{"--open", "-d", "feature-x"},
+		} {
+			out, err := runGitWt(t, binPath, repo.Root, args...)
+			if err == nil {
+				t.Errorf("git-wt %s should fail, got: %s", strings.Join(args, " "), out)
+			}
+		}
+	})
+}
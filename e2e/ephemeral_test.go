@@ -0,0 +1,202 @@
+// ephemeral_test.go contains tests for throwaway worktrees:
+//   - TestE2E_Ephemeral: --ephemeral diffs, --patch, start-points, exit codes, quiet teardown and teardown after failures
+package e2e
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/exec"
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Ephemeral(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// assertTornDown checks that no ephemeral worktree or branch is left.
+	assertTornDown := func(t *testing.T, repo *testutil.TestRepo) {
+		t.Helper()
+		if out := repo.Git("worktree", "list"); strings.Contains(out, "ephemeral-") {
+			t.Errorf("ephemeral worktree should be removed, got:\n%s", out)
+		}
+		if out := repo.Git("branch", "--list", "ephemeral-*"); strings.TrimSpace(out) != "" {
+			t.Errorf("ephemeral branch should be deleted, got: %s", out)
+		}
+	}
+
+	t.Run("diff", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "echo hooked > hooked.txt")
+
+		script := `echo changed >> README.md && git add README.md && git commit -qm "edit readme" && echo new > new.txt`
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", "--", script)
+		if err != nil {
+			t.Fatalf("git-wt --ephemeral failed: %v\nstderr: %s", err, stderr)
+		}
+		for _, s := range []string{"+changed", "+++ b/new.txt", "+++ b/hooked.txt"} {
+			if !strings.Contains(stdout, s) {
+				t.Errorf("diff should contain %q, got:\n%s", s, stdout)
+			}
+		}
+		if !strings.Contains(stderr, "edit readme") {
+			t.Errorf("stderr should list the commits, got: %s", stderr)
+		}
+		assertTornDown(t, repo)
+
+		// The printed diff applies to the start point (the output is trimmed).
+		patch := filepath.Join(t.TempDir(), "out.patch")
+		if err := os.WriteFile(patch, []byte(stdout+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		repo.Git("apply", "--check", patch)
+	})
+
+	t.Run("quiet_teardown", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", "--", "echo changed >> README.md && echo new > new.txt")
+		if err != nil {
+			t.Fatalf("git-wt --ephemeral failed: %v\nstderr: %s", err, stderr)
+		}
+		// stdout is exactly the patch: the teardown reports to stderr.
+		if !strings.HasPrefix(stdout, "diff --git a/README.md b/README.md\n") {
+			t.Errorf("stdout should start with the patch, got:\n%s", stdout)
+		}
+		if strings.Contains(stdout, "Deleted") {
+			t.Errorf("teardown messages should not be written to stdout, got:\n%s", stdout)
+		}
+		if !strings.Contains(stderr, `Deleted worktree and branch "ephemeral-`) {
+			t.Errorf("stderr should report the teardown, got: %s", stderr)
+		}
+		assertTornDown(t, repo)
+
+		// Nothing is kept in the trash.
+		trash, _, err := runGitWtStdout(t, binPath, repo.Root, "--trash", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --trash failed: %v", err)
+		}
+		if trash != "[]" {
+			t.Errorf("trash should stay empty, got: %s", trash)
+		}
+		if refs := strings.TrimSpace(repo.Git("for-each-ref", "refs/wt-trash")); refs != "" {
+			t.Errorf("no trash refs should be left, got: %s", refs)
+		}
+	})
+
+	t.Run("patch_file", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		patch := filepath.Join(t.TempDir(), "changes.patch")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", "--patch", patch, "--", "echo output; echo new > new.txt")
+		if err != nil {
+			t.Fatalf("git-wt --ephemeral --patch failed: %v\nstderr: %s", err, stderr)
+		}
+		if strings.Contains(stdout, "diff --git") {
+			t.Errorf("the diff should not be printed with --patch, got:\n%s", stdout)
+		}
+		if !strings.Contains(stdout, "output") {
+			t.Errorf("stdout should contain the command output, got:\n%s", stdout)
+		}
+		b, err := os.ReadFile(patch)
+		if err != nil {
+			t.Fatalf("patch file should be written: %v", err)
+		}
+		if !strings.HasPrefix(string(b), "diff --git a/new.txt b/new.txt") {
+			t.Errorf("unexpected patch:\n%s", b)
+		}
+		assertTornDown(t, repo)
+	})
+
+	t.Run("start_point", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		base := strings.TrimSpace(repo.Git("rev-parse", "HEAD"))
+		repo.CreateFile("second.txt", "second")
+		repo.Commit("second commit")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", base, "--", "test ! -e second.txt")
+		if err != nil {
+			t.Fatalf("git-wt --ephemeral with start-point failed: %v\nstderr: %s\nstdout: %s", err, stderr, stdout)
+		}
+		if !strings.Contains(stderr, "No changes") {
+			t.Errorf("stderr should report no changes, got: %s", stderr)
+		}
+		assertTornDown(t, repo)
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--ephemeral", "no-such-ref", "--", "true"); err == nil {
+			t.Errorf("an invalid start-point should fail, got: %s", out)
+		}
+	})
+
+	t.Run("command_fails", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		stdout, _, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", "--", "echo partial > partial.txt; exit 5")
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 5 {
+			t.Fatalf("git-wt --ephemeral should exit with the exit code of the command, got: %v", err)
+		}
+		if !strings.Contains(stdout, "+++ b/partial.txt") {
+			t.Errorf("the diff should be printed even if the command fails, got:\n%s", stdout)
+		}
+		assertTornDown(t, repo)
+	})
+
+	t.Run("hook_fails", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "exit 1")
+
+		stdout, _, err := runGitWtStdout(t, binPath, repo.Root, "--ephemeral", "--", "echo command-ran")
+		if err == nil {
+			t.Fatal("git-wt --ephemeral should fail when a hook fails")
+		}
+		if strings.Contains(stdout, "command-ran") {
+			t.Errorf("the command should not run when a hook fails, got: %s", stdout)
+		}
+		assertTornDown(t, repo)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test\n")
+		repo.Commit("initial commit")
+
+		for _, args := range [][]string{
+			{"--ephemeral"},
+			{"--ephemeral", "--"},
+			{"--ephemeral", "a", "b", "--", "true"},
+			{"--ephemeral", "-b", "x", "--", "true"},
+			{"--patch", "out.patch", "main"},
+		} {
+			if out, err := runGitWt(t, binPath, repo.Root, args...); err == nil {
+				t.Errorf("git-wt %s should fail, got: %s", strings.Join(args, " "), out)
+			}
+		}
+	})
+}
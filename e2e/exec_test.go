@@ -0,0 +1,224 @@
+// exec_test.go contains tests for running a command in a single worktree:
+//   - TestE2E_Exec: --exec creating worktrees, no abbreviation matching, exit code propagation, stdin, start-points and the shell integration
+package e2e
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/exec"
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Exec(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// exitCode returns the exit code of git wt from err.
+	exitCode := func(t *testing.T, err error) int {
+		t.Helper()
+		if err == nil {
+			return 0
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return exitErr.ExitCode()
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "touch hooked")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--exec", "feature/exec", "--", "pwd && ls")
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v\nstderr: %s", err, stderr)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "feature", "exec")
+		assertWorktreeExists(t, wtPath)
+		// Only the command writes to stdout.
+		if want := wtPath + "\nREADME.md\nhooked"; stdout != want {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+		if branch := strings.TrimSpace(repo.Git("-C", wtPath, "branch", "--show-current")); branch != "feature/exec" {
+			t.Errorf("branch = %q, want %q", branch, "feature/exec")
+		}
+	})
+
+	t.Run("existing", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "existing"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("config", "wt.hook", "touch hooked")
+
+		wtPath := filepath.Join(repo.Root, ".wt", "existing")
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--exec", wtPath, "--", "pwd")
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != wtPath {
+			t.Errorf("stdout = %q, want %q", stdout, wtPath)
+		}
+		if _, err := os.Stat(filepath.Join(wtPath, "hooked")); err == nil {
+			t.Error("hooks should not run for an existing worktree")
+		}
+	})
+
+	t.Run("no_abbreviation", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "feature/auth-refresh"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("config", "wt.abbrev", "true")
+
+		// "auth" would abbreviate feature/auth-refresh for git wt, but --exec
+		// creates the worktree it names.
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--exec", "auth", "--", "git branch --show-current")
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != "auth" {
+			t.Errorf("command should run on the new branch auth, got %q (stderr: %s)", stdout, stderr)
+		}
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "auth"))
+	})
+
+	t.Run("exit_code", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		_, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--exec", "failing", "--", "exit 7")
+		if got := exitCode(t, err); got != 7 {
+			t.Errorf("exit code = %d, want 7", got)
+		}
+		if strings.Contains(stderr, "Error:") {
+			t.Errorf("the exit code should be passed on without an error message, got: %s", stderr)
+		}
+
+		_, _, err = runGitWtStdout(t, binPath, repo.Root, "--exec", "failing", "--", "sh", "-c", "exit 3")
+		if got := exitCode(t, err); got != 3 {
+			t.Errorf("exit code = %d, want 3", got)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		cmd := exec.Command(binPath, "--exec", "reader", "--", "cat") //#nosec G204
+		cmd.Dir = repo.Root
+		cmd.Stdin = strings.NewReader("from stdin\n")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v", err)
+		}
+		if string(out) != "from stdin\n" {
+			t.Errorf("stdout = %q, want %q", out, "from stdin\n")
+		}
+	})
+
+	t.Run("start_point", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		base := strings.TrimSpace(repo.Git("rev-parse", "HEAD"))
+		repo.CreateFile("second.txt", "second")
+		repo.Commit("second commit")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--exec", "old", base, "--", "git", "rev-parse", "HEAD")
+		if err != nil {
+			t.Fatalf("git-wt --exec with start-point failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != base {
+			t.Errorf("HEAD = %q, want %q", stdout, base)
+		}
+	})
+
+	t.Run("hook_fails", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "exit 1")
+
+		stdout, _, err := runGitWtStdout(t, binPath, repo.Root, "--exec", "broken", "--", "echo ran")
+		if err == nil {
+			t.Fatal("git-wt --exec should fail when a hook fails")
+		}
+		if strings.Contains(stdout, "ran") {
+			t.Errorf("the command should not run when a hook fails, got: %s", stdout)
+		}
+	})
+
+	t.Run("shell_integration", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		directives := filepath.Join(t.TempDir(), "directives")
+		if err := os.WriteFile(directives, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Neither git wt --exec nor a git wt run by the command asks the
+		// shell to change directories.
+		_, stderr, err := runGitWtWithEnv(t, binPath, repo.Root,
+			[]string{"GIT_WT_SHELL_INTEGRATION=1", "GIT_WT_DIRECTIVE_FILE=" + directives},
+			"--exec", "scripted", "--", binPath, "nested")
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v\nstderr: %s", err, stderr)
+		}
+		b, err := os.ReadFile(directives)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "cd\t") {
+			t.Errorf("no cd directive should be written, got: %q", b)
+		}
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "nested"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		for _, args := range [][]string{
+			{"--exec", "target"},
+			{"--exec", "target", "--"},
+			{"--exec", "target", "a", "b", "--", "true"},
+			{"--exec", "target", "-d", "--", "true"},
+			{"--exec", "target", "--foreach", "--", "true"},
+		} {
+			if out, err := runGitWt(t, binPath, repo.Root, args...); err == nil {
+				t.Errorf("git-wt %s should fail, got: %s", strings.Join(args, " "), out)
+			}
+		}
+	})
+}
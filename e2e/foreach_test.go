@@ -0,0 +1,169 @@
+// foreach_test.go contains tests for running commands across worktrees:
+//   - TestE2E_Foreach: --foreach output prefixes, summary, exit codes, --filter, --parallel and --json
+package e2e
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Foreach(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// hasLine reports whether out has a line of name's output that reads line.
+	hasLine := func(out, name, line string) bool {
+		for l := range strings.Lines(out) {
+			prefix, rest, ok := strings.Cut(strings.TrimSuffix(l, "\n"), " | ")
+			if ok && strings.TrimSpace(prefix) == name && rest == line {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("all_worktrees", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/one", "two")
+		repoName := filepath.Base(repo.Root)
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--foreach", "--", "pwd")
+		if err != nil {
+			t.Fatalf("git-wt --foreach failed: %v\nstderr: %s", err, stderr)
+		}
+		for name, dir := range map[string]string{
+			repoName:      repo.Root,
+			"feature/one": filepath.Join(repo.Root, ".wt", "feature", "one"),
+			"two":         filepath.Join(repo.Root, ".wt", "two"),
+		} {
+			if !hasLine(stdout, name, dir) {
+				t.Errorf("stdout should contain %q prefixed with %q, got:\n%s", dir, name, stdout)
+			}
+		}
+		// Prefixes are padded to the longest name.
+		if !strings.Contains(stdout, "two         | ") {
+			t.Errorf("prefixes should be aligned, got:\n%s", stdout)
+		}
+		if i, j := strings.Index(stdout, "feature/one"), strings.Index(stdout, filepath.Join(".wt", "two")); i < 0 || j < 0 || i > j {
+			t.Errorf("worktrees should run in list order, got:\n%s", stdout)
+		}
+		for _, s := range []string{"WORKTREE", "RESULT", "ok"} {
+			if !strings.Contains(stdout, s) {
+				t.Errorf("summary should contain %q, got:\n%s", s, stdout)
+			}
+		}
+	})
+
+	t.Run("shell_command", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "one")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--foreach", "--", "echo out && echo err >&2 && printf tail")
+		if err != nil {
+			t.Fatalf("git-wt --foreach failed: %v\nstderr: %s", err, stderr)
+		}
+		if !hasLine(stdout, "one", "out") || !hasLine(stdout, "one", "tail") {
+			t.Errorf("stdout should contain prefixed lines, got:\n%s", stdout)
+		}
+		if !hasLine(stderr, "one", "err") {
+			t.Errorf("stderr should contain prefixed lines, got:\n%s", stderr)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "good", "bad")
+		if err := os.WriteFile(filepath.Join(repo.Root, ".wt", "bad", "fail"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--foreach", "--", "test ! -e fail || exit 3")
+		if err == nil {
+			t.Fatalf("git-wt --foreach should fail when the command fails somewhere, got:\n%s", stdout)
+		}
+		if !strings.Contains(stdout, "exit 3") {
+			t.Errorf("summary should show the exit code, got:\n%s", stdout)
+		}
+		if !strings.Contains(stderr, "command failed in 1 of 3 worktree(s)") {
+			t.Errorf("stderr should count the failures, got: %s", stderr)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/one", "feature/two", "other")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--foreach", "--filter", "feature/*", "--", "echo ran")
+		if err != nil {
+			t.Fatalf("git-wt --foreach --filter failed: %v\nstderr: %s", err, stderr)
+		}
+		if n := strings.Count(stdout, "| ran"); n != 2 {
+			t.Errorf("the command should run in 2 worktrees, ran in %d:\n%s", n, stdout)
+		}
+		if strings.Contains(stdout, "other") {
+			t.Errorf("other should be filtered out, got:\n%s", stdout)
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--foreach", "--filter", "nothing*", "--", "true"); err == nil {
+			t.Errorf("--filter matching nothing should fail, got: %s", out)
+		}
+	})
+
+	t.Run("parallel_json", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "one", "two", "three")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--foreach", "--parallel", "3", "--json", "--", "sh", "-c", "echo $(basename $PWD); test $(basename $PWD) != two")
+		if err == nil {
+			t.Fatal("git-wt --foreach should fail when the command fails in two")
+		}
+		var results []struct {
+			Name     string `json:"name"`
+			Path     string `json:"path"`
+			Branch   string `json:"branch"`
+			ExitCode int    `json:"exit_code"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+			t.Fatalf("stdout should be JSON: %v\n%s", err, stdout)
+		}
+		if len(results) != 4 {
+			t.Fatalf("expected 4 results, got %d: %s", len(results), stdout)
+		}
+		for _, r := range results {
+			want := 0
+			if r.Name == "two" {
+				want = 1
+			}
+			if r.ExitCode != want {
+				t.Errorf("%s: exit_code = %d, want %d", r.Name, r.ExitCode, want)
+			}
+		}
+		if !hasLine(stderr, "three", "three") {
+			t.Errorf("command output should go to stderr with --json, got:\n%s", stderr)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		for _, args := range [][]string{
+			{"--foreach"},
+			{"--foreach", "--parallel", "0", "--", "true"},
+			{"--foreach", "-d", "--", "true"},
+			{"--filter", "x", "main"},
+			{"--foreach", "--filter", "[", "--", "true"},
+		} {
+			if out, err := runGitWt(t, binPath, repo.Root, args...); err == nil {
+				t.Errorf("git-wt %s should fail, got: %s", strings.Join(args, " "), out)
+			}
+		}
+	})
+}
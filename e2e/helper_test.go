@@ -5,21 +5,27 @@
 //   - runGitWt: executes git-wt and returns combined output
 //   - runGitWtStdout: executes git-wt and returns stdout/stderr separately
 //   - runGitWtWithStderr: executes git-wt with isolated HOME and returns stdout/stderr separately
+//   - runGitWtWithEnv: executes git-wt with extra environment variables and returns stdout/stderr separately
 //   - runGitWtWithShellIntegration: executes git-wt with GIT_WT_SHELL_INTEGRATION=1
 //   - worktreePath: extracts worktree path from command output
 //   - addRawWorktreeFromBare: creates a worktree via raw git command
+//   - newRepoWithWorktrees: creates a repository with git-wt worktrees for branches
 //   - assertWorktreeExists: asserts that a worktree directory exists
 //   - assertWorktreeDeleted: asserts that a worktree directory has been removed
+//   - hookEnvScript: returns a hook command that records the GIT_WT_* variables
+//   - assertHookEnv: asserts the variables recorded by hookEnvScript
 package e2e
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/k1LoW/exec"
+	"github.com/k1LoW/git-wt/testutil"
 )
 
 func TestMain(m *testing.M) {
@@ -85,6 +91,20 @@ func runGitWtWithStderr(t *testing.T, binPath, dir string, args ...string) (stri
 	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), err
 }
 
+// runGitWtWithEnv runs git-wt with env added to the environment and returns stdout, stderr, and error separately.
+func runGitWtWithEnv(t *testing.T, binPath, dir string, env []string, args ...string) (string, string, error) {
+	t.Helper()
+
+	cmd := exec.Command(binPath, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), err
+}
+
 // worktreePath extracts the worktree path from git-wt output.
 // The path is the last line of output (after git messages).
 func worktreePath(output string) string {
@@ -107,6 +127,26 @@ func createBareWorktree(t *testing.T, binPath, dir, branch string) string {
 	return worktreePath(stdout)
 }
 
+// newRepoWithWorktrees creates a repository with one commit and a git-wt
+// worktree for each of branches, and returns it with the worktree paths by
+// branch.
+func newRepoWithWorktrees(t *testing.T, binPath string, branches ...string) (*testutil.TestRepo, map[string]string) {
+	t.Helper()
+
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+	paths := make(map[string]string)
+	for _, b := range branches {
+		out, err := runGitWt(t, binPath, repo.Root, b)
+		if err != nil {
+			t.Fatalf("git-wt %s failed: %v\noutput: %s", b, err, out)
+		}
+		paths[b] = worktreePath(out)
+	}
+	return repo, paths
+}
+
 // commitUnmergedChange creates a file and commits it in the given directory,
 // producing an unmerged commit relative to the parent branch.
 func commitUnmergedChange(t *testing.T, dir string) {
@@ -194,3 +234,33 @@ func assertLastLine(t *testing.T, output, expected string) {
 		t.Errorf("last line should be %q, got %q", expected, lastLine)
 	}
 }
+
+// hookEnvScript returns a hook command that writes the GIT_WT_* variables
+// (set or not) to envFile.
+func hookEnvScript(envFile string) string {
+	return fmt.Sprintf(`env | grep '^GIT_WT_' > %q; for v in EVENT PATH BRANCH NAME MAIN_ROOT SOURCE_PATH START_POINT NEW_BRANCH OLD_PATH OLD_BRANCH; do eval "test \"\${GIT_WT_$v+set}\" = set" || echo "GIT_WT_$v unset" >> %q; done`, envFile, envFile)
+}
+
+// assertHookEnv asserts the variables written by hookEnvScript to envFile.
+func assertHookEnv(t *testing.T, envFile string, want map[string]string) {
+	t.Helper()
+	b, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("hook did not write %s: %v", envFile, err)
+	}
+	got := map[string]string{}
+	for line := range strings.Lines(string(b)) {
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasSuffix(line, " unset") {
+			t.Errorf("%s", line)
+			continue
+		}
+		k, v, _ := strings.Cut(line, "=")
+		got[k] = v
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
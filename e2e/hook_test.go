@@ -0,0 +1,829 @@
+// hook_test.go contains tests for the lifecycle hooks besides wt.hook and wt.deletehook, and for how hooks run:
+//   - TestE2E_PreCreateHooks: pre-create hooks (flag, config, veto, environment, not_run_on_existing)
+//   - TestE2E_SwitchHooks: switch hooks (not_run_on_create, switch, failure)
+//   - TestE2E_MoveHooks: move hooks (environment, failure)
+//   - TestE2E_PostDeleteHooks: post-delete hooks (environment, flag_overrides_config, failure, failure_continues, not_run_on_branch_only)
+//   - TestE2E_HookMode: "&" hooks and wt.hookmode (background, background_mode, parallel, exec_waits, invalid)
+//   - TestE2E_HookPolicy: wt.hooktimeout and failure policies (timeout, timeout_config, delete_warn, retry, invalid)
+//   - TestE2E_HookLogs: --logs and --rerun-hooks (logs, rerun_hooks, deleted_worktree, json, errors)
+package e2e
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_PreCreateHooks(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("flag", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		marker := filepath.Join(t.TempDir(), "marker")
+
+		// The worktree does not exist yet when the hook runs.
+		out, err := runGitWt(t, binPath, repo.Root, "--precreatehook", `test ! -e "$GIT_WT_PATH" && pwd > `+marker, "pre-flag")
+		if err != nil {
+			t.Fatalf("git-wt --precreatehook failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "pre-flag"))
+		b, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("pre-create hook did not run: %v", err)
+		}
+		if got := strings.TrimSpace(string(b)); got != repo.Root {
+			t.Errorf("pre-create hook should run in the main working tree, ran in %q", got)
+		}
+	})
+
+	t.Run("config", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		marker := filepath.Join(t.TempDir(), "marker")
+		repo.Git("config", "wt.precreatehook", "touch "+marker)
+
+		if out, err := runGitWt(t, binPath, repo.Root, "pre-config"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		if _, err := os.Stat(marker); err != nil {
+			t.Errorf("pre-create hook from config did not run: %v", err)
+		}
+	})
+
+	t.Run("veto", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "touch hooked")
+
+		_, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--precreatehook", `echo "no $GIT_WT_BRANCH here"; exit 1`, "vetoed")
+		if err == nil {
+			t.Fatal("git-wt should fail when a pre-create hook fails")
+		}
+		if !strings.Contains(stderr, "no vetoed here") {
+			t.Errorf("hook output should be in stderr, got: %s", stderr)
+		}
+		if !strings.Contains(stderr, "pre-create hook rejected") {
+			t.Errorf("stderr should report the rejection, got: %s", stderr)
+		}
+		assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", "vetoed"))
+		if out := repo.Git("branch", "--list", "vetoed"); strings.TrimSpace(out) != "" {
+			t.Errorf("branch should not be created, got: %s", out)
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		base := strings.TrimSpace(repo.Git("rev-parse", "HEAD"))
+
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		out, err := runGitWt(t, binPath, repo.Root, "--precreatehook", hookEnvScript(envFile), "-b", "feature/pre", "pre-env", base)
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":       "precreate",
+			"GIT_WT_PATH":        filepath.Join(repo.Root, ".wt", "pre-env"),
+			"GIT_WT_BRANCH":      "feature/pre",
+			"GIT_WT_NAME":        "pre-env",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": repo.Root,
+			"GIT_WT_START_POINT": base,
+			"GIT_WT_NEW_BRANCH":  "1",
+			"GIT_WT_OLD_PATH":    "",
+		})
+	})
+
+	t.Run("not_run_on_existing", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "pre-existing"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--precreatehook", "exit 1", "pre-existing"); err != nil {
+			t.Errorf("pre-create hooks should not run for an existing worktree: %v\noutput: %s", err, out)
+		}
+	})
+}
+
+func TestE2E_SwitchHooks(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("not_run_on_create", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--switchhook", "exit 1", "switch-new"); err != nil {
+			t.Errorf("switch hooks should not run for a new worktree: %v\noutput: %s", err, out)
+		}
+	})
+
+	t.Run("switch", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/switch", "switch-existing"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "switch-existing")
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		repo.Git("config", "wt.switchhook", hookEnvScript(envFile))
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "switch-existing")
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != wtPath {
+			t.Errorf("stdout = %q, want %q", stdout, wtPath)
+		}
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":       "switch",
+			"GIT_WT_PATH":        wtPath,
+			"GIT_WT_BRANCH":      "feature/switch",
+			"GIT_WT_NAME":        "switch-existing",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": repo.Root,
+			"GIT_WT_NEW_BRANCH":  "",
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "switch-fail"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--switchhook", "exit 1", "switch-fail"); err == nil {
+			t.Errorf("git-wt should fail when a switch hook fails, got: %s", out)
+		}
+	})
+}
+
+func TestE2E_MoveHooks(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("environment", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "move-src"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		marker := filepath.Join(t.TempDir(), "marker")
+		repo.Git("config", "--add", "wt.movehook", hookEnvScript(envFile))
+		repo.Git("config", "--add", "wt.movehook", "pwd > "+marker)
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-m", "move-src", "move-dst"); err != nil {
+			t.Fatalf("git-wt -m failed: %v\noutput: %s", err, out)
+		}
+		newPath := filepath.Join(repo.Root, ".wt", "move-dst")
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":      "move",
+			"GIT_WT_PATH":       newPath,
+			"GIT_WT_BRANCH":     "move-dst",
+			"GIT_WT_NAME":       "move-dst",
+			"GIT_WT_MAIN_ROOT":  repo.Root,
+			"GIT_WT_OLD_PATH":   filepath.Join(repo.Root, ".wt", "move-src"),
+			"GIT_WT_OLD_BRANCH": "move-src",
+		})
+		b, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("move hook did not run: %v", err)
+		}
+		if got := strings.TrimSpace(string(b)); got != newPath {
+			t.Errorf("move hook should run in the renamed worktree, ran in %q", got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "move-src"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		out, err := runGitWt(t, binPath, repo.Root, "-m", "--movehook", "exit 1", "move-src", "move-failed")
+		if err == nil {
+			t.Fatalf("git-wt -m should fail when a move hook fails, got: %s", out)
+		}
+		// The rename is kept.
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "move-failed"))
+	})
+}
+
+func TestE2E_PostDeleteHooks(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("environment", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/post", "post-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		wtPath := filepath.Join(repo.Root, ".wt", "post-delete")
+		envFile := filepath.Join(t.TempDir(), "env.txt")
+		marker := filepath.Join(t.TempDir(), "marker")
+
+		// The worktree and its branch are gone when the hook runs.
+		out, err := runGitWt(t, binPath, repo.Root, "-D",
+			"--postdeletehook", hookEnvScript(envFile),
+			"--postdeletehook", `test ! -e "$GIT_WT_PATH" && ! git rev-parse --verify -q "refs/heads/$GIT_WT_BRANCH" && pwd > `+marker,
+			"post-delete")
+		if err != nil {
+			t.Fatalf("git-wt -D --postdeletehook failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, wtPath)
+		assertHookEnv(t, envFile, map[string]string{
+			"GIT_WT_EVENT":       "postdelete",
+			"GIT_WT_PATH":        wtPath,
+			"GIT_WT_BRANCH":      "feature/post",
+			"GIT_WT_NAME":        "post-delete",
+			"GIT_WT_MAIN_ROOT":   repo.Root,
+			"GIT_WT_SOURCE_PATH": "",
+		})
+		b, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("post-delete hook did not run: %v", err)
+		}
+		if got := strings.TrimSpace(string(b)); got != repo.Root {
+			t.Errorf("post-delete hook should run in the main working tree, ran in %q", got)
+		}
+	})
+
+	t.Run("flag_overrides_config", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/post", "post-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		dir := t.TempDir()
+		repo.Git("config", "wt.postdeletehook", "touch "+filepath.Join(dir, "config"))
+
+		out, err := runGitWt(t, binPath, repo.Root, "-D", "--postdeletehook", "touch "+filepath.Join(dir, "flag"), "post-delete")
+		if err != nil {
+			t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "flag")); err != nil {
+			t.Errorf("post-delete hook from the flag did not run: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "config")); err == nil {
+			t.Error("post-delete hook from config should be overridden by the flag")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/post", "post-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		out, err := runGitWt(t, binPath, repo.Root, "-D", "--postdeletehook", "exit 1", "post-delete")
+		if err == nil {
+			t.Fatalf("git-wt -D should fail when a post-delete hook fails, got: %s", out)
+		}
+		if !strings.Contains(out, "post-delete hook failed") {
+			t.Errorf("output should report the failure, got: %s", out)
+		}
+		// The deletion itself is done.
+		assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", "post-delete"))
+	})
+
+	t.Run("failure_continues", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/post", "post-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		for _, name := range []string{"pd-a", "pd-b"} {
+			if out, err := runGitWt(t, binPath, repo.Root, name); err != nil {
+				t.Fatalf("git-wt %s failed: %v\noutput: %s", name, err, out)
+			}
+		}
+		directiveFile := filepath.Join(t.TempDir(), "directives")
+		if err := os.WriteFile(directiveFile, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Run from inside pd-a, which gets deleted first.
+		_, stderr, err := runGitWtWithEnv(t, binPath, filepath.Join(repo.Root, ".wt", "pd-a"), []string{"GIT_WT_DIRECTIVE_FILE=" + directiveFile}, "-d", "--postdeletehook", "exit 1", "pd-a", "pd-b")
+		if err == nil {
+			t.Fatalf("git-wt -d should fail when a post-delete hook fails, got: %s", stderr)
+		}
+		for _, name := range []string{"pd-a", "pd-b"} {
+			if !strings.Contains(stderr, `post-delete hook failed for worktree "`+name+`"`) {
+				t.Errorf("output should report the failure for %s, got: %s", name, stderr)
+			}
+			assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", name))
+		}
+		b, err := os.ReadFile(directiveFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), repo.Root) {
+			t.Errorf("the shell should be sent to the main working tree, directives: %q", b)
+		}
+	})
+
+	t.Run("not_run_on_branch_only", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/post", "post-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		repo.Git("branch", "no-worktree")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "-D", "--postdeletehook", "exit 1", "no-worktree"); err != nil {
+			t.Errorf("post-delete hooks should not run when only a branch is deleted: %v\noutput: %s", err, out)
+		}
+	})
+}
+
+func TestE2E_HookMode(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// waitFor waits until the file at path exists.
+	waitFor := func(t *testing.T, path string) {
+		t.Helper()
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if _, err := os.Stat(path); err == nil {
+				return
+			}
+		}
+		t.Fatalf("%s was not created", path)
+	}
+
+	t.Run("background", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		release := filepath.Join(t.TempDir(), "release")
+		done := filepath.Join(t.TempDir(), "done")
+		repo.Git("config", "--add", "wt.hook", "touch foreground")
+		repo.Git("config", "--add", "wt.hook", "& echo bg-output; while ! test -e "+release+"; do sleep 0.05; done; touch "+done+"; exit 3")
+
+		// git wt does not wait for the background hook.
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "bg-hooks")
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\nstderr: %s", err, stderr)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "bg-hooks")
+		if stdout != wtPath {
+			t.Errorf("stdout = %q, want %q", stdout, wtPath)
+		}
+		if _, err := os.Stat(filepath.Join(wtPath, "foreground")); err != nil {
+			t.Errorf("foreground hook should have run: %v", err)
+		}
+		if !strings.Contains(stderr, "Running 1 hook(s) in the background") {
+			t.Errorf("stderr should mention the background hook, got: %s", stderr)
+		}
+		if strings.Contains(stderr, "bg-output") {
+			t.Errorf("background hook output should go to the log, got: %s", stderr)
+		}
+
+		// No failure is reported while the hook runs.
+		if _, stderr, _ := runGitWtStdout(t, binPath, repo.Root); strings.Contains(stderr, "background hook") {
+			t.Errorf("a running hook should not be reported, got: %s", stderr)
+		}
+
+		if err := os.WriteFile(release, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, done)
+
+		// The failure is reported once, on the next switch (or list).
+		var report string
+		for deadline := time.Now().Add(10 * time.Second); report == "" && time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			_, stderr, err := runGitWtStdout(t, binPath, repo.Root, "bg-hooks")
+			if err != nil {
+				t.Fatalf("git-wt failed: %v\nstderr: %s", err, stderr)
+			}
+			if strings.Contains(stderr, "background hook") {
+				report = stderr
+			}
+		}
+		if !strings.Contains(report, "failed with exit code 3") {
+			t.Fatalf("the failure should be reported, got: %q", report)
+		}
+		_, logFile, ok := strings.Cut(strings.TrimSpace(report), "(log: ")
+		if !ok {
+			t.Fatalf("the report should name the log, got: %s", report)
+		}
+		b, err := os.ReadFile(strings.TrimSuffix(logFile, ")"))
+		if err != nil {
+			t.Fatalf("failed to read the log: %v", err)
+		}
+		if !strings.Contains(string(b), "bg-output") {
+			t.Errorf("log should contain the hook output, got: %s", b)
+		}
+		if _, stderr, _ := runGitWtStdout(t, binPath, repo.Root); strings.Contains(stderr, "background hook") {
+			t.Errorf("the failure should be reported only once, got: %s", stderr)
+		}
+	})
+
+	t.Run("background_mode", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		done := filepath.Join(t.TempDir(), "done")
+		repo.Git("config", "wt.hookmode", "background")
+
+		out, err := runGitWt(t, binPath, repo.Root, "--hook", `pwd > `+done+`.tmp && mv `+done+`.tmp `+done, "bg-mode")
+		if err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		waitFor(t, done)
+		b, err := os.ReadFile(done)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := strings.TrimSpace(string(b)), filepath.Join(repo.Root, ".wt", "bg-mode"); got != want {
+			t.Errorf("background hook ran in %q, want %q", got, want)
+		}
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		// Each hook waits for the other, so they only succeed if run concurrently.
+		wait := func(mine, other string) string {
+			return "touch " + mine + "; for i in $(seq 100); do test -e " + other + " && exit 0; sleep 0.05; done; exit 1"
+		}
+
+		out, err := runGitWt(t, binPath, repo.Root, "--hookmode", "parallel", "--hook", wait("a", "b"), "--hook", wait("b", "a"), "parallel-hooks")
+		if err != nil {
+			t.Fatalf("git-wt --hookmode parallel failed: %v\noutput: %s", err, out)
+		}
+		for _, f := range []string{"a", "b"} {
+			if _, err := os.Stat(filepath.Join(repo.Root, ".wt", "parallel-hooks", f)); err != nil {
+				t.Errorf("hook should have created %s: %v", f, err)
+			}
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--hookmode", "parallel", "--hook", "exit 1", "--hook", "true", "parallel-fail"); err == nil {
+			t.Errorf("git-wt should fail when a parallel hook fails, got: %s", out)
+		}
+	})
+
+	t.Run("exec_waits", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		// The command of --exec needs the hooks done, so none run in the background.
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--hookmode", "background", "--hook", "&sleep 0.2; touch hooked", "--exec", "exec-bg", "--", "cat hooked && echo found")
+		if err != nil {
+			t.Fatalf("git-wt --exec failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != "found" {
+			t.Errorf("stdout = %q, want %q", stdout, "found")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--hookmode", "later", "invalid-mode"); err == nil {
+			t.Errorf("an unsupported hook mode should fail, got: %s", out)
+		}
+		assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", "invalid-mode"))
+	})
+}
+
+func TestE2E_HookPolicy(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		start := time.Now()
+		out, err := runGitWt(t, binPath, repo.Root, "--hooktimeout", "300ms", "--hook", "sleep 30", "hung-hook")
+		if err == nil {
+			t.Fatalf("git-wt should fail when a hook times out, got: %s", out)
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Errorf("git-wt took %s despite the timeout", elapsed)
+		}
+		if !strings.Contains(out, "timed out after 300ms") {
+			t.Errorf("output should report the timeout, got: %s", out)
+		}
+	})
+
+	t.Run("timeout_config", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "slow-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("config", "wt.hooktimeout", "1")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-D", "--deletehook", "sleep 30", "slow-delete")
+		if err == nil {
+			t.Fatalf("git-wt -D should fail when a delete hook times out, got: %s", out)
+		}
+		if !strings.Contains(out, "timed out after 1s") {
+			t.Errorf("output should report the timeout, got: %s", out)
+		}
+		// The worktree is kept, as for any failing delete hook.
+		assertWorktreeExists(t, filepath.Join(repo.Root, ".wt", "slow-delete"))
+	})
+
+	t.Run("delete_warn", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "warn-delete"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("config", "wt.deletehook", "[warn] git push no-such-remote --delete \"$GIT_WT_BRANCH\"")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-D", "warn-delete")
+		if err != nil {
+			t.Fatalf("git-wt -D should delete the worktree despite the failing hook: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "warning: hook \"git push no-such-remote") {
+			t.Errorf("output should warn about the hook, got: %s", out)
+		}
+		assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", "warn-delete"))
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		counter := filepath.Join(t.TempDir(), "count")
+
+		// Fails on the first attempt only.
+		hook := "[retry=2] echo x >> " + counter + "; test $(wc -l < " + counter + ") -ge 2"
+		out, err := runGitWt(t, binPath, repo.Root, "--hook", hook, "retried")
+		if err != nil {
+			t.Fatalf("git-wt should succeed after a retry: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "retrying (1/2)") {
+			t.Errorf("output should mention the retry, got: %s", out)
+		}
+		b, err := os.ReadFile(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(b), "x"); n != 2 {
+			t.Errorf("hook ran %d times, want 2", n)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		for _, args := range [][]string{
+			{"--hooktimeout", "soon", "invalid-timeout"},
+			{"--hook", "[sometimes] true", "invalid-policy"},
+		} {
+			if out, err := runGitWt(t, binPath, repo.Root, args...); err == nil {
+				t.Errorf("git-wt %s should fail, got: %s", strings.Join(args, " "), out)
+			}
+		}
+		assertWorktreeDeleted(t, filepath.Join(repo.Root, ".wt", "invalid-timeout"))
+	})
+}
+
+func TestE2E_HookLogs(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("logs", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "echo installing; echo broken >&2; exit 7")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "failing-hook"); err == nil {
+			t.Fatalf("git-wt should fail when the hook fails, got: %s", out)
+		}
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--logs", "failing-hook")
+		if err != nil {
+			t.Fatalf("git-wt --logs failed: %v\nstderr: %s", err, stderr)
+		}
+		for _, want := range []string{"[create] echo installing; echo broken >&2; exit 7 (exit code 7", "installing\nbroken"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("--logs output should contain %q, got: %s", want, stdout)
+			}
+		}
+	})
+
+	t.Run("rerun_hooks", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "test -e ../ready && touch hooked")
+
+		wtPath := filepath.Join(repo.Root, ".wt", "rerun")
+		if out, err := runGitWt(t, binPath, repo.Root, "rerun"); err == nil {
+			t.Fatalf("git-wt should fail when the hook fails, got: %s", out)
+		}
+		if err := os.WriteFile(filepath.Join(repo.Root, ".wt", "ready"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--rerun-hooks", "rerun"); err != nil {
+			t.Fatalf("git-wt --rerun-hooks failed: %v\noutput: %s", err, out)
+		}
+		if _, err := os.Stat(filepath.Join(wtPath, "hooked")); err != nil {
+			t.Errorf("hook should have run again in the worktree: %v", err)
+		}
+
+		stdout, _, err := runGitWtStdout(t, binPath, repo.Root, "--logs", "rerun")
+		if err != nil {
+			t.Fatalf("git-wt --logs failed: %v", err)
+		}
+		if !strings.Contains(stdout, "(exit code 1") || !strings.Contains(stdout, "(exit code 0") {
+			t.Errorf("--logs should show the failed run and the rerun, got: %s", stdout)
+		}
+	})
+
+	t.Run("deleted_worktree", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "gone"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "-D", "--deletehook", "echo cleaning up", "gone"); err != nil {
+			t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+		}
+
+		stdout, _, err := runGitWtStdout(t, binPath, repo.Root, "--logs", "gone")
+		if err != nil {
+			t.Fatalf("git-wt --logs should show the logs of a deleted worktree: %v", err)
+		}
+		if !strings.Contains(stdout, "[delete] echo cleaning up") || !strings.Contains(stdout, ")\ncleaning up") {
+			t.Errorf("--logs should show the delete hook run, got: %s", stdout)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.hook", "echo first")
+		repo.Git("config", "--add", "wt.hook", "&echo second")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "json-logs"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+		var runs []struct {
+			Event      string `json:"event"`
+			Hook       string `json:"hook"`
+			Path       string `json:"path"`
+			Background bool   `json:"background"`
+			ExitCode   int    `json:"exit_code"`
+			Log        string `json:"log"`
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--logs", "json-logs", "--json")
+			if err != nil {
+				t.Fatalf("git-wt --logs --json failed: %v\nstderr: %s", err, stderr)
+			}
+			if err := json.Unmarshal([]byte(stdout), &runs); err != nil {
+				t.Fatalf("failed to parse JSON: %v\noutput: %s", err, stdout)
+			}
+			// Both runs are done once the background hook has an end time.
+			if strings.Count(stdout, `"ended_at"`) == 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("background hook did not finish: %s", stdout)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if len(runs) != 2 {
+			t.Fatalf("expected 2 runs, got %+v", runs)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "json-logs")
+		for i, want := range []struct {
+			hook       string
+			background bool
+		}{{"echo first", false}, {"echo second", true}} {
+			r := runs[i]
+			if r.Event != "create" || r.Hook != want.hook || r.Background != want.background || r.ExitCode != 0 || r.Path != wtPath {
+				t.Errorf("run %d = %+v, want %q (background: %v)", i, r, want.hook, want.background)
+			}
+			if _, err := os.Stat(r.Log); err != nil {
+				t.Errorf("log of run %d should exist: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--logs", "never-created"); err == nil || !strings.Contains(out, "no hook runs recorded") {
+			t.Errorf("--logs should fail without recorded runs, got: %v\noutput: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--rerun-hooks", "never-created", "--hook", "true"); err == nil || !strings.Contains(out, "not found") {
+			t.Errorf("--rerun-hooks should fail for a missing worktree, got: %v\noutput: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--logs", "a", "b"); err == nil || !strings.Contains(out, "do not take arguments") {
+			t.Errorf("--logs should reject arguments, got: %v\noutput: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--logs", "a", "-d"); err == nil || !strings.Contains(out, "cannot combine") {
+			t.Errorf("--logs should not combine with -d, got: %v\noutput: %s", err, out)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+// match_test.go contains tests for abbreviated worktree targets:
+//   - TestE2E_FuzzyMatch: new branches by default (with a hint), and with wt.abbrev: word-prefix matching, frecency-ranked candidates, ambiguity, exact-match priority and targets that only look similar
+package e2e
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_FuzzyMatch(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// Without wt.abbrev, creation is unchanged: the matches are only pointed
+	// out.
+	t.Run("creates_by_default", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature-x", "feature-y", "feature/JIRA-123-auth-refresh")
+
+		for _, tt := range []struct {
+			name    string
+			matches []string
+		}{
+			{"feat", []string{"feature-x", "feature-y"}},
+			{"auth", []string{"feature/JIRA-123-auth-refresh"}},
+		} {
+			stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, tt.name)
+			if err != nil {
+				t.Fatalf("git-wt %s failed: %v\nstderr: %s", tt.name, err, stderr)
+			}
+			if want := filepath.Join(repo.Root, ".wt", tt.name); stdout != want {
+				t.Errorf("git wt %s should create %q, got %q", tt.name, want, stdout)
+			}
+			if got := strings.TrimSpace(repo.Git("branch", "--list", tt.name)); got == "" {
+				t.Errorf("branch %s should be created", tt.name)
+			}
+			for _, m := range append([]string{"hint:", "wt.abbrev"}, tt.matches...) {
+				if !strings.Contains(stderr, m) {
+					t.Errorf("stderr should contain %q, got: %s", m, stderr)
+				}
+			}
+		}
+	})
+
+	t.Run("substring", func(t *testing.T) {
+		t.Parallel()
+		repo, paths := newRepoWithWorktrees(t, binPath, "feature/JIRA-123-auth-refresh", "feature/billing")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "--abbrev", "auth")
+		if err != nil {
+			t.Fatalf("git-wt auth failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != paths["feature/JIRA-123-auth-refresh"] {
+			t.Errorf("git wt auth should switch to %q, got %q", paths["feature/JIRA-123-auth-refresh"], stdout)
+		}
+		if !strings.Contains(stderr, `matched "auth"`) {
+			t.Errorf("stderr should tell which worktree was matched, got: %s", stderr)
+		}
+		if out := strings.TrimSpace(repo.Git("branch", "--list", "auth")); out != "" {
+			t.Errorf("no branch should be created for a matched target, got: %s", out)
+		}
+	})
+
+	t.Run("word_start", func(t *testing.T) {
+		t.Parallel()
+		repo, paths := newRepoWithWorktrees(t, binPath, "feature/JIRA-123-auth-refresh", "feature/billing")
+		repo.Git("config", "wt.abbrev", "true")
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, repo.Root, "123")
+		if err != nil {
+			t.Fatalf("git-wt 123 failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != paths["feature/JIRA-123-auth-refresh"] {
+			t.Errorf("git wt 123 should switch to %q, got %q", paths["feature/JIRA-123-auth-refresh"], stdout)
+		}
+	})
+
+	// Targets whose letters merely appear in an existing name, in order or in
+	// the middle of a word, are new branches.
+	t.Run("lookalike_creates", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/first-item")
+		repo.Git("config", "wt.abbrev", "true")
+
+		for _, name := range []string{"fit", "tem", "ffi"} {
+			out, err := runGitWt(t, binPath, repo.Root, name)
+			if err != nil {
+				t.Fatalf("git-wt %s failed: %v\noutput: %s", name, err, out)
+			}
+			if strings.Contains(out, "matched") || strings.Contains(out, "hint:") {
+				t.Errorf("git wt %s should not match feature/first-item, got: %s", name, out)
+			}
+			assertLastLine(t, out, filepath.Join(repo.Root, ".wt", name))
+			assertWorktreeExists(t, worktreePath(out))
+			if got := strings.TrimSpace(repo.Git("branch", "--list", name)); got == "" {
+				t.Errorf("branch %s should be created", name)
+			}
+		}
+	})
+
+	t.Run("main_not_matched", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.Git("config", "wt.abbrev", "true")
+
+		out, err := runGitWt(t, binPath, repo.Root, "ma")
+		if err != nil {
+			t.Fatalf("git-wt ma failed: %v\noutput: %s", err, out)
+		}
+		assertLastLine(t, out, filepath.Join(repo.Root, ".wt", "ma"))
+		assertWorktreeExists(t, worktreePath(out))
+	})
+
+	// Several matches are never resolved, however frecent one of them is:
+	// the candidates are listed, the most frecent first.
+	t.Run("ambiguous", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/auth-old", "feature/auth-refresh")
+		repo.Git("config", "wt.abbrev", "true")
+		for range 3 {
+			if out, err := runGitWt(t, binPath, repo.Root, "feature/auth-refresh"); err != nil {
+				t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+			}
+		}
+
+		out, err := runGitWt(t, binPath, repo.Root, "auth")
+		if err == nil {
+			t.Fatalf("git wt auth should fail for an ambiguous target, got: %s", out)
+		}
+		for _, s := range []string{"matches several worktrees", "feature/auth-refresh", "feature/auth-old"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("output should contain %q, got: %s", s, out)
+			}
+		}
+		if strings.Index(out, "feature/auth-refresh") > strings.Index(out, "feature/auth-old") {
+			t.Errorf("the most frecent worktree should be listed first, got: %s", out)
+		}
+		if out := strings.TrimSpace(repo.Git("branch", "--list", "auth")); out != "" {
+			t.Errorf("no branch should be created for an ambiguous target, got: %s", out)
+		}
+	})
+
+	t.Run("exact_branch_first", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/auth-refresh")
+		repo.Git("config", "wt.abbrev", "true")
+		repo.Git("branch", "auth")
+
+		out, err := runGitWt(t, binPath, repo.Root, "auth")
+		if err != nil {
+			t.Fatalf("git-wt auth failed: %v\noutput: %s", err, out)
+		}
+		assertLastLine(t, out, filepath.Join(repo.Root, ".wt", "auth"))
+		assertWorktreeExists(t, worktreePath(out))
+	})
+
+	t.Run("start_point_creates", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/auth-refresh")
+		repo.Git("config", "wt.abbrev", "true")
+
+		out, err := runGitWt(t, binPath, repo.Root, "auth", "HEAD")
+		if err != nil {
+			t.Fatalf("git-wt auth HEAD failed: %v\noutput: %s", err, out)
+		}
+		assertLastLine(t, out, filepath.Join(repo.Root, ".wt", "auth"))
+		assertWorktreeExists(t, worktreePath(out))
+	})
+
+	t.Run("no_match_creates", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/auth-refresh")
+		repo.Git("config", "wt.abbrev", "true")
+
+		out, err := runGitWt(t, binPath, repo.Root, "payments")
+		if err != nil {
+			t.Fatalf("git-wt payments failed: %v\noutput: %s", err, out)
+		}
+		assertLastLine(t, out, filepath.Join(repo.Root, ".wt", "payments"))
+		assertWorktreeExists(t, worktreePath(out))
+	})
+}
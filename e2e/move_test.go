@@ -105,6 +105,35 @@ func TestE2E_MoveWorktree(t *testing.T) {
 		}
 	})
 
+	t.Run("locked_blocks_safe", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "locked-src")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		oldPath := worktreePath(out)
+		repo.Git("worktree", "lock", "--reason", "in use by CI", oldPath)
+
+		out, err = runGitWt(t, binPath, repo.Root, "-m", "locked-src", "locked-dst")
+		if err == nil {
+			t.Fatalf("git-wt -m should fail for a locked worktree, got: %s", out)
+		}
+		if !strings.Contains(out, "is locked") || !strings.Contains(out, "in use by CI") {
+			t.Errorf("error should mention the lock and its reason, got: %s", out)
+		}
+		assertWorktreeExists(t, oldPath)
+
+		out, err = runGitWt(t, binPath, repo.Root, "-M", "locked-src", "locked-dst")
+		if err != nil {
+			t.Fatalf("git-wt -M should move a locked worktree: %v\noutput: %s", err, out)
+		}
+		assertWorktreeExists(t, filepath.Join(filepath.Dir(oldPath), "locked-dst"))
+	})
+
 	t.Run("target_dir_exists_blocks_even_force", func(t *testing.T) {
 		t.Parallel()
 		repo := testutil.NewTestRepo(t)
@@ -0,0 +1,337 @@
+// multiplexer_test.go contains tests for opening worktrees in terminal multiplexers:
+//   - TestE2E_Multiplexer: tmux windows and zellij tabs are opened, focused and closed, but not tabs git wt did not open (using fake tmux/zellij commands)
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+// fakeTmux keeps windows as "<id>\t<@git-wt-path>" lines in $FAKE_STATE and
+// logs every invocation to $FAKE_LOG.
+const fakeTmux = `#!/bin/sh
+echo "$*" >> "$FAKE_LOG"
+touch "$FAKE_STATE"
+case "$1" in
+list-windows)
+  cat "$FAKE_STATE" ;;
+new-window)
+  id="@$(($(wc -l < "$FAKE_STATE") + 1))"
+  printf '%s\t\n' "$id" >> "$FAKE_STATE"
+  echo "$id" ;;
+set-option)
+  awk -F '\t' -v id="$4" -v p="$6" 'BEGIN { OFS = "\t" } $1 == id { $2 = p } { print }' "$FAKE_STATE" > "$FAKE_STATE.tmp"
+  mv "$FAKE_STATE.tmp" "$FAKE_STATE" ;;
+kill-window)
+  grep -v "^$3	" "$FAKE_STATE" > "$FAKE_STATE.tmp"
+  mv "$FAKE_STATE.tmp" "$FAKE_STATE" ;;
+esac
+`
+
+// fakeZellij keeps tab names as lines in $FAKE_STATE and the focused tab in
+// $FAKE_STATE.focus, and logs every invocation to $FAKE_LOG.
+const fakeZellij = `#!/bin/sh
+echo "$*" >> "$FAKE_LOG"
+touch "$FAKE_STATE"
+case "$2" in
+query-tab-names)
+  cat "$FAKE_STATE" ;;
+new-tab)
+  echo "$4" >> "$FAKE_STATE"
+  echo "$4" > "$FAKE_STATE.focus" ;;
+go-to-tab-name)
+  echo "$3" > "$FAKE_STATE.focus" ;;
+close-tab)
+  grep -vx "$(cat "$FAKE_STATE.focus")" "$FAKE_STATE" > "$FAKE_STATE.tmp"
+  mv "$FAKE_STATE.tmp" "$FAKE_STATE" ;;
+esac
+`
+
+func TestE2E_Multiplexer(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// fake installs a fake multiplexer command and returns the environment
+	// to run git-wt with, along with the paths of its log and state files.
+	fake := func(t *testing.T, name, script string, env ...string) ([]string, string, string) {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0700); err != nil { //#nosec G306
+			t.Fatalf("failed to write fake %s: %v", name, err)
+		}
+		log := filepath.Join(dir, "log")
+		state := filepath.Join(dir, "state")
+		env = append(env,
+			"PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"),
+			"FAKE_LOG="+log,
+			"FAKE_STATE="+state,
+		)
+		return env, log, state
+	}
+
+	run := func(t *testing.T, dir string, env []string, args ...string) (string, string, error) {
+		t.Helper()
+		return runGitWtWithEnv(t, binPath, dir, env, args...)
+	}
+
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		return string(b)
+	}
+
+	t.Run("tmux_open_and_focus", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "tmux")
+		env, log, state := fake(t, "tmux", fakeTmux, "TMUX=/tmp/fake,1,0")
+		wtPath := filepath.Join(repo.Root, ".wt", "feature")
+
+		stdout, stderr, err := run(t, repo.Root, env, "feature")
+		if err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		assertWorktreeExists(t, wtPath)
+		if stdout != wtPath {
+			t.Errorf("stdout should be the worktree path, got %q", stdout)
+		}
+		if !strings.Contains(stderr, `Opened tmux window "feature"`) {
+			t.Errorf("stderr should report the new window, got: %s", stderr)
+		}
+		if !strings.Contains(read(t, log), "new-window -P -F #{window_id} -n feature -c "+wtPath) {
+			t.Errorf("a window should be created in the worktree, log:\n%s", read(t, log))
+		}
+		if got := read(t, state); got != "@1\t"+wtPath+"\n" {
+			t.Errorf("the window should be tagged with the worktree path, got %q", got)
+		}
+
+		// Switching again focuses the existing window.
+		_, stderr, err = run(t, repo.Root, env, "feature")
+		if err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if !strings.Contains(stderr, `Switched to tmux window "feature"`) {
+			t.Errorf("stderr should report the focused window, got: %s", stderr)
+		}
+		if !strings.Contains(read(t, log), "select-window -t @1") {
+			t.Errorf("the existing window should be selected, log:\n%s", read(t, log))
+		}
+		if n := strings.Count(read(t, log), "new-window"); n != 1 {
+			t.Errorf("no second window should be created, got %d new-window calls", n)
+		}
+	})
+
+	t.Run("tmux_delete_closes_window", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		env, _, state := fake(t, "tmux", fakeTmux, "TMUX=/tmp/fake,1,0")
+		wtPath := filepath.Join(repo.Root, ".wt", "feature")
+
+		if _, stderr, err := run(t, repo.Root, env, "--tmux", "feature"); err != nil {
+			t.Fatalf("git-wt --tmux feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if got := read(t, state); !strings.Contains(got, wtPath) {
+			t.Fatalf("--tmux should open a window, state: %q", got)
+		}
+
+		_, stderr, err := run(t, repo.Root, env, "--tmux", "-d", "feature")
+		if err != nil {
+			t.Fatalf("git-wt -d feature failed: %v\nstderr: %s", err, stderr)
+		}
+		assertWorktreeDeleted(t, wtPath)
+		if got := read(t, state); got != "" {
+			t.Errorf("the window of the deleted worktree should be closed, state: %q", got)
+		}
+		if !strings.Contains(stderr, `Closed tmux window "feature"`) {
+			t.Errorf("stderr should report the closed window, got: %s", stderr)
+		}
+	})
+
+	t.Run("shell_integration_does_not_cd", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "tmux")
+		directives := filepath.Join(t.TempDir(), "directives")
+		env, _, _ := fake(t, "tmux", fakeTmux, "TMUX=/tmp/fake,1,0",
+			"GIT_WT_SHELL_INTEGRATION=1", "GIT_WT_DIRECTIVE_FILE="+directives)
+
+		stdout, stderr, err := run(t, repo.Root, env, "feature")
+		if err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != "" {
+			t.Errorf("stdout should be empty under the shell integration, got %q", stdout)
+		}
+		if got := read(t, directives); strings.Contains(got, "cd\t") {
+			t.Errorf("the current shell should not cd, directives: %q", got)
+		}
+	})
+
+	t.Run("outside_session", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "tmux")
+		env, log, _ := fake(t, "tmux", fakeTmux, "TMUX=")
+		wtPath := filepath.Join(repo.Root, ".wt", "feature")
+
+		stdout, stderr, err := run(t, repo.Root, env, "feature")
+		if err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != wtPath {
+			t.Errorf("stdout should be the worktree path, got %q", stdout)
+		}
+		if strings.Contains(stderr, "warning") {
+			t.Errorf("the config should be ignored silently outside tmux, got: %s", stderr)
+		}
+		if got := read(t, log); got != "" {
+			t.Errorf("tmux should not be run outside a session, log:\n%s", got)
+		}
+
+		// Asking for it explicitly warns.
+		_, stderr, err = run(t, repo.Root, env, "--tmux", "feature")
+		if err != nil {
+			t.Fatalf("git-wt --tmux feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if !strings.Contains(stderr, "not running inside tmux") {
+			t.Errorf("--tmux outside tmux should warn, got: %s", stderr)
+		}
+	})
+
+	t.Run("none_overrides_config", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "tmux")
+		env, log, _ := fake(t, "tmux", fakeTmux, "TMUX=/tmp/fake,1,0")
+
+		if _, stderr, err := run(t, repo.Root, env, "--multiplexer", "none", "feature"); err != nil {
+			t.Fatalf("git-wt --multiplexer none feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if got := read(t, log); got != "" {
+			t.Errorf("--multiplexer none should not run tmux, log:\n%s", got)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		wtPath := filepath.Join(repo.Root, ".wt", "feature")
+
+		stdout, stderr, err := run(t, repo.Root, nil, "--multiplexer", "screen", "feature")
+		if err != nil {
+			t.Fatalf("git-wt --multiplexer screen feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != wtPath {
+			t.Errorf("stdout should be the worktree path, got %q", stdout)
+		}
+		if !strings.Contains(stderr, `unsupported multiplexer "screen"`) {
+			t.Errorf("stderr should report the unsupported multiplexer, got: %s", stderr)
+		}
+	})
+
+	t.Run("zellij", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "zellij")
+		env, log, state := fake(t, "zellij", fakeZellij, "ZELLIJ=0")
+		wtPath := filepath.Join(repo.Root, ".wt", "feature")
+
+		_, stderr, err := run(t, repo.Root, env, "feature")
+		if err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if !strings.Contains(read(t, log), "action new-tab --name feature --cwd "+wtPath) {
+			t.Errorf("a tab should be created in the worktree, log:\n%s", read(t, log))
+		}
+
+		if _, stderr, err := run(t, repo.Root, env, "feature"); err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if !strings.Contains(read(t, log), "action go-to-tab-name feature") {
+			t.Errorf("the existing tab should be focused, log:\n%s", read(t, log))
+		}
+
+		if _, stderr, err := run(t, repo.Root, env, "-d", "feature"); err != nil {
+			t.Fatalf("git-wt -d feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if got := read(t, state); got != "" {
+			t.Errorf("the tab of the deleted worktree should be closed, state: %q", got)
+		}
+	})
+
+	t.Run("zellij_foreign_tab", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		env, log, state := fake(t, "zellij", fakeZellij, "ZELLIJ=0", "ZELLIJ_SESSION_NAME=test")
+		// A tab the user opened, named like the worktree created without
+		// the multiplexer.
+		if err := os.WriteFile(state, []byte("feature\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, stderr, err := run(t, repo.Root, env, "feature"); err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+
+		repo.Git("config", "wt.multiplexer", "zellij")
+		if _, stderr, err := run(t, repo.Root, env, "-d", "feature"); err != nil {
+			t.Fatalf("git-wt -d feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if got := read(t, state); got != "feature\n" {
+			t.Errorf("a tab git wt did not open should be left open, state: %q", got)
+		}
+		if strings.Contains(read(t, log), "close-tab") || strings.Contains(read(t, log), "go-to-tab-name") {
+			t.Errorf("the tab should not be touched, log:\n%s", read(t, log))
+		}
+	})
+
+	t.Run("zellij_foreign_tab_not_focused", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		repo.Git("config", "wt.multiplexer", "zellij")
+		env, log, state := fake(t, "zellij", fakeZellij, "ZELLIJ=0", "ZELLIJ_SESSION_NAME=test")
+		if err := os.WriteFile(state, []byte("feature\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, stderr, err := run(t, repo.Root, env, "feature"); err != nil {
+			t.Fatalf("git-wt feature failed: %v\nstderr: %s", err, stderr)
+		}
+		if strings.Contains(read(t, log), "go-to-tab-name") || !strings.Contains(read(t, log), "action new-tab --name feature") {
+			t.Errorf("a tab of its own should be created rather than the user's focused, log:\n%s", read(t, log))
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// previous_test.go contains tests for switching back to previous worktrees:
+//   - TestE2E_SwitchBack: git wt - and git wt @{-N} (toggle, N-th previous, pruning, shell integration)
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/exec"
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_SwitchBack(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("toggle_and_nth", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "back-a")
+		if err != nil {
+			t.Fatalf("git-wt back-a failed: %v\noutput: %s", err, out)
+		}
+		a := worktreePath(out)
+		out, err = runGitWt(t, binPath, a, "back-b")
+		if err != nil {
+			t.Fatalf("git-wt back-b failed: %v\noutput: %s", err, out)
+		}
+		b := worktreePath(out)
+
+		// b -> a -> b, like cd -
+		stdout, stderr, err := runGitWtStdout(t, binPath, b, "-")
+		if err != nil {
+			t.Fatalf("git-wt - failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != a {
+			t.Errorf("git wt - in %q should print %q, got %q", b, a, stdout)
+		}
+		stdout, stderr, err = runGitWtStdout(t, binPath, a, "-")
+		if err != nil {
+			t.Fatalf("git-wt - failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != b {
+			t.Errorf("git wt - in %q should print %q, got %q", a, b, stdout)
+		}
+
+		// History is now b, a, main worktree.
+		stdout, stderr, err = runGitWtStdout(t, binPath, b, "@{-2}")
+		if err != nil {
+			t.Fatalf("git-wt @{-2} failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != repo.Root {
+			t.Errorf("git wt @{-2} should print %q, got %q", repo.Root, stdout)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "@{-5}")
+		if err == nil {
+			t.Fatalf("git wt @{-5} should fail, got: %s", out)
+		}
+		if !strings.Contains(out, "fewer than 5 previous worktrees") {
+			t.Errorf("unexpected error: %s", out)
+		}
+	})
+
+	t.Run("no_history", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-")
+		if err == nil {
+			t.Fatalf("git wt - should fail without history, got: %s", out)
+		}
+		if !strings.Contains(out, "no previous worktree") {
+			t.Errorf("unexpected error: %s", out)
+		}
+	})
+
+	t.Run("start_point_not_allowed", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "-", "main")
+		if err == nil {
+			t.Fatalf("git wt - main should fail, got: %s", out)
+		}
+	})
+
+	t.Run("skips_deleted_worktrees", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "gone-a")
+		if err != nil {
+			t.Fatalf("git-wt gone-a failed: %v\noutput: %s", err, out)
+		}
+		a := worktreePath(out)
+		out, err = runGitWt(t, binPath, a, "gone-b")
+		if err != nil {
+			t.Fatalf("git-wt gone-b failed: %v\noutput: %s", err, out)
+		}
+		b := worktreePath(out)
+		if out, err := runGitWt(t, binPath, repo.Root, "-D", "gone-a"); err != nil {
+			t.Fatalf("git-wt -D gone-a failed: %v\noutput: %s", err, out)
+		}
+
+		stdout, stderr, err := runGitWtStdout(t, binPath, b, "-")
+		if err != nil {
+			t.Fatalf("git-wt - failed: %v\nstderr: %s", err, stderr)
+		}
+		if stdout != repo.Root {
+			t.Errorf("git wt - should skip the deleted worktree and print %q, got %q", repo.Root, stdout)
+		}
+	})
+
+	t.Run("bash", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("bash"); err != nil {
+			t.Skip("bash not available")
+		}
+
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		script := fmt.Sprintf(`
+set -e
+cd %q
+export PATH="%s:$PATH"
+eval "$(git wt --init bash)"
+
+git wt back-bash
+git wt -
+pwd
+`, repo.Root, filepath.Dir(binPath))
+
+		cmd := exec.Command("bash", "-c", script) //#nosec G204
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash shell integration failed: %v\noutput: %s", err, out)
+		}
+		assertLastLine(t, string(out), repo.Root)
+	})
+}
@@ -0,0 +1,206 @@
+// prompt_test.go contains tests for the prompt segment:
+//   - TestE2E_Prompt: --prompt output for the main and linked worktrees, dirty markers, formats and outside repositories
+//   - TestE2E_PromptInit: --init zsh/starship --prompt snippets
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/exec"
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Prompt(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	prompt := func(t *testing.T, dir string, args ...string) string {
+		t.Helper()
+		stdout, stderr, err := runGitWtStdout(t, binPath, dir, append([]string{"--prompt"}, args...)...)
+		if err != nil {
+			t.Fatalf("git-wt --prompt failed: %v\nstderr: %s", err, stderr)
+		}
+		return stdout
+	}
+
+	t.Run("no_worktrees", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if got := prompt(t, repo.Root); got != "" {
+			t.Errorf("--prompt should print nothing without linked worktrees, got %q", got)
+		}
+	})
+
+	t.Run("linked_worktree", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature/a", "other")
+
+		wtPath := filepath.Join(repo.Root, ".wt", "feature", "a")
+		if got := prompt(t, wtPath); got != "feature/a" {
+			t.Errorf("--prompt = %q, want %q", got, "feature/a")
+		}
+		// Subdirectories show the same worktree.
+		if err := os.MkdirAll(filepath.Join(wtPath, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if got := prompt(t, filepath.Join(wtPath, "sub")); got != "feature/a" {
+			t.Errorf("--prompt in a subdirectory = %q, want %q", got, "feature/a")
+		}
+	})
+
+	t.Run("dirty", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "alpha", "bravo", "charlie")
+		wtAlpha := filepath.Join(repo.Root, ".wt", "alpha")
+		for _, p := range []string{wtAlpha, filepath.Join(repo.Root, ".wt", "bravo"), repo.Root} {
+			if err := os.WriteFile(filepath.Join(p, "new.txt"), []byte("x"), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got := prompt(t, wtAlpha); got != "alpha* +2" {
+			t.Errorf("--prompt = %q, want %q", got, "alpha* +2")
+		}
+		if got := prompt(t, filepath.Join(repo.Root, ".wt", "charlie")); got != "charlie +3" {
+			t.Errorf("--prompt = %q, want %q", got, "charlie +3")
+		}
+	})
+
+	t.Run("main_worktree", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := newRepoWithWorktrees(t, binPath, "feature")
+		branch := strings.TrimSpace(repo.Git("branch", "--show-current"))
+
+		if got := prompt(t, repo.Root); got != branch {
+			t.Errorf("--prompt in the main working tree = %q, want %q", got, branch)
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		if out, err := runGitWt(t, binPath, repo.Root, "-b", "feature/x", "x"); err != nil {
+			t.Fatalf("git-wt -b failed: %v\noutput: %s", err, out)
+		}
+		wtPath := filepath.Join(repo.Root, ".wt", "x")
+
+		if got := prompt(t, wtPath, "--format", `{{.Name}}\t{{.Branch}}\t{{.Main}}`); got != "x\tfeature/x\tfalse" {
+			t.Errorf("--prompt --format = %q", got)
+		}
+
+		repo.Git("config", "wt.promptformat", "[{{.Branch}}]")
+		if got := prompt(t, wtPath); got != "[feature/x]" {
+			t.Errorf("--prompt with wt.promptformat = %q, want %q", got, "[feature/x]")
+		}
+		// wt.promptformat does not affect the list.
+		if out, err := runGitWt(t, binPath, repo.Root); err != nil || strings.Contains(out, "[feature/x]") {
+			t.Errorf("the list should not use wt.promptformat: %v\noutput: %s", err, out)
+		}
+
+		if out, err := runGitWt(t, binPath, wtPath, "--prompt", "--format", "{{.Nope"); err == nil {
+			t.Errorf("--prompt with an invalid format should fail, got: %s", out)
+		}
+	})
+
+	t.Run("basedir", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.Git("config", "wt.basedir", "../{gitroot}-worktrees")
+		if out, err := runGitWt(t, binPath, repo.Root, "feature/y"); err != nil {
+			t.Fatalf("git-wt failed: %v\noutput: %s", err, out)
+		}
+
+		wtPath := filepath.Join(filepath.Dir(repo.Root), filepath.Base(repo.Root)+"-worktrees", "feature", "y")
+		if got := prompt(t, wtPath); got != "feature/y" {
+			t.Errorf("--prompt = %q, want %q", got, "feature/y")
+		}
+	})
+
+	t.Run("outside_repository", func(t *testing.T) {
+		t.Parallel()
+
+		if got := prompt(t, t.TempDir()); got != "" {
+			t.Errorf("--prompt outside a repository should print nothing, got %q", got)
+		}
+	})
+}
+
+func TestE2E_PromptInit(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("zsh", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "zsh", "--prompt")
+		if err != nil {
+			t.Fatalf("git-wt --init zsh --prompt failed: %v\noutput: %s", err, out)
+		}
+		for _, s := range []string{"git() {", "add-zsh-hook precmd _git_wt_prompt_precmd", "command git-wt --prompt", "PROMPT="} {
+			if !strings.Contains(out, s) {
+				t.Errorf("output should contain %q", s)
+			}
+		}
+
+		if _, err := exec.LookPath("zsh"); err != nil {
+			t.Skip("zsh not available")
+		}
+		// Evaluating the snippet again (e.g., reloading ~/.zshrc) must not add
+		// the segment twice.
+		script := `PROMPT='%~ %# '; eval "$(` + binPath + ` --init zsh --prompt)"; eval "$(` + binPath + ` --init zsh --prompt)"; _git_wt_prompt_precmd; print -r -- "$PROMPT"`
+		cmd := exec.Command("zsh", "-f", "-c", script) //#nosec G204
+		cmd.Dir = t.TempDir()
+		b, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("zsh failed: %v\noutput: %s", err, b)
+		}
+		if got, want := strings.TrimSpace(string(b)), `${_git_wt_prompt:+[${_git_wt_prompt//\%/%%}] }%~ %#`; got != want {
+			t.Errorf("PROMPT = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("zsh_without_prompt", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "zsh")
+		if err != nil {
+			t.Fatalf("git-wt --init zsh failed: %v\noutput: %s", err, out)
+		}
+		if strings.Contains(out, "PROMPT=") {
+			t.Error("--init zsh should not touch the prompt without --prompt")
+		}
+	})
+
+	t.Run("starship", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "starship", "--prompt")
+		if err != nil {
+			t.Fatalf("git-wt --init starship --prompt failed: %v\noutput: %s", err, out)
+		}
+		for _, s := range []string{"[custom.git_wt]", `command = "git-wt --prompt"`} {
+			if !strings.Contains(out, s) {
+				t.Errorf("output should contain %q, got:\n%s", s, out)
+			}
+		}
+
+		if out, err := runGitWt(t, binPath, t.TempDir(), "--init", "starship"); err == nil {
+			t.Errorf("--init starship without --prompt should fail, got: %s", out)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "fish", "--prompt")
+		if err == nil {
+			t.Errorf("--init fish --prompt should fail, got: %s", out)
+		}
+	})
+}
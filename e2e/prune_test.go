@@ -0,0 +1,268 @@
+// prune_test.go contains bulk cleanup tests:
+//   - TestE2E_PruneMerged: --prune-merged (merged/squash-merged/unmerged/dirty/locked/new worktrees, branch-only entries, confirmation without a terminal)
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_PruneMerged(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// setup creates worktrees "merged" (merged into main), "unmerged" (with
+	// a commit not on main) and "dirty" (merged but with an untracked file).
+	setup := func(t *testing.T) (repo *testutil.TestRepo, merged, unmerged, dirty string) {
+		t.Helper()
+		repo = testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "merged")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		merged = worktreePath(out)
+		if err := os.WriteFile(filepath.Join(merged, "merged.txt"), []byte("merged"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		repo.Git("-C", merged, "add", "-A")
+		repo.Git("-C", merged, "commit", "-m", "merged commit")
+		repo.Git("merge", "merged")
+
+		out, err = runGitWt(t, binPath, repo.Root, "unmerged")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		unmerged = worktreePath(out)
+		commitUnmergedChange(t, unmerged)
+
+		out, err = runGitWt(t, binPath, repo.Root, "dirty")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		dirty = worktreePath(out)
+		if err := os.WriteFile(filepath.Join(dirty, "dirty.txt"), []byte("dirty"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		repo.Git("-C", dirty, "add", "-A")
+		repo.Git("-C", dirty, "commit", "-m", "dirty commit")
+		repo.Git("merge", "--no-edit", "dirty")
+		if err := os.WriteFile(filepath.Join(dirty, "untracked.txt"), []byte("wip"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		return repo, merged, unmerged, dirty
+	}
+
+	t.Run("deletes_merged_only", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, unmerged, dirty := setup(t)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+
+		assertWorktreeDeleted(t, merged)
+		assertWorktreeExists(t, unmerged)
+		assertWorktreeExists(t, dirty)
+		if !strings.Contains(out, "skip") || !strings.Contains(out, "use -D to force") {
+			t.Errorf("plan should report the dirty worktree as skipped, got: %s", out)
+		}
+		if branches := repo.Git("branch", "--list", "merged"); branches != "" {
+			t.Errorf("merged branch should have been deleted, got: %s", branches)
+		}
+		if branches := repo.Git("branch", "--list", "main"); branches == "" {
+			t.Error("default branch must not be deleted")
+		}
+	})
+
+	t.Run("force_deletes_dirty", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, unmerged, dirty := setup(t)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "-D", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged -D failed: %v\noutput: %s", err, out)
+		}
+
+		assertWorktreeDeleted(t, merged)
+		assertWorktreeDeleted(t, dirty)
+		assertWorktreeExists(t, unmerged)
+	})
+
+	t.Run("from_feature_worktree", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, unmerged, _ := setup(t)
+
+		// Run from the unmerged worktree: merge status must be checked
+		// against the default branch, not the current branch.
+		out, err := runGitWt(t, binPath, unmerged, "--prune-merged", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+
+		assertWorktreeDeleted(t, merged)
+		assertWorktreeExists(t, unmerged)
+		if branches := repo.Git("branch", "--list", "merged"); branches != "" {
+			t.Errorf("merged branch should have been deleted, got: %s", branches)
+		}
+	})
+
+	t.Run("squash_merged", func(t *testing.T) {
+		t.Parallel()
+		repo, _, unmerged, _ := setup(t)
+
+		out, err := runGitWt(t, binPath, repo.Root, "squashed")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		squashed := worktreePath(out)
+		if err := os.WriteFile(filepath.Join(squashed, "squashed.txt"), []byte("squashed"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		repo.Git("-C", squashed, "add", "-A")
+		repo.Git("-C", squashed, "commit", "-m", "squashed commit")
+		repo.Git("merge", "--squash", "squashed")
+		repo.Commit("squash merge")
+
+		out, err = runGitWt(t, binPath, repo.Root, "--prune-merged", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, squashed)
+		assertWorktreeExists(t, unmerged)
+		if branches := repo.Git("branch", "--list", "squashed"); branches != "" {
+			t.Errorf("squash-merged branch should have been deleted, got: %s", branches)
+		}
+	})
+
+	t.Run("skips_locked", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, _, _ := setup(t)
+		repo.Git("worktree", "lock", "--reason", "keep", merged)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "-D", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeExists(t, merged)
+		if !strings.Contains(out, "locked: keep") {
+			t.Errorf("plan should report the locked worktree as skipped, got: %s", out)
+		}
+	})
+
+	t.Run("include_branches", func(t *testing.T) {
+		t.Parallel()
+		repo, _, _, _ := setup(t)
+		repo.Git("checkout", "-q", "-b", "merged-no-wt")
+		repo.CreateFile("no-wt.txt", "no worktree")
+		repo.Commit("merged without worktree")
+		repo.Git("checkout", "-q", "main")
+		repo.Git("merge", "--no-edit", "merged-no-wt")
+		repo.Git("branch", "unmerged-no-wt", "unmerged")
+
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+		if branches := repo.Git("branch", "--list", "merged-no-wt"); branches == "" {
+			t.Error("branches without worktree should be kept without --include-branches")
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--prune-merged", "--include-branches", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged --include-branches failed: %v\noutput: %s", err, out)
+		}
+		if branches := repo.Git("branch", "--list", "merged-no-wt"); branches != "" {
+			t.Errorf("merged branch without worktree should have been deleted, got: %s", branches)
+		}
+		if branches := repo.Git("branch", "--list", "unmerged-no-wt"); branches == "" {
+			t.Error("unmerged branch without worktree should be kept")
+		}
+	})
+
+	t.Run("keeps_new_branches", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, _, _ := setup(t)
+
+		// Branches without commits are reachable from main but not merged.
+		out, err := runGitWt(t, binPath, repo.Root, "new-at-tip")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		atTip := worktreePath(out)
+		out, err = runGitWt(t, binPath, repo.Root, "new-behind", "merged~1")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		behind := worktreePath(out)
+		repo.Git("branch", "new-no-wt")
+
+		out, err = runGitWt(t, binPath, repo.Root, "--prune-merged", "--include-branches", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, merged)
+		assertWorktreeExists(t, atTip)
+		assertWorktreeExists(t, behind)
+		if branches := repo.Git("branch", "--list", "new-no-wt"); branches == "" {
+			t.Error("new branch without worktree should be kept")
+		}
+		if !strings.Contains(out, "no commits of its own yet") {
+			t.Errorf("plan should report the new branches as skipped, got: %s", out)
+		}
+	})
+
+	t.Run("requires_terminal", func(t *testing.T) {
+		t.Parallel()
+		repo, merged, _, _ := setup(t)
+
+		// stdin is not a terminal, so there is no one to confirm.
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged")
+		if err == nil {
+			t.Fatalf("git-wt --prune-merged should fail without a terminal, output: %s", out)
+		}
+		if !strings.Contains(out, "use --yes") {
+			t.Errorf("error should suggest --yes, got: %s", out)
+		}
+		assertWorktreeExists(t, merged)
+		if branches := repo.Git("branch", "--list", "merged"); branches == "" {
+			t.Error("merged branch should be kept without confirmation")
+		}
+	})
+
+	t.Run("nothing_to_prune", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --prune-merged failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "Nothing to prune") {
+			t.Errorf("output should report nothing to prune, got: %s", out)
+		}
+	})
+
+	t.Run("rejects_arguments", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--prune-merged", "foo"); err == nil {
+			t.Errorf("--prune-merged with arguments should fail, got: %s", out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--include-branches"); err == nil {
+			t.Errorf("--include-branches without --prune-merged should fail, got: %s", out)
+		}
+	})
+}
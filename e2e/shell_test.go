@@ -1,7 +1,8 @@
 // shell_test.go contains shell integration tests:
-//   - TestE2E_InitScript: --init script generation (bash/zsh/fish/powershell, nocd, unsupported_shell)
+//   - TestE2E_InitScript: --init script generation (bash/zsh/fish/powershell/nu/elvish, nocd, unsupported_shell)
 //   - TestE2E_ShellIntegration_StdoutFormat: stdout format for shell integration compatibility
-//   - TestE2E_ShellIntegration: shell integration cd tests (bash, zsh, fish, powershell, nocd)
+//   - TestE2E_ShellIntegration: shell integration cd tests (bash, zsh, fish, nu, elvish, powershell, nocd)
+//   - TestE2E_ShellIntegration_DirectiveFile: GIT_WT_DIRECTIVE_FILE protocol (cd, created, env, exit code)
 package e2e
 
 import (
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -80,6 +82,50 @@ func TestE2E_InitScript(t *testing.T) {
 		}
 	})
 
+	t.Run("nu", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "nu")
+		if err != nil {
+			t.Fatalf("git-wt --init nu failed: %v\noutput: %s", err, out)
+		}
+
+		contains := []string{"# git-wt shell hook for nushell", "def --env --wrapped git", "$env.config.completions.external.completer"}
+		for _, s := range contains {
+			if !strings.Contains(out, s) {
+				t.Errorf("output should contain %q, got: %s", s, out)
+			}
+		}
+	})
+
+	t.Run("elvish", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "elvish")
+		if err != nil {
+			t.Fatalf("git-wt --init elvish failed: %v\noutput: %s", err, out)
+		}
+
+		contains := []string{"# git-wt shell hook for elvish", "fn git {|@args|", "edit:completion:arg-completer[git-wt]"}
+		for _, s := range contains {
+			if !strings.Contains(out, s) {
+				t.Errorf("output should contain %q, got: %s", s, out)
+			}
+		}
+	})
+
+	t.Run("nocd_nu", func(t *testing.T) {
+		t.Parallel()
+		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "nu", "--nocd")
+		if err != nil {
+			t.Fatalf("git-wt --init nu --nocd failed: %v\noutput: %s", err, out)
+		}
+		if strings.Contains(out, "def --env --wrapped git") {
+			t.Error("output should not contain git wrapper when --nocd is used")
+		}
+		if !strings.Contains(out, "__git_wt_complete") {
+			t.Error("output should contain completion")
+		}
+	})
+
 	t.Run("nocd", func(t *testing.T) {
 		t.Parallel()
 		out, err := runGitWt(t, binPath, t.TempDir(), "--init", "bash", "--nocd")
@@ -342,6 +388,103 @@ pwd
 		}
 	})
 
+	t.Run("nu", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("nu"); err != nil {
+			t.Skip("nu not available")
+		}
+
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		// 'source' needs a file that exists when the script is parsed.
+		initScript, _, err := runGitWtStdout(t, binPath, repo.Root, "--init", "nu")
+		if err != nil {
+			t.Fatalf("git-wt --init nu failed: %v", err)
+		}
+		initFile := filepath.Join(t.TempDir(), "git-wt.nu")
+		if err := os.WriteFile(initFile, []byte(initScript+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		script := fmt.Sprintf(`
+source %q
+cd %q
+$env.PATH = ($env.PATH | prepend %q)
+
+# Test: a failing git wt should raise an error
+try { git wt --delete no-such-branch; print "not raised" } catch { print "raised" }
+
+# Test: git wt <branch> should cd to the worktree
+git wt shell-nu-test
+print (pwd)
+`, initFile, repo.Root, filepath.Dir(binPath))
+
+		cmd := exec.Command("nu", "--no-config-file", "-c", script)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("nu shell integration failed: %v\noutput: %s", err, out)
+		}
+
+		output := strings.TrimSpace(string(out))
+		lines := strings.Split(output, "\n")
+		pwd := lines[len(lines)-1]
+
+		if !strings.Contains(pwd, "shell-nu-test") {
+			t.Errorf("pwd should contain worktree path, got: %s", pwd)
+		}
+		if !slices.Contains(lines, "raised") {
+			t.Errorf("a failing git wt should raise an error, got: %s", output)
+		}
+	})
+
+	t.Run("elvish", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("elvish"); err != nil {
+			t.Skip("elvish not available")
+		}
+
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		// The init script registers git~ and completers through the line
+		// editor (edit:), which only exists in interactive shells, so a
+		// stand-in edit: namespace is passed to eval.
+		script := fmt.Sprintf(`
+cd %q
+set E:PATH = %q
+var git~ = $nil
+var edit-ns = (ns [&add-var~={|name value| set git~ = $value } &completion:=(ns [&arg-completer=[&]])])
+eval (git-wt --init elvish | slurp) &ns=(ns [&edit:=$edit-ns])
+
+# Test: a failing git wt should raise an exception
+try { git wt --delete no-such-branch; echo 'not raised' } catch { echo raised }
+
+# Test: git wt <branch> should cd to the worktree
+git wt shell-elvish-test
+echo $pwd
+`, repo.Root, filepath.Dir(binPath)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		cmd := exec.Command("elvish", "-norc", "-c", script)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("elvish shell integration failed: %v\noutput: %s", err, out)
+		}
+
+		output := strings.TrimSpace(string(out))
+		lines := strings.Split(output, "\n")
+		pwd := lines[len(lines)-1]
+
+		if !strings.Contains(pwd, "shell-elvish-test") {
+			t.Errorf("pwd should contain worktree path, got: %s", pwd)
+		}
+		if !slices.Contains(lines, "raised") {
+			t.Errorf("a failing git wt should raise an error, got: %s", output)
+		}
+	})
+
 	t.Run("powershell", func(t *testing.T) {
 		t.Parallel()
 		// PowerShell init script uses git.exe which is Windows-specific
@@ -514,3 +657,170 @@ pwd
 		}
 	})
 }
+
+// TestE2E_ShellIntegration_DirectiveFile tests the directive file protocol
+// (GIT_WT_DIRECTIVE_FILE) used by the shell integration.
+func TestE2E_ShellIntegration_DirectiveFile(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// run runs git-wt with a directive file and returns stdout and the directives.
+	run := func(t *testing.T, dir string, args ...string) (string, string) {
+		t.Helper()
+		directiveFile := filepath.Join(t.TempDir(), "directives")
+		if err := os.WriteFile(directiveFile, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command(binPath, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_WT_SHELL_INTEGRATION=1", "GIT_WT_DIRECTIVE_FILE="+directiveFile)
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git-wt %v failed: %v\nstderr: %s", args, err, stderr.String())
+		}
+		b, err := os.ReadFile(directiveFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(stdout.String()), string(b)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		stdout, directives := run(t, repo.Root, "directive-create")
+		if stdout != "" {
+			t.Errorf("stdout should be empty with a directive file, got: %q", stdout)
+		}
+		want := fmt.Sprintf("cd\t%s\ncreated\t1\n", filepath.Join(repo.Root, ".wt", "directive-create"))
+		if directives != want {
+			t.Errorf("directives = %q, want %q", directives, want)
+		}
+	})
+
+	t.Run("switch", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		run(t, repo.Root, "directive-switch")
+		stdout, directives := run(t, repo.Root, "directive-switch")
+		if stdout != "" {
+			t.Errorf("stdout should be empty with a directive file, got: %q", stdout)
+		}
+		want := fmt.Sprintf("cd\t%s\n", filepath.Join(repo.Root, ".wt", "directive-switch"))
+		if directives != want {
+			t.Errorf("directives = %q, want %q", directives, want)
+		}
+	})
+
+	t.Run("delete_current", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		run(t, repo.Root, "directive-delete")
+		wtPath := filepath.Join(repo.Root, ".wt", "directive-delete")
+		_, directives := run(t, wtPath, "-d", "directive-delete")
+		want := fmt.Sprintf("cd\t%s\n", repo.Root)
+		if directives != want {
+			t.Errorf("directives = %q, want %q", directives, want)
+		}
+	})
+
+	t.Run("move_current", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		run(t, repo.Root, "directive-old")
+		_, directives := run(t, filepath.Join(repo.Root, ".wt", "directive-old"), "-m", "directive-new")
+		want := fmt.Sprintf("cd\t%s\n", filepath.Join(repo.Root, ".wt", "directive-new"))
+		if directives != want {
+			t.Errorf("directives = %q, want %q", directives, want)
+		}
+	})
+
+	t.Run("hook_env_bash", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("bash"); err != nil {
+			t.Skip("bash not available")
+		}
+
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		// The hook prints a directory as its last line, which must not be
+		// mistaken for the cd target, and exports a variable to the shell.
+		repo.Git("config", "wt.hook", `echo /; printf 'env\tWT_HOOK_VAR\thello world\n' >> "$GIT_WT_DIRECTIVE_FILE"`)
+
+		script := fmt.Sprintf(`
+set -e
+cd %q
+export PATH="%s:$PATH"
+eval "$(git wt --init bash)"
+
+git wt directive-hook
+echo "var=$WT_HOOK_VAR"
+pwd
+`, repo.Root, filepath.Dir(binPath))
+
+		cmd := exec.Command("bash", "-c", script)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash shell integration failed: %v\noutput: %s", err, out)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if pwd := lines[len(lines)-1]; pwd != filepath.Join(repo.Root, ".wt", "directive-hook") {
+			t.Errorf("pwd = %q, want the worktree %q\noutput: %s", pwd, filepath.Join(repo.Root, ".wt", "directive-hook"), out)
+		}
+		if !strings.Contains(string(out), "var=hello world\n") {
+			t.Errorf("WT_HOOK_VAR should be exported by the env directive, output: %s", out)
+		}
+	})
+
+	t.Run("failure_bash", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("bash"); err != nil {
+			t.Skip("bash not available")
+		}
+
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+		repo.Git("config", "wt.hook", "exit 3")
+
+		script := fmt.Sprintf(`
+cd %q
+export PATH="%s:$PATH"
+eval "$(git wt --init bash)"
+
+git wt directive-fail
+echo "status=$?"
+pwd
+`, repo.Root, filepath.Dir(binPath))
+
+		cmd := exec.Command("bash", "-c", script)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash failed: %v\noutput: %s", err, out)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if pwd := lines[len(lines)-1]; pwd != repo.Root {
+			t.Errorf("pwd = %q, want %q (no cd on failure)", pwd, repo.Root)
+		}
+		if !strings.Contains(string(out), "status=1\n") {
+			t.Errorf("the wrapper should return the exit code of git wt, output: %s", out)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+// stale_test.go contains inactive worktree tests:
+//   - TestE2E_OlderThan: --older-than report, --json output and deletion with -d/-D
+package e2e
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_OlderThan(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	// setup creates worktrees "old" and "dirty", both without activity for
+	// 60 days ("dirty" has an old untracked file), and a fresh "recent" one.
+	setup := func(t *testing.T) (repo *testutil.TestRepo, old, dirty, recent string) {
+		t.Helper()
+		repo = testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Git("add", "README.md")
+		past := time.Now().Add(-60 * 24 * time.Hour)
+		commit := exec.Command("git", "commit", "-m", "initial commit", "--date", past.Format(time.RFC3339))
+		commit.Dir = repo.Root
+		commit.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+past.Format(time.RFC3339))
+		if out, err := commit.CombinedOutput(); err != nil {
+			t.Fatalf("failed to commit: %v\noutput: %s", err, out)
+		}
+
+		paths := map[string]string{}
+		for _, name := range []string{"old", "dirty", "recent"} {
+			out, err := runGitWt(t, binPath, repo.Root, name)
+			if err != nil {
+				t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+			}
+			paths[name] = worktreePath(out)
+		}
+		if err := os.WriteFile(filepath.Join(paths["dirty"], "wip.txt"), []byte("wip"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		for _, name := range []string{"old", "dirty"} {
+			backdate(t, paths[name], past)
+		}
+
+		// Switching to "recent" again keeps it active even though its files
+		// and commits are old.
+		backdate(t, paths["recent"], past)
+		if out, err := runGitWt(t, binPath, repo.Root, "recent"); err != nil {
+			t.Fatalf("failed to switch to worktree: %v\noutput: %s", err, out)
+		}
+		return repo, paths["old"], paths["dirty"], paths["recent"]
+	}
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+		repo, old, dirty, recent := setup(t)
+		ageSwitchHistory(t, repo, old, dirty)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--older-than", "30d", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --older-than --json failed: %v\noutput: %s", err, out)
+		}
+		var entries []struct {
+			Path         string     `json:"path"`
+			Branch       string     `json:"branch"`
+			LastActivity time.Time  `json:"last_activity"`
+			LastSwitch   *time.Time `json:"last_switch"`
+		}
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		var branches []string
+		for _, e := range entries {
+			branches = append(branches, e.Branch)
+			if time.Since(e.LastActivity) < 30*24*time.Hour {
+				t.Errorf("%s: last activity %v should be older than 30 days", e.Branch, e.LastActivity)
+			}
+		}
+		if len(branches) != 2 || !slices.Contains(branches, "old") || !slices.Contains(branches, "dirty") {
+			t.Errorf("expected old and dirty worktrees, got %v", branches)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--older-than", "30d")
+		if err != nil {
+			t.Fatalf("git-wt --older-than failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, old) || strings.Contains(out, recent) || strings.Contains(out, repo.Root+" ") {
+			t.Errorf("report should list only inactive linked worktrees, got: %s", out)
+		}
+		if !strings.Contains(out, "days ago") {
+			t.Errorf("report should show the age, got: %s", out)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		repo, old, dirty, recent := setup(t)
+		ageSwitchHistory(t, repo, old, dirty)
+
+		out, err := runGitWt(t, binPath, repo.Root, "--older-than", "30d", "-d", "--dry-run")
+		if err != nil {
+			t.Fatalf("git-wt --older-than -d --dry-run failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "Dry run") {
+			t.Errorf("dry run should print a plan, got: %s", out)
+		}
+		assertWorktreeExists(t, old)
+
+		out, err = runGitWt(t, binPath, repo.Root, "--older-than", "30d", "-d", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --older-than -d failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, old)
+		assertWorktreeExists(t, dirty)
+		assertWorktreeExists(t, recent)
+		if !strings.Contains(out, "skip") || !strings.Contains(out, "-D") {
+			t.Errorf("dirty worktree should be skipped with a hint to use -D, got: %s", out)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--older-than", "30d", "-D", "--yes")
+		if err != nil {
+			t.Fatalf("git-wt --older-than -D failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, dirty)
+		assertWorktreeExists(t, recent)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--older-than", "soon"); err == nil || !strings.Contains(out, "invalid age") {
+			t.Errorf("invalid age should fail, got: %v: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--older-than", "30d", "feature"); err == nil {
+			t.Errorf("--older-than with arguments should fail, got: %s", out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--older-than", "30d", "--dry-run"); err == nil {
+			t.Errorf("--dry-run without -d/-D should fail, got: %s", out)
+		}
+	})
+}
+
+// backdate sets the mtime of every file in the worktree at dir to tm.
+func backdate(t *testing.T, dir string, tm time.Time) {
+	t.Helper()
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return os.Chtimes(path, tm, tm)
+	})
+	if err != nil {
+		t.Fatalf("failed to backdate %s: %v", dir, err)
+	}
+}
+
+// ageSwitchHistory moves the recorded git wt switches to the given worktrees
+// 60 days into the past.
+func ageSwitchHistory(t *testing.T, repo *testutil.TestRepo, paths ...string) {
+	t.Helper()
+	file := filepath.Join(repo.Root, ".git", "wt", "history.json")
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read switch history: %v", err)
+	}
+	var entries []map[string]any
+	if err := json.Unmarshal(b, &entries); err != nil {
+		t.Fatalf("failed to parse switch history: %v", err)
+	}
+	past := time.Now().Add(-60 * 24 * time.Hour).Format(time.RFC3339)
+	for _, e := range entries {
+		for _, p := range paths {
+			if e["path"] == p {
+				e["switched_at"] = past
+			}
+		}
+	}
+	b, err = json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		t.Fatalf("failed to write switch history: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+// trash_test.go contains recoverable delete tests:
+//   - TestE2E_Trash: --restore, --trash, --expire-trash and wt.trashexpire after deleting worktrees, safe deletes and failed removals that skip the trash, -D without a snapshot and concurrent deletes
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestE2E_Trash(t *testing.T) {
+	t.Parallel()
+	binPath := buildBinary(t)
+
+	t.Run("restore_force_deleted", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "feature")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		commitUnmergedChange(t, wtPath)
+		head := repo.Git("rev-parse", "feature")
+		if err := os.WriteFile(filepath.Join(wtPath, "wip.txt"), []byte("wip"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "-D", "feature")
+		if err != nil {
+			t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+		}
+		assertWorktreeDeleted(t, wtPath)
+		if !strings.Contains(out, "git wt --restore feature") {
+			t.Errorf("output should mention how to restore, got: %s", out)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--restore", "feature")
+		if err != nil {
+			t.Fatalf("git-wt --restore failed: %v\noutput: %s", err, out)
+		}
+		if got := worktreePath(out); got != wtPath {
+			t.Errorf("restore should print the worktree path %q, got: %s", wtPath, out)
+		}
+		assertWorktreeExists(t, wtPath)
+		if got := repo.Git("rev-parse", "feature"); got != head {
+			t.Errorf("branch should be restored at %s, got %s", head, got)
+		}
+		b, err := os.ReadFile(filepath.Join(wtPath, "wip.txt"))
+		if err != nil || string(b) != "wip" {
+			t.Errorf("untracked file should be restored, got %q, %v", b, err)
+		}
+
+		// The entry is consumed by the restore.
+		if out, err := runGitWt(t, binPath, repo.Root, "--restore", "feature"); err == nil {
+			t.Errorf("second restore should fail, got: %s", out)
+		}
+	})
+
+	t.Run("list_and_expire", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		for _, name := range []string{"one", "two"} {
+			if out, err := runGitWt(t, binPath, repo.Root, name); err != nil {
+				t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+			}
+			if out, err := runGitWt(t, binPath, repo.Root, "-D", name); err != nil {
+				t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+			}
+		}
+
+		out, err := runGitWt(t, binPath, repo.Root, "--trash", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --trash --json failed: %v\noutput: %s", err, out)
+		}
+		var entries []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Branch string `json:"branch"`
+		}
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(entries) != 2 || entries[0].Name != "two" || entries[1].Name != "one" {
+			t.Fatalf("expected entries two, one (newest first), got %+v", entries)
+		}
+
+		out, err = runGitWt(t, binPath, repo.Root, "--trash")
+		if err != nil {
+			t.Fatalf("git-wt --trash failed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, entries[0].ID) || !strings.Contains(out, entries[1].ID) {
+			t.Errorf("trash list should contain both IDs, got: %s", out)
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--expire-trash", "30d"); err != nil {
+			t.Fatalf("git-wt --expire-trash failed: %v\noutput: %s", err, out)
+		}
+		if out, _ := runGitWt(t, binPath, repo.Root, "--trash", "--json"); !strings.Contains(out, entries[0].ID) {
+			t.Errorf("recent entries should survive --expire-trash 30d, got: %s", out)
+		}
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--expire-trash", "0"); err != nil {
+			t.Fatalf("git-wt --expire-trash failed: %v\noutput: %s", err, out)
+		}
+		out, err = runGitWt(t, binPath, repo.Root, "--trash", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --trash --json failed: %v\noutput: %s", err, out)
+		}
+		if strings.TrimSpace(out) != "[]" {
+			t.Errorf("trash should be empty after --expire-trash 0, got: %s", out)
+		}
+		if refs := repo.Git("for-each-ref", "refs/wt-trash/"); refs != "" {
+			t.Errorf("trash refs should be deleted, got: %s", refs)
+		}
+	})
+
+	t.Run("safe_delete_not_trashed", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "clean"); err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "-d", "clean"); err != nil {
+			t.Fatalf("git-wt -d failed: %v\noutput: %s", err, out)
+		}
+		if out, _ := runGitWt(t, binPath, repo.Root, "--trash", "--json"); strings.TrimSpace(out) != "[]" {
+			t.Errorf("a safe delete of a clean worktree should not be trashed, got: %s", out)
+		}
+		if refs := repo.Git("for-each-ref", "refs/wt-trash/"); refs != "" {
+			t.Errorf("no trash refs should be created, got: %s", refs)
+		}
+	})
+
+	// -D is the way out of a worktree that cannot be snapshot, e.g., one
+	// with an unborn HEAD.
+	t.Run("force_delete_without_snapshot", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "unborn")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		repo.Git("-C", wtPath, "checkout", "--orphan", "orphan")
+
+		out, err = runGitWt(t, binPath, repo.Root, "-D", "unborn")
+		if err != nil {
+			t.Fatalf("git-wt -D should delete a worktree that cannot be trashed: %v\noutput: %s", err, out)
+		}
+		if !strings.Contains(out, "warning: failed to save worktree") {
+			t.Errorf("output should warn that no snapshot was taken, got: %s", out)
+		}
+		assertWorktreeDeleted(t, wtPath)
+	})
+
+	t.Run("remover_failure_not_trashed", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "kept")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--remover", "false", "-D", "kept"); err == nil {
+			t.Fatalf("git-wt -D should fail with a failing remover, got: %s", out)
+		}
+		assertWorktreeExists(t, wtPath)
+		if out, _ := runGitWt(t, binPath, repo.Root, "--trash", "--json"); strings.TrimSpace(out) != "[]" {
+			t.Errorf("a worktree that is still there should not be trashed, got: %s", out)
+		}
+		if refs := repo.Git("for-each-ref", "refs/wt-trash/"); refs != "" {
+			t.Errorf("no trash refs should be left, got: %s", refs)
+		}
+	})
+
+	t.Run("concurrent_deletes", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		names := []string{"c1", "c2", "c3", "c4", "c5", "c6"}
+		for _, name := range names {
+			if out, err := runGitWt(t, binPath, repo.Root, name); err != nil {
+				t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+			}
+		}
+		var wg sync.WaitGroup
+		errs := make([]error, len(names))
+		for i, name := range names {
+			wg.Go(func() {
+				if out, err := runGitWt(t, binPath, repo.Root, "-D", name); err != nil {
+					errs[i] = fmt.Errorf("git-wt -D %s failed: %w\noutput: %s", name, err, out)
+				}
+			})
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Every delete is kept in the trash journal.
+		out, err := runGitWt(t, binPath, repo.Root, "--trash", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --trash --json failed: %v\noutput: %s", err, out)
+		}
+		var entries []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(entries) != len(names) {
+			t.Errorf("expected %d trash entries, got %+v", len(names), entries)
+		}
+	})
+
+	t.Run("auto_expire", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		deleteWorktree := func(name string) {
+			t.Helper()
+			if out, err := runGitWt(t, binPath, repo.Root, name); err != nil {
+				t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+			}
+			if out, err := runGitWt(t, binPath, repo.Root, "-D", name); err != nil {
+				t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+			}
+		}
+		deleteWorktree("old")
+		// Entries older than wt.trashexpire are dropped on the next delete.
+		repo.Git("config", "wt.trashexpire", "0")
+		deleteWorktree("new")
+
+		out, err := runGitWt(t, binPath, repo.Root, "--trash", "--json")
+		if err != nil {
+			t.Fatalf("git-wt --trash --json failed: %v\noutput: %s", err, out)
+		}
+		var entries []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("failed to parse JSON: %v\noutput: %s", err, out)
+		}
+		if len(entries) != 1 || entries[0].Name != "new" {
+			t.Errorf("only the new entry should be left, got %+v", entries)
+		}
+
+		// "never" keeps all entries.
+		repo.Git("config", "wt.trashexpire", "never")
+		deleteWorktree("newer")
+		if out, _ := runGitWt(t, binPath, repo.Root, "--trash", "--json"); !strings.Contains(out, `"new"`) || !strings.Contains(out, `"newer"`) {
+			t.Errorf("entries should be kept with wt.trashexpire never, got: %s", out)
+		}
+	})
+
+	t.Run("restore_refuses_diverged_branch", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		out, err := runGitWt(t, binPath, repo.Root, "feature")
+		if err != nil {
+			t.Fatalf("failed to create worktree: %v\noutput: %s", err, out)
+		}
+		wtPath := worktreePath(out)
+		commitUnmergedChange(t, wtPath)
+		if out, err := runGitWt(t, binPath, repo.Root, "-D", "feature"); err != nil {
+			t.Fatalf("git-wt -D failed: %v\noutput: %s", err, out)
+		}
+		repo.Git("branch", "feature")
+
+		out, err = runGitWt(t, binPath, repo.Root, "--restore", "feature")
+		if err == nil {
+			t.Fatalf("restore should fail when the branch points elsewhere, got: %s", out)
+		}
+		if !strings.Contains(out, "already exists") {
+			t.Errorf("error should mention the existing branch, got: %s", out)
+		}
+		assertWorktreeDeleted(t, wtPath)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		repo := testutil.NewTestRepo(t)
+		repo.CreateFile("README.md", "# Test")
+		repo.Commit("initial commit")
+
+		if out, err := runGitWt(t, binPath, repo.Root, "--restore", "missing"); err == nil || !strings.Contains(out, "no trash entry") {
+			t.Errorf("restore of unknown entry should fail, got: %v: %s", err, out)
+		}
+		if out, err := runGitWt(t, binPath, repo.Root, "--expire-trash", "soon"); err == nil || !strings.Contains(out, "invalid age") {
+			t.Errorf("invalid age should fail, got: %v: %s", err, out)
+		}
+	})
+}
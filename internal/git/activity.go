@@ -0,0 +1,105 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorktreeActivity holds the signals of the last activity in a worktree.
+// Zero times mean the signal is not available.
+type WorktreeActivity struct {
+	LastCommit   time.Time // committer date of HEAD
+	LastModified time.Time // newest mtime of tracked and untracked (not ignored) files
+	LastSwitch   time.Time // last switch to the worktree with git wt
+}
+
+// Last returns the most recent of all activity signals.
+func (a *WorktreeActivity) Last() time.Time {
+	last := a.LastCommit
+	for _, t := range []time.Time{a.LastModified, a.LastSwitch} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// GetWorktreeActivity returns the activity signals of the worktree at path.
+// lastSwitch is taken from the switch history (see SwitchHistory).
+func GetWorktreeActivity(ctx context.Context, path string, lastSwitch time.Time) (*WorktreeActivity, error) {
+	a := &WorktreeActivity{LastSwitch: lastSwitch}
+
+	cmd, err := gitCommand(ctx, "log", "-1", "--format=%ct", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = path
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	if sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+		a.LastCommit = time.Unix(sec, 0)
+	}
+
+	cmd, err = gitCommand(ctx, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = path
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(path, name))
+		if err != nil {
+			continue // deleted files have no mtime
+		}
+		if info.ModTime().After(a.LastModified) {
+			a.LastModified = info.ModTime()
+		}
+	}
+	return a, nil
+}
+
+// WorktreeActivities returns the activity of each worktree, in the same order
+// as worktrees. Worktrees are inspected concurrently. Entries are nil for bare
+// and prunable worktrees, and for worktrees whose activity cannot be read.
+func WorktreeActivities(ctx context.Context, worktrees []Worktree) []*WorktreeActivity {
+	switches := make(map[string]time.Time)
+	if history, err := SwitchHistory(ctx); err == nil {
+		for _, e := range history {
+			switches[e.Path] = e.SwitchedAt
+		}
+	}
+
+	activities := make([]*WorktreeActivity, len(worktrees))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		if wt.Bare || wt.Prunable {
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			a, err := GetWorktreeActivity(ctx, wt.Path, switches[filepath.Clean(wt.Path)])
+			if err != nil {
+				return
+			}
+			activities[i] = a
+		})
+	}
+	wg.Wait()
+	return activities
+}
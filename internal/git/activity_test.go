@@ -0,0 +1,58 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestGetWorktreeActivity(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Git("add", "README.md")
+	committed := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	t.Setenv("GIT_COMMITTER_DATE", committed.Format(time.RFC3339))
+	repo.Git("commit", "-m", "initial commit")
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(repo.Root, "README.md"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	switched := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	restore := repo.Chdir()
+	defer restore()
+
+	a, err := GetWorktreeActivity(t.Context(), repo.Root, switched)
+	if err != nil {
+		t.Fatalf("GetWorktreeActivity failed: %v", err)
+	}
+	if !a.LastCommit.Equal(committed) {
+		t.Errorf("LastCommit = %v, want %v", a.LastCommit, committed)
+	}
+	if !a.LastModified.Equal(old) {
+		t.Errorf("LastModified = %v, want %v", a.LastModified, old)
+	}
+	if !a.Last().Equal(switched) {
+		t.Errorf("Last() = %v, want the switch time %v", a.Last(), switched)
+	}
+
+	// Untracked files count as activity; ignored files do not.
+	repo.CreateFile(".gitignore", "*.log\n")
+	newer := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(repo.Root, ".gitignore"), newer, newer); err != nil {
+		t.Fatal(err)
+	}
+	repo.CreateFile("debug.log", "ignored")
+
+	a, err = GetWorktreeActivity(t.Context(), repo.Root, time.Time{})
+	if err != nil {
+		t.Fatalf("GetWorktreeActivity failed: %v", err)
+	}
+	if !a.LastModified.Equal(newer) {
+		t.Errorf("LastModified = %v, want %v (ignored files must not count)", a.LastModified, newer)
+	}
+}
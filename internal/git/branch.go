@@ -64,6 +64,16 @@ func DeleteBranch(ctx context.Context, name string, force bool) error {
 // DeleteBranchInDir deletes a branch from a specific directory.
 // If dir is empty, uses current directory.
 func DeleteBranchInDir(ctx context.Context, name string, force bool, dir string) error {
+	return deleteBranch(ctx, name, force, dir, false)
+}
+
+// DeleteBranchQuietly deletes a branch like DeleteBranchInDir, without git's
+// "Deleted branch" message on stdout.
+func DeleteBranchQuietly(ctx context.Context, name string, force bool, dir string) error {
+	return deleteBranch(ctx, name, force, dir, true)
+}
+
+func deleteBranch(ctx context.Context, name string, force bool, dir string, quiet bool) error {
 	flag := "-d"
 	if force {
 		flag = "-D"
@@ -72,7 +82,11 @@ func DeleteBranchInDir(ctx context.Context, name string, force bool, dir string)
 	if dir != "" {
 		args = append(args, "-C", dir)
 	}
-	args = append(args, "branch", flag, name)
+	args = append(args, "branch", flag)
+	if quiet {
+		args = append(args, "--quiet")
+	}
+	args = append(args, name)
 	cmd, err := gitCommand(ctx, args...)
 	if err != nil {
 		return err
@@ -128,28 +142,191 @@ func RenameBranch(ctx context.Context, oldName, newName string, force bool, dir
 	return cmd.Run()
 }
 
-// IsBranchMerged checks if a branch is merged into the current branch.
+// IsBranchMerged checks if a branch is merged into the default branch,
+// either by ancestry (see MergedBranches) or because its changes were
+// squash- or rebase-merged (see IsBranchIntegrated). Unlike 'git branch -d',
+// the result does not depend on which branch is checked out in the current
+// worktree.
 func IsBranchMerged(ctx context.Context, name string) (bool, error) {
-	cmd, err := gitCommand(ctx, "branch", "--merged")
+	merged, err := MergedBranches(ctx)
 	if err != nil {
 		return false, err
 	}
-	out, err := cmd.Output()
+	if _, ok := merged[name]; ok {
+		return true, nil
+	}
+	return IsBranchIntegrated(ctx, name)
+}
+
+// IsBranchIntegrated checks if the changes of a branch are already contained
+// in the default branch even though its tip is not reachable from it, as
+// happens when a pull request is squash- or rebase-merged. A branch is
+// considered integrated if every commit has an equivalent (same patch-id)
+// commit on the default branch, or if merging it into the default branch
+// would not change the default branch's tree.
+func IsBranchIntegrated(ctx context.Context, name string) (bool, error) {
+	targets, err := defaultBranchRefs(ctx)
 	if err != nil {
 		return false, err
 	}
-
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		// Remove leading * and spaces
-		branch := strings.TrimSpace(strings.TrimPrefix(line, "*"))
-		if branch == name {
+	for _, target := range targets {
+		ok, err := isCherryPicked(ctx, target, name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+		ok, err = mergeKeepsTree(ctx, target, name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
 			return true, nil
 		}
 	}
 	return false, nil
 }
 
+// HasOwnCommits reports whether a branch has commits made on it since it was
+// created, as opposed to a branch just created for a new worktree, whose tip
+// is trivially reachable from the default branch. It has none if its reflog
+// only records its creation. Without a reflog (e.g., in bare repositories), a
+// branch has none if it points at the tip of the default branch.
+func HasOwnCommits(ctx context.Context, name string) (bool, error) {
+	cmd, err := gitCommand(ctx, "reflog", "show", "--format=%gs", "refs/heads/"+name, "--")
+	if err != nil {
+		return false, err
+	}
+	if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		entries := strings.Split(strings.TrimSpace(string(out)), "\n")
+		return len(entries) > 1 || !strings.HasPrefix(entries[0], "branch: Created from "), nil
+	}
+
+	tip, err := revParse(ctx, "", "refs/heads/"+name)
+	if err != nil {
+		return false, err
+	}
+	targets, err := defaultBranchRefs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, target := range targets {
+		targetTip, err := revParse(ctx, "", target)
+		if err != nil {
+			return false, err
+		}
+		if tip == targetTip {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isCherryPicked reports whether every commit of branch that is not on target
+// has an equivalent commit on target, as determined by 'git cherry'
+// (rebase merges).
+func isCherryPicked(ctx context.Context, target, branch string) (bool, error) {
+	cmd, err := gitCommand(ctx, "cherry", target, branch)
+	if err != nil {
+		return false, err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if lines[0] == "" {
+		return false, nil
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "+") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// mergeKeepsTree reports whether merging branch into target would leave the
+// tree of target unchanged, i.e., all changes of branch are already in target
+// (squash merges). It uses 'git merge-tree --write-tree', which does not touch
+// any worktree or ref; conflicts and Git versions without --write-tree
+// (before 2.38) are reported as false.
+func mergeKeepsTree(ctx context.Context, target, branch string) (bool, error) {
+	cmd, err := gitCommand(ctx, "merge-tree", "--write-tree", target, branch)
+	if err != nil {
+		return false, err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		// Conflicts (exit status 1) or --write-tree is not supported
+		return false, nil
+	}
+	merged, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+
+	cmd, err = gitCommand(ctx, "rev-parse", target+"^{tree}")
+	if err != nil {
+		return false, err
+	}
+	out, err = cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return merged == strings.TrimSpace(string(out)), nil
+}
+
+// MergedBranches returns the set of local branches whose tips are reachable
+// from the default branch, either the local branch or its remote-tracking
+// counterpart on origin (so branches merged on the remote are detected even
+// before the local default branch is updated). The default branch itself is
+// included.
+func MergedBranches(ctx context.Context) (map[string]struct{}, error) {
+	targets, err := defaultBranchRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]struct{})
+	for _, target := range targets {
+		cmd, err := gitCommand(ctx, "for-each-ref", "--merged="+target, "--format=%(refname:short)", "refs/heads")
+		if err != nil {
+			return nil, err
+		}
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if line != "" {
+				merged[line] = struct{}{}
+			}
+		}
+	}
+	return merged, nil
+}
+
+// defaultBranchRefs returns the existing refs of the default branch: the
+// local branch and/or its remote-tracking branch on origin.
+func defaultBranchRefs(ctx context.Context) ([]string, error) {
+	defaultBranch, err := DefaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, ref := range []string{"refs/heads/" + defaultBranch, "refs/remotes/origin/" + defaultBranch} {
+		cmd, err := gitCommand(ctx, "show-ref", "--verify", "--quiet", ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Run(); err == nil {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("default branch %q not found", defaultBranch)
+	}
+	return refs, nil
+}
+
 // ListBranches returns a list of all local branch names.
 func ListBranches(ctx context.Context) ([]string, error) {
 	cmd, err := gitCommand(ctx, "branch", "--format=%(refname:short)")
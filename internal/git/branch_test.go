@@ -284,6 +284,195 @@ func TestIsBranchMerged(t *testing.T) {
 	}
 }
 
+func TestHasOwnCommits(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	// Create and fast-forward merge a branch
+	repo.Git("checkout", "-b", "merged-branch")
+	repo.CreateFile("merged.txt", "merged content")
+	repo.Commit("commit on merged branch")
+	repo.Git("checkout", "main")
+	repo.Git("merge", "merged-branch")
+
+	// Create branches without commits
+	repo.Git("branch", "new-at-tip")
+	repo.Git("branch", "new-behind", "main~1")
+
+	// Without a reflog, only the tip of the default branch counts
+	repo.Git("branch", "no-reflog-at-tip")
+	repo.Git("branch", "no-reflog-behind", "main~1")
+	repo.Git("reflog", "delete", "--updateref", "refs/heads/no-reflog-at-tip@{0}")
+	repo.Git("reflog", "delete", "--updateref", "refs/heads/no-reflog-behind@{0}")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	tests := []struct {
+		name   string
+		branch string
+		want   bool
+	}{
+		{"merged branch", "merged-branch", true},
+		{"new branch at the default branch", "new-at-tip", false},
+		{"new branch behind the default branch", "new-behind", false},
+		{"no reflog, at the default branch", "no-reflog-at-tip", false},
+		{"no reflog, behind the default branch", "no-reflog-behind", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HasOwnCommits(t.Context(), tt.branch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasOwnCommits(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBranchMerged_FromFeatureBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	// merged-branch is merged into main
+	repo.Git("checkout", "-b", "merged-branch")
+	repo.CreateFile("merged.txt", "merged content")
+	repo.Commit("commit on merged branch")
+	repo.Git("checkout", "main")
+	repo.Git("merge", "merged-branch")
+
+	// feature contains a commit that is not on main; check it out so that
+	// HEAD is not the default branch
+	repo.Git("checkout", "-b", "feature")
+	repo.CreateFile("feature.txt", "feature content")
+	repo.Commit("commit on feature")
+	repo.Git("branch", "derived")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{"merged-branch", true},
+		{"main", true},
+		// merged into HEAD (feature) but not into the default branch
+		{"derived", false},
+		{"feature", false},
+	}
+	for _, tt := range tests {
+		got, err := IsBranchMerged(t.Context(), tt.branch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("IsBranchMerged(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestMergedBranches_RemoteDefaultBranch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	// Simulate a branch merged on the remote while local main is stale:
+	// origin/main contains the branch, local main does not.
+	repo.Git("checkout", "-b", "merged-on-remote")
+	repo.CreateFile("remote.txt", "remote content")
+	repo.Commit("commit merged on remote")
+	repo.Git("update-ref", "refs/remotes/origin/main", "merged-on-remote")
+	repo.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	repo.Git("checkout", "main")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	merged, err := MergedBranches(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := merged["merged-on-remote"]; !ok {
+		t.Errorf("merged-on-remote should be detected as merged via origin/main, got %v", merged)
+	}
+}
+
+func TestIsBranchIntegrated(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	// squashed: two commits squash-merged into main
+	repo.Git("checkout", "-b", "squashed")
+	repo.CreateFile("squash1.txt", "squash 1")
+	repo.Commit("first commit on squashed")
+	repo.CreateFile("squash2.txt", "squash 2")
+	repo.Commit("second commit on squashed")
+
+	// rebased: a commit cherry-picked onto main
+	repo.Git("checkout", "-b", "rebased", "main")
+	repo.CreateFile("rebase.txt", "rebase")
+	repo.Commit("commit on rebased")
+
+	// partial: only one of two commits made it into main
+	repo.Git("checkout", "-b", "partial", "main")
+	repo.CreateFile("partial1.txt", "partial 1")
+	repo.Commit("first commit on partial")
+	repo.CreateFile("partial2.txt", "partial 2")
+	repo.Commit("second commit on partial")
+
+	// unmerged: never merged
+	repo.Git("checkout", "-b", "unmerged", "main")
+	repo.CreateFile("unmerged.txt", "unmerged")
+	repo.Commit("commit on unmerged")
+
+	repo.Git("checkout", "main")
+	// Advance main first so that neither merge is a fast-forward.
+	repo.CreateFile("main.txt", "main")
+	repo.Commit("commit on main")
+	repo.Git("merge", "--squash", "squashed")
+	repo.Commit("squash merge")
+	repo.Git("cherry-pick", "rebased")
+	repo.Git("cherry-pick", "partial~1")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{"squashed", true},
+		{"rebased", true},
+		{"partial", false},
+		{"unmerged", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got, err := IsBranchIntegrated(t.Context(), tt.branch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBranchIntegrated(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+			merged, err := IsBranchMerged(t.Context(), tt.branch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if merged != tt.want {
+				t.Errorf("IsBranchMerged(%q) = %v, want %v", tt.branch, merged, tt.want)
+			}
+		})
+	}
+}
+
 func TestDefaultBranch(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.CreateFile("README.md", "# Test")
@@ -3,42 +3,71 @@ package git
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/k1LoW/exec"
 )
 
 const (
-	configKeyBaseDir       = "wt.basedir"
-	configKeyCopyIgnored   = "wt.copyignored"
-	configKeyCopyUntracked = "wt.copyuntracked"
-	configKeyCopyModified  = "wt.copymodified"
-	configKeyNoCopy        = "wt.nocopy"
-	configKeyCopy          = "wt.copy"
-	configKeyHook          = "wt.hook"
-	configKeyDeleteHook    = "wt.deletehook"
-	configKeyRemover       = "wt.remover"
-	configKeySymlink       = "wt.symlink"
-	configKeyNoCd          = "wt.nocd"
-	configKeyRelative      = "wt.relative"
+	configKeyBaseDir        = "wt.basedir"
+	configKeyCopyIgnored    = "wt.copyignored"
+	configKeyCopyUntracked  = "wt.copyuntracked"
+	configKeyCopyModified   = "wt.copymodified"
+	configKeyNoCopy         = "wt.nocopy"
+	configKeyCopy           = "wt.copy"
+	configKeyHook           = "wt.hook"
+	configKeyDeleteHook     = "wt.deletehook"
+	configKeyPreCreateHook  = "wt.precreatehook"
+	configKeySwitchHook     = "wt.switchhook"
+	configKeyMoveHook       = "wt.movehook"
+	configKeyPostDeleteHook = "wt.postdeletehook"
+	configKeyHookMode       = "wt.hookmode"
+	configKeyHookTimeout    = "wt.hooktimeout"
+	configKeyRemover        = "wt.remover"
+	configKeySymlink        = "wt.symlink"
+	configKeyNoCd           = "wt.nocd"
+	configKeyRelative       = "wt.relative"
+	configKeyListFormat     = "wt.listformat"
+	configKeyInteractive    = "wt.interactive"
+	configKeyMultiplexer    = "wt.multiplexer"
+	configKeyEditor         = "wt.editor"
+	configKeyPromptFormat   = "wt.promptformat"
+	configKeyTrashExpire    = "wt.trashexpire"
+	configKeyAbbrev         = "wt.abbrev"
 )
 
 // Config holds all wt configuration values.
 type Config struct {
-	BaseDir       string
-	CopyIgnored   bool
-	CopyUntracked bool
-	CopyModified  bool
-	NoCopy        []string
-	Copy          []string
-	Symlink       []string
-	Hooks         []string
-	DeleteHooks   []string
-	Remover       string
-	NoCd          bool
-	Relative      bool
+	BaseDir         string
+	CopyIgnored     bool
+	CopyUntracked   bool
+	CopyModified    bool
+	NoCopy          []string
+	Copy            []string
+	Symlink         []string
+	Hooks           []string
+	DeleteHooks     []string
+	PreCreateHooks  []string
+	SwitchHooks     []string
+	MoveHooks       []string
+	PostDeleteHooks []string
+	HookMode        string
+	HookTimeout     time.Duration
+	Remover         string
+	NoCd            bool
+	Relative        bool
+	ListFormat      string
+	Interactive     bool
+	Multiplexer     string
+	Editor          string
+	PromptFormat    string
+	TrashExpire     string // age of trash entries dropped on delete, or "never"
+	Abbrev          bool   // resolve targets that abbreviate a single worktree
 }
 
 // GitConfig retrieves all git config values for a key.
@@ -63,122 +92,125 @@ func GitConfig(ctx context.Context, key string) ([]string, error) { //nolint:rev
 	return strings.Split(trimmed, "\n"), nil
 }
 
-// LoadConfig loads configuration from git config with default values.
-func LoadConfig(ctx context.Context) (Config, error) {
-	cfg := Config{}
-
-	// BaseDir
-	baseDir, err := GitConfig(ctx, configKeyBaseDir)
-	if err != nil {
-		return cfg, err
-	}
-	if len(baseDir) == 0 {
-		cfg.BaseDir = ".wt"
-	} else {
-		cfg.BaseDir = baseDir[len(baseDir)-1]
-	}
-
-	// CopyIgnored
-	val, err := GitConfig(ctx, configKeyCopyIgnored)
+// wtConfig retrieves the values of all wt.* keys with a single git process,
+// so that loading the configuration stays fast (e.g., for --prompt). Keys are
+// lower-cased, as git config keys are case-insensitive.
+func wtConfig(ctx context.Context) (map[string][]string, error) {
+	cmd, err := gitCommand(ctx, "config", "-z", "--get-regexp", `^wt\.`)
 	if err != nil {
-		return cfg, err
-	}
-	cfg.CopyIgnored = len(val) > 0 && val[len(val)-1] == "true"
-
-	// CopyUntracked
-	val, err = GitConfig(ctx, configKeyCopyUntracked)
-	if err != nil {
-		return cfg, err
-	}
-	cfg.CopyUntracked = len(val) > 0 && val[len(val)-1] == "true"
-
-	// CopyModified
-	val, err = GitConfig(ctx, configKeyCopyModified)
-	if err != nil {
-		return cfg, err
+		return nil, err
 	}
-	cfg.CopyModified = len(val) > 0 && val[len(val)-1] == "true"
-
-	// NoCopy
-	noCopy, err := GitConfig(ctx, configKeyNoCopy)
+	out, err := cmd.Output()
 	if err != nil {
-		return cfg, err
+		// git config returns exit code 1 if no key matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return map[string][]string{}, nil
+		}
+		return nil, err
 	}
-	cfg.NoCopy = noCopy
-
-	// Copy
-	copyPatterns, err := GitConfig(ctx, configKeyCopy)
-	if err != nil {
-		return cfg, err
+	values := make(map[string][]string)
+	// With -z, each entry is "<key>\n<value>\x00" ("<key>\x00" for a key
+	// without a value).
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "\n")
+		key = strings.ToLower(key)
+		values[key] = append(values[key], value)
 	}
-	cfg.Copy = copyPatterns
+	return values, nil
+}
 
-	// Symlink
-	symlinkPatterns, err := GitConfig(ctx, configKeySymlink)
-	if err != nil {
-		return cfg, err
+// lastValue returns the last of values (the one that takes effect for
+// single-valued keys), or def if there is none.
+func lastValue(values []string, def string) string {
+	if len(values) == 0 {
+		return def
 	}
-	cfg.Symlink = symlinkPatterns
+	return values[len(values)-1]
+}
 
-	// Hooks
-	hooks, err := GitConfig(ctx, configKeyHook)
+// LoadConfig loads configuration from git config with default values.
+func LoadConfig(ctx context.Context) (Config, error) {
+	values, err := wtConfig(ctx)
 	if err != nil {
-		return cfg, err
+		return Config{}, err
 	}
-	cfg.Hooks = hooks
-
-	// DeleteHooks
-	deleteHooks, err := GitConfig(ctx, configKeyDeleteHook)
+	hookTimeout, err := ParseHookTimeout(lastValue(values[configKeyHookTimeout], ""))
 	if err != nil {
-		return cfg, err
+		return Config{}, fmt.Errorf("invalid %s: %w", configKeyHookTimeout, err)
 	}
-	cfg.DeleteHooks = deleteHooks
+	return Config{
+		BaseDir:         lastValue(values[configKeyBaseDir], ".wt"),
+		CopyIgnored:     lastValue(values[configKeyCopyIgnored], "") == "true",
+		CopyUntracked:   lastValue(values[configKeyCopyUntracked], "") == "true",
+		CopyModified:    lastValue(values[configKeyCopyModified], "") == "true",
+		NoCopy:          values[configKeyNoCopy],
+		Copy:            values[configKeyCopy],
+		Symlink:         values[configKeySymlink],
+		Hooks:           values[configKeyHook],
+		DeleteHooks:     values[configKeyDeleteHook],
+		PreCreateHooks:  values[configKeyPreCreateHook],
+		SwitchHooks:     values[configKeySwitchHook],
+		MoveHooks:       values[configKeyMoveHook],
+		PostDeleteHooks: values[configKeyPostDeleteHook],
+		HookMode:        lastValue(values[configKeyHookMode], HookModeSerial),
+		HookTimeout:     hookTimeout,
+		Remover:         lastValue(values[configKeyRemover], ""),
+		NoCd:            lastValue(values[configKeyNoCd], "") == "true",
+		Relative:        lastValue(values[configKeyRelative], "") == "true",
+		ListFormat:      lastValue(values[configKeyListFormat], ""),
+		Interactive:     lastValue(values[configKeyInteractive], "") == "true",
+		Multiplexer:     lastValue(values[configKeyMultiplexer], ""),
+		Editor:          lastValue(values[configKeyEditor], ""),
+		PromptFormat:    lastValue(values[configKeyPromptFormat], ""),
+		TrashExpire:     lastValue(values[configKeyTrashExpire], "30d"),
+		Abbrev:          lastValue(values[configKeyAbbrev], "") == "true",
+	}, nil
+}
 
-	// Remover
-	remover, err := GitConfig(ctx, configKeyRemover)
-	if err != nil {
-		return cfg, err
-	}
-	if len(remover) > 0 {
-		cfg.Remover = remover[len(remover)-1]
+// ParseHookTimeout parses a hook timeout: a duration such as "90s" or "5m",
+// or a number of seconds. Empty and zero mean no timeout.
+func ParseHookTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
 	}
-
-	// NoCd
-	val, err = GitConfig(ctx, configKeyNoCd)
+	d, err := time.ParseDuration(s)
 	if err != nil {
-		return cfg, err
+		n, nerr := strconv.Atoi(s)
+		if nerr != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = time.Duration(n) * time.Second
 	}
-	cfg.NoCd = len(val) > 0 && val[len(val)-1] == "true"
-
-	// Relative
-	val, err = GitConfig(ctx, configKeyRelative)
-	if err != nil {
-		return cfg, err
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", s)
 	}
-	cfg.Relative = len(val) > 0 && val[len(val)-1] == "true"
-
-	return cfg, nil
+	return d, nil
 }
 
-// expandTemplate expands template variables in a string.
+// expandTemplate expands template variables in a string for the repository
+// whose main root is mainRoot.
 // Supported variables:
 //   - {gitroot}: repository root directory name
-func expandTemplate(ctx context.Context, s string) (string, error) {
-	// Expand {gitroot}
-	if strings.Contains(s, "{gitroot}") {
-		repoName, err := RepoName(ctx)
-		if err != nil {
-			return "", err
-		}
-		s = strings.ReplaceAll(s, "{gitroot}", repoName)
-	}
-
-	return s, nil
+func expandTemplate(s, mainRoot string) string {
+	return strings.ReplaceAll(s, "{gitroot}", filepath.Base(mainRoot))
 }
 
 // ExpandPath expands ~ to home directory and resolves relative paths.
 // Relative paths are resolved from the main repository root, not the current worktree.
 func ExpandPath(ctx context.Context, path string) (string, error) {
+	repoRoot, err := MainRepoRoot(ctx)
+	if err != nil {
+		return "", err
+	}
+	return expandPathAt(path, repoRoot)
+}
+
+// expandPathAt is ExpandPath with relative paths resolved from mainRoot.
+func expandPathAt(path, mainRoot string) (string, error) {
 	// Expand ~
 	if strings.HasPrefix(path, "~/") {
 		home, err := os.UserHomeDir()
@@ -195,29 +227,23 @@ func ExpandPath(ctx context.Context, path string) (string, error) {
 		return filepath.Clean(path), nil
 	}
 
-	// Resolve relative path from main repo root (not current worktree)
-	repoRoot, err := MainRepoRoot(ctx)
-	if err != nil {
-		return "", err
-	}
-	return filepath.Clean(filepath.Join(repoRoot, path)), nil
+	return filepath.Clean(filepath.Join(mainRoot, path)), nil
 }
 
 // ExpandBaseDir expands template variables and path for the given base directory pattern.
 func ExpandBaseDir(ctx context.Context, baseDir string) (string, error) {
-	// Expand template variables
-	expanded, err := expandTemplate(ctx, baseDir)
-	if err != nil {
-		return "", err
-	}
-
-	// Expand path (~ and relative paths)
-	expanded, err = ExpandPath(ctx, expanded)
+	mainRoot, err := MainRepoRoot(ctx)
 	if err != nil {
 		return "", err
 	}
+	return expandBaseDirAt(baseDir, mainRoot)
+}
 
-	return expanded, nil
+// expandBaseDirAt is ExpandBaseDir for the repository whose main root is
+// mainRoot. It does not run git, for the prompt, which gets mainRoot along
+// with the rest of its status.
+func expandBaseDirAt(baseDir, mainRoot string) (string, error) {
+	return expandPathAt(expandTemplate(baseDir, mainRoot), mainRoot)
 }
 
 // IsBaseDirConfigured checks if wt.basedir is explicitly configured in git config.
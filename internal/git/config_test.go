@@ -3,7 +3,9 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/k1LoW/git-wt/testutil"
 )
@@ -127,6 +129,201 @@ func TestLoadConfig(t *testing.T) {
 	if !cfg.NoCd {
 		t.Errorf("LoadConfig().NoCd = %v, want true", cfg.NoCd)
 	}
+
+	// Test ListFormat setting
+	if cfg.ListFormat != "" {
+		t.Errorf("LoadConfig().ListFormat default = %q, want empty", cfg.ListFormat)
+	}
+	repo.Git("config", "wt.listformat", "{{.Branch}}")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.ListFormat != "{{.Branch}}" {
+		t.Errorf("LoadConfig().ListFormat = %q, want %q", cfg.ListFormat, "{{.Branch}}")
+	}
+
+	// Test Interactive setting
+	if cfg.Interactive {
+		t.Errorf("LoadConfig().Interactive default = %v, want false", cfg.Interactive)
+	}
+	repo.Git("config", "wt.interactive", "true")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.Interactive {
+		t.Errorf("LoadConfig().Interactive = %v, want true", cfg.Interactive)
+	}
+
+	// Test Multiplexer setting
+	if cfg.Multiplexer != "" {
+		t.Errorf("LoadConfig().Multiplexer default = %q, want empty", cfg.Multiplexer)
+	}
+	repo.Git("config", "wt.multiplexer", "tmux")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Multiplexer != "tmux" {
+		t.Errorf("LoadConfig().Multiplexer = %q, want %q", cfg.Multiplexer, "tmux")
+	}
+
+	// Test Editor setting
+	if cfg.Editor != "" {
+		t.Errorf("LoadConfig().Editor default = %q, want empty", cfg.Editor)
+	}
+	repo.Git("config", "wt.editor", "code --reuse-window {path}")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Editor != "code --reuse-window {path}" {
+		t.Errorf("LoadConfig().Editor = %q, want %q", cfg.Editor, "code --reuse-window {path}")
+	}
+
+	// Test PromptFormat setting
+	if cfg.PromptFormat != "" {
+		t.Errorf("LoadConfig().PromptFormat default = %q, want empty", cfg.PromptFormat)
+	}
+	repo.Git("config", "wt.promptformat", "{{.Name}}")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.PromptFormat != "{{.Name}}" {
+		t.Errorf("LoadConfig().PromptFormat = %q, want %q", cfg.PromptFormat, "{{.Name}}")
+	}
+
+	// Test TrashExpire setting
+	if cfg.TrashExpire != "30d" {
+		t.Errorf("LoadConfig().TrashExpire default = %q, want %q", cfg.TrashExpire, "30d")
+	}
+	repo.Git("config", "wt.trashexpire", "never")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.TrashExpire != "never" {
+		t.Errorf("LoadConfig().TrashExpire = %q, want %q", cfg.TrashExpire, "never")
+	}
+
+	// Test Abbrev setting
+	if cfg.Abbrev {
+		t.Error("LoadConfig().Abbrev default = true, want false")
+	}
+	repo.Git("config", "wt.abbrev", "true")
+
+	cfg, err = LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.Abbrev {
+		t.Error("LoadConfig().Abbrev = false, want true")
+	}
+}
+
+func TestLoadConfig_SingleProcess(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+	// All wt.* keys are read at once: keys are case-insensitive, and values
+	// may span lines.
+	repo.Git("config", "wt.copyIgnored", "true")
+	repo.Git("config", "--add", "wt.hook", "echo one\necho two")
+	repo.Git("config", "--add", "wt.hook", "echo three")
+	repo.Git("config", "wt.remover", "")
+	repo.Git("config", "wt.preCreateHook", "echo pre")
+	repo.Git("config", "wt.switchhook", "echo switch")
+	repo.Git("config", "wt.movehook", "echo move")
+	repo.Git("config", "wt.hookTimeout", "90")
+	repo.Git("config", "--add", "wt.postdeletehook", "echo post1")
+	repo.Git("config", "--add", "wt.postdeletehook", "echo post2")
+	repo.Git("config", "other.basedir", "ignored")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	cfg, err := LoadConfig(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.CopyIgnored {
+		t.Error("LoadConfig().CopyIgnored = false, want true for wt.copyIgnored")
+	}
+	if want := []string{"echo one\necho two", "echo three"}; !slices.Equal(cfg.Hooks, want) {
+		t.Errorf("LoadConfig().Hooks = %q, want %q", cfg.Hooks, want)
+	}
+	if want := []string{"echo pre"}; !slices.Equal(cfg.PreCreateHooks, want) {
+		t.Errorf("LoadConfig().PreCreateHooks = %q, want %q", cfg.PreCreateHooks, want)
+	}
+	if want := []string{"echo switch"}; !slices.Equal(cfg.SwitchHooks, want) {
+		t.Errorf("LoadConfig().SwitchHooks = %q, want %q", cfg.SwitchHooks, want)
+	}
+	if want := []string{"echo move"}; !slices.Equal(cfg.MoveHooks, want) {
+		t.Errorf("LoadConfig().MoveHooks = %q, want %q", cfg.MoveHooks, want)
+	}
+	if want := []string{"echo post1", "echo post2"}; !slices.Equal(cfg.PostDeleteHooks, want) {
+		t.Errorf("LoadConfig().PostDeleteHooks = %q, want %q", cfg.PostDeleteHooks, want)
+	}
+	if cfg.Remover != "" {
+		t.Errorf("LoadConfig().Remover = %q, want empty", cfg.Remover)
+	}
+	if cfg.BaseDir != ".wt" {
+		t.Errorf("LoadConfig().BaseDir = %q, want %q", cfg.BaseDir, ".wt")
+	}
+	if cfg.HookMode != HookModeSerial {
+		t.Errorf("LoadConfig().HookMode = %q, want %q", cfg.HookMode, HookModeSerial)
+	}
+	if cfg.HookTimeout != 90*time.Second {
+		t.Errorf("LoadConfig().HookTimeout = %s, want 1m30s", cfg.HookTimeout)
+	}
+
+	repo.Git("config", "wt.hooktimeout", "soon")
+	if _, err := LoadConfig(t.Context()); err == nil {
+		t.Error("LoadConfig() should fail for an invalid wt.hooktimeout")
+	}
+}
+
+func TestParseHookTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "30", want: 30 * time.Second},
+		{in: "90s", want: 90 * time.Second},
+		{in: "5m", want: 5 * time.Minute},
+		{in: "-1s", wantErr: true},
+		{in: "-5", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseHookTimeout(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHookTimeout(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHookTimeout(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
 }
 
 func TestExpandPath(t *testing.T) {
@@ -319,3 +516,33 @@ func TestSetConfig(t *testing.T) {
 		t.Errorf("GitConfig() = %v, want [../test-wt]", values)
 	}
 }
+
+func TestExpandBaseDirAt(t *testing.T) {
+	// No repository is needed, as the main root is given.
+	mainRoot := filepath.Join(t.TempDir(), "repo")
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("failed to get home dir: %v", err)
+	}
+
+	tests := []struct {
+		baseDir string
+		want    string
+	}{
+		{".wt", filepath.Join(mainRoot, ".wt")},
+		{"../{gitroot}-wt", filepath.Join(filepath.Dir(mainRoot), "repo-wt")},
+		{"/tmp/{gitroot}", "/tmp/repo"},
+		{"~/worktrees/{gitroot}", filepath.Join(homeDir, "worktrees", "repo")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseDir, func(t *testing.T) {
+			got, err := expandBaseDirAt(tt.baseDir, mainRoot)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandBaseDirAt(%q) = %q, want %q", tt.baseDir, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of problems reported by Diagnose.
+const (
+	// ProblemDanglingRegistration is a registered worktree whose directory is
+	// missing. It is fixed by 'git worktree prune'.
+	ProblemDanglingRegistration = "dangling_registration"
+	// ProblemMovedWorktree is a worktree directory that was moved without
+	// 'git worktree move', so its registration still points to the old path.
+	// It is fixed by 'git worktree repair'.
+	ProblemMovedWorktree = "moved_worktree"
+	// ProblemOrphanedWorktree is a worktree directory of this repository whose
+	// registration is gone (e.g., after a failed wt.remover). It can only be
+	// removed.
+	ProblemOrphanedWorktree = "orphaned_worktree"
+	// ProblemEmptyDirectory is a directory under basedir that holds nothing
+	// but empty directories and untouched basedir decoration files.
+	ProblemEmptyDirectory = "empty_directory"
+	// ProblemUnknownDirectory is a directory under basedir that is neither a
+	// worktree nor empty. It is reported but never removed.
+	ProblemUnknownDirectory = "unknown_directory"
+)
+
+// Problem is an inconsistency between basedir and the registered worktrees.
+type Problem struct {
+	Kind       string `json:"kind"`
+	Path       string `json:"path"`
+	OldPath    string `json:"old_path,omitempty"` // registered path of a moved worktree
+	Branch     string `json:"branch,omitempty"`
+	Locked     bool   `json:"locked,omitempty"`
+	LockReason string `json:"lock_reason,omitempty"`
+}
+
+// Diagnose cross-references the directories under baseDir with the
+// registered worktrees. It reports registrations whose directory is missing
+// (anywhere, not only under baseDir), and directories under baseDir that are
+// not registered worktrees. Directories holding worktrees or repositories of
+// other repositories are ignored, so a basedir shared between repositories
+// is safe to inspect.
+func Diagnose(ctx context.Context, baseDir string) ([]Problem, error) {
+	worktrees, err := ListWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, gitCommonDir, err := gitDirs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	adminDir := resolvePath(filepath.Join(gitCommonDir, "worktrees"))
+
+	registered := make(map[string]Worktree)
+	for _, wt := range worktrees {
+		registered[resolvePath(wt.Path)] = wt
+	}
+
+	var problems []Problem
+	moved := make(map[string]struct{}) // registered paths of moved worktrees
+	baseDir = resolvePath(baseDir)
+	if _, err := os.Stat(baseDir); err == nil {
+		err := filepath.WalkDir(baseDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() || path == baseDir {
+				return nil
+			}
+			if _, ok := registered[path]; ok {
+				return filepath.SkipDir
+			}
+			if gitdir, ok := readGitFile(path); ok {
+				if filepath.Dir(gitdir) == adminDir {
+					p := classifyUnregisteredWorktree(path, gitdir, registered)
+					if p.Kind == ProblemMovedWorktree {
+						moved[p.OldPath] = struct{}{}
+					}
+					problems = append(problems, p)
+				}
+				return filepath.SkipDir
+			}
+			if isWorktreeLike(path) {
+				return filepath.SkipDir // a repository of its own
+			}
+			if hasRegisteredDescendant(path, registered) || containsGitEntry(path) {
+				return nil
+			}
+			empty, err := isEmptyTree(path)
+			if err != nil {
+				return err
+			}
+			if empty {
+				problems = append(problems, Problem{Kind: ProblemEmptyDirectory, Path: path})
+			} else {
+				problems = append(problems, Problem{Kind: ProblemUnknownDirectory, Path: path})
+			}
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", baseDir, err)
+		}
+	}
+
+	for _, wt := range worktrees {
+		if !wt.Prunable {
+			continue
+		}
+		if _, ok := moved[resolvePath(wt.Path)]; ok {
+			continue
+		}
+		p := Problem{Kind: ProblemDanglingRegistration, Path: wt.Path, Locked: wt.Locked, LockReason: wt.LockReason}
+		if wt.Branch != DetachedMarker {
+			p.Branch = wt.Branch
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+// RepairWorktree runs 'git worktree repair' for the worktree directory at
+// path, pointing its registration to path.
+func RepairWorktree(ctx context.Context, path string) error {
+	cmd, err := gitCommand(ctx, "worktree", "repair", path)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RemoveOrphanedDir removes the directory at path found by Diagnose, then
+// the parents left empty up to baseDir (see RemoveEmptyParents). An
+// empty_directory is checked again right before removal and kept if files
+// have appeared in it since.
+func RemoveOrphanedDir(p Problem, baseDir string) error {
+	switch p.Kind {
+	case ProblemEmptyDirectory:
+		empty, err := isEmptyTree(p.Path)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return fmt.Errorf("directory %q is no longer empty", p.Path)
+		}
+	case ProblemOrphanedWorktree:
+	default:
+		return fmt.Errorf("cannot remove %s %q", p.Kind, p.Path)
+	}
+	if !isStrictDescendant(p.Path, resolvePath(baseDir)) {
+		return fmt.Errorf("directory %q is not under basedir %q", p.Path, baseDir)
+	}
+	if err := os.RemoveAll(p.Path); err != nil {
+		return err
+	}
+	return RemoveEmptyParents(filepath.Dir(p.Path), resolvePath(baseDir))
+}
+
+// classifyUnregisteredWorktree tells a moved worktree (its administrative
+// directory still exists and is registered for a missing path) from an
+// orphaned one.
+func classifyUnregisteredWorktree(path, gitdir string, registered map[string]Worktree) Problem {
+	b, err := os.ReadFile(filepath.Join(gitdir, "gitdir"))
+	if err == nil {
+		oldPath := resolvePath(filepath.Dir(strings.TrimSpace(string(b))))
+		if wt, ok := registered[oldPath]; ok && wt.Prunable {
+			p := Problem{Kind: ProblemMovedWorktree, Path: path, OldPath: oldPath}
+			if wt.Branch != DetachedMarker {
+				p.Branch = wt.Branch
+			}
+			return p
+		}
+	}
+	return Problem{Kind: ProblemOrphanedWorktree, Path: path}
+}
+
+// readGitFile returns the administrative directory a worktree's .git file
+// points to. ok is false if dir has no .git file.
+func readGitFile(dir string) (gitdir string, ok bool) {
+	b, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return "", false
+	}
+	gitdir, found := strings.CutPrefix(strings.TrimSpace(string(b)), "gitdir: ")
+	if !found {
+		return "", false
+	}
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(dir, gitdir)
+	}
+	return resolvePath(gitdir), true
+}
+
+// isWorktreeLike reports whether dir has a .git entry of any kind.
+func isWorktreeLike(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// containsGitEntry reports whether any directory below dir has a .git entry.
+func containsGitEntry(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		// Unreadable directories are skipped.
+		if err != nil {
+			return nil
+		}
+		if d.Name() == ".git" {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// hasRegisteredDescendant reports whether a registered worktree lies below dir.
+func hasRegisteredDescendant(dir string, registered map[string]Worktree) bool {
+	for path := range registered {
+		if isStrictDescendant(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyTree reports whether dir contains nothing but (recursively) empty
+// directories and untouched basedir decoration files, the same criterion
+// RemoveEmptyParents applies to a single directory.
+func isEmptyTree(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	var files []os.DirEntry
+	for _, e := range entries {
+		if !e.IsDir() {
+			files = append(files, e)
+			continue
+		}
+		empty, err := isEmptyTree(filepath.Join(dir, e.Name()))
+		if err != nil || !empty {
+			return false, err
+		}
+	}
+	return onlyUntouchedDecorationFiles(dir, files)
+}
+
+// resolvePath cleans path and resolves symlinks when possible.
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
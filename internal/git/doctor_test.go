@@ -0,0 +1,106 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestDiagnose(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	baseDir := filepath.Join(repo.Root, ".wt")
+	for _, name := range []string{"ok", "missing", "moved", "orphaned"} {
+		repo.Git("worktree", "add", "-b", name, filepath.Join(baseDir, name))
+	}
+	if err := os.RemoveAll(filepath.Join(baseDir, "missing")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(baseDir, "moved"), filepath.Join(baseDir, "moved-by-hand")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(repo.Root, ".git", "worktrees", "orphaned")); err != nil {
+		t.Fatal(err)
+	}
+	emptyDir := filepath.Join(baseDir, "feat", "gone")
+	if err := os.MkdirAll(emptyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := initBaseDir(filepath.Join(baseDir, "feat")); err != nil {
+		t.Fatal(err)
+	}
+	repo.CreateFile(".wt/notes/todo.txt", "keep me")
+	repo.Git("init", filepath.Join(baseDir, "other-repo"))
+
+	restore := repo.Chdir()
+	defer restore()
+
+	problems, err := Diagnose(t.Context(), baseDir)
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	got := make(map[string]Problem)
+	for _, p := range problems {
+		got[p.Kind+" "+filepath.Base(p.Path)] = p
+	}
+	want := []string{
+		ProblemDanglingRegistration + " missing",
+		ProblemMovedWorktree + " moved-by-hand",
+		ProblemOrphanedWorktree + " orphaned",
+		ProblemEmptyDirectory + " feat",
+		ProblemUnknownDirectory + " notes",
+	}
+	if len(problems) != len(want) {
+		t.Errorf("expected %d problems, got %+v", len(want), problems)
+	}
+	for _, w := range want {
+		if _, ok := got[w]; !ok {
+			t.Errorf("missing problem %q in %+v", w, problems)
+		}
+	}
+	if p := got[ProblemMovedWorktree+" moved-by-hand"]; p.Branch != "moved" || filepath.Base(p.OldPath) != "moved" {
+		t.Errorf("moved worktree should point to its old registration, got %+v", p)
+	}
+
+	if err := RemoveOrphanedDir(got[ProblemEmptyDirectory+" feat"], baseDir); err != nil {
+		t.Fatalf("RemoveOrphanedDir failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "feat")); !os.IsNotExist(err) {
+		t.Errorf("empty directory should be removed, got %v", err)
+	}
+	if err := RemoveOrphanedDir(got[ProblemUnknownDirectory+" notes"], baseDir); err == nil {
+		t.Error("RemoveOrphanedDir should refuse unknown directories")
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "notes", "todo.txt")); err != nil {
+		t.Errorf("unknown directory should be kept: %v", err)
+	}
+}
+
+func TestDiagnose_Clean(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	baseDir := filepath.Join(repo.Root, ".wt")
+	repo.Git("worktree", "add", "-b", "feat/foo", filepath.Join(baseDir, "feat", "foo"))
+
+	restore := repo.Chdir()
+	defer restore()
+
+	problems, err := Diagnose(t.Context(), baseDir)
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected no problems, got %+v", problems)
+	}
+
+	// A missing basedir is not a problem either.
+	if problems, err := Diagnose(t.Context(), filepath.Join(repo.Root, "nonexistent")); err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems for a missing basedir, got %+v, %v", problems, err)
+	}
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editorPlaceholders maps the placeholders of wt.editor to the positional
+// parameters RunEditor passes them as, so that paths and branch names need no
+// quoting.
+var editorPlaceholders = []struct{ placeholder, param string }{
+	{"{path}", "$1"},
+	{"{branch}", "$2"},
+}
+
+// editorScript returns the shell script that runs the editor command. The
+// placeholders are quoted to suit where they are, so that '{path}' and
+// "{path}" work as well as {path}. The path is appended if the command has no
+// {path} placeholder.
+func editorScript(editor string) string {
+	var b strings.Builder
+	var quote byte // the quote the scanner is inside of, or 0
+	for i := 0; i < len(editor); i++ {
+		if param, n := editorParam(editor[i:]); n > 0 {
+			switch quote {
+			case '\'':
+				b.WriteString(`'"` + param + `"'`)
+			case '"':
+				b.WriteString(param)
+			default:
+				b.WriteString(`"` + param + `"`)
+			}
+			i += n - 1
+			continue
+		}
+		c := editor[i]
+		b.WriteByte(c)
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(editor):
+			i++
+			b.WriteByte(editor[i])
+		case c == quote:
+			quote = 0
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		}
+	}
+	if !strings.Contains(editor, "{path}") {
+		b.WriteString(` "$1"`)
+	}
+	return b.String()
+}
+
+// editorParam returns the positional parameter of the placeholder s starts
+// with and the length of the placeholder, or 0 if s starts with none.
+func editorParam(s string) (string, int) {
+	for _, p := range editorPlaceholders {
+		if strings.HasPrefix(s, p.placeholder) {
+			return p.param, len(p.placeholder)
+		}
+	}
+	return "", 0
+}
+
+// RunEditor runs the editor command for the worktree directory path (of
+// branch) in that directory and waits for it to exit. {path} and {branch} in
+// editor are replaced with path and branch. The editor inherits stdin and,
+// unlike hooks, stays in the process group of git wt, so that terminal
+// editors can read from the terminal and receive Ctrl-C.
+func RunEditor(ctx context.Context, editor, path, branch string, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", editorScript(editor), "--", path, branch) //#nosec G204
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package git
+
+import "testing"
+
+func TestEditorScript(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   string
+	}{
+		{"code", `code "$1"`},
+		{"code --reuse-window {path}", `code --reuse-window "$1"`},
+		{"vim {path}/README.md", `vim "$1"/README.md`},
+		{"idea {path} --title {branch}", `idea "$1" --title "$2"`},
+		{"echo {branch}", `echo "$2" "$1"`},
+		{"code '{path}'", `code ''"$1"''`},
+		{`code "{path}"`, `code "$1"`},
+		{`open -a 'My Editor' '{path}/src'`, `open -a 'My Editor' ''"$1"'/src'`},
+		{`idea --title "{branch} ({path})"`, `idea --title "$2 ($1)"`},
+		{`echo \'{path}`, `echo \'"$1"`},
+		{`echo "it's {branch}" {path}`, `echo "it's $2" "$1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			if got := editorScript(tt.editor); got != tt.want {
+				t.Errorf("editorScript(%q) = %q, want %q", tt.editor, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// WorktreeChanges is what was done in a worktree since a base commit.
+type WorktreeChanges struct {
+	// Commits are the commits made on top of the base commit, oldest first,
+	// as "<abbreviated hash> <subject>".
+	Commits []string
+	// Stat is the diffstat of Patch.
+	Stat string
+	// Patch is the binary diff from the base commit to the working tree,
+	// including uncommitted and untracked (but not ignored) files. It is
+	// empty if nothing changed.
+	Patch string
+}
+
+// ResolveCommit resolves rev to a commit hash.
+func ResolveCommit(ctx context.Context, rev string) (string, error) {
+	return revParse(ctx, "", rev+"^{commit}")
+}
+
+// CollectChanges returns the changes in the worktree dir since the commit
+// base. The worktree itself, including its index, is left untouched.
+func CollectChanges(ctx context.Context, dir, base string) (*WorktreeChanges, error) {
+	head, err := revParse(ctx, dir, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tip, err := snapshotChanges(ctx, dir, head, dir)
+	if err != nil {
+		return nil, err
+	}
+	if tip == "" {
+		tip = head
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd, err := gitCommand(ctx, args...)
+		if err != nil {
+			return "", err
+		}
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return string(out), nil
+	}
+
+	changes := &WorktreeChanges{}
+	log, err := run("log", "--reverse", "--format=%h %s", base+".."+head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %q: %w", dir, err)
+	}
+	for line := range strings.Lines(log) {
+		changes.Commits = append(changes.Commits, strings.TrimSuffix(line, "\n"))
+	}
+	if changes.Stat, err = run("diff", "--stat", base, tip); err != nil {
+		return nil, fmt.Errorf("failed to diff %q: %w", dir, err)
+	}
+	if changes.Patch, err = run("diff", "--binary", base, tip); err != nil {
+		return nil, fmt.Errorf("failed to diff %q: %w", dir, err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestCollectChanges(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	wtPath := filepath.Join(repo.ParentDir(), "worktree-ephemeral")
+	repo.Git("worktree", "add", "-b", "ephemeral", wtPath)
+
+	restore := repo.Chdir()
+	defer restore()
+
+	base, err := ResolveCommit(t.Context(), "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveCommit failed: %v", err)
+	}
+
+	changes, err := CollectChanges(t.Context(), wtPath, base)
+	if err != nil {
+		t.Fatalf("CollectChanges failed: %v", err)
+	}
+	if changes.Patch != "" || len(changes.Commits) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	// A commit, an uncommitted modification and an untracked file.
+	if err := os.WriteFile(filepath.Join(wtPath, "committed.txt"), []byte("committed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	repo.Git("-C", wtPath, "add", "committed.txt")
+	repo.Git("-C", wtPath, "commit", "-m", "add committed.txt")
+	if err := os.WriteFile(filepath.Join(wtPath, "README.md"), []byte("# Modified\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "untracked.txt"), []byte("untracked\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = CollectChanges(t.Context(), wtPath, base)
+	if err != nil {
+		t.Fatalf("CollectChanges failed: %v", err)
+	}
+	if len(changes.Commits) != 1 || !strings.HasSuffix(changes.Commits[0], " add committed.txt") {
+		t.Errorf("Commits = %q, want the commit of committed.txt", changes.Commits)
+	}
+	for _, s := range []string{"+++ b/committed.txt", "+# Modified", "+++ b/untracked.txt"} {
+		if !strings.Contains(changes.Patch, s) {
+			t.Errorf("Patch should contain %q, got:\n%s", s, changes.Patch)
+		}
+	}
+	if !strings.Contains(changes.Stat, "3 files changed") {
+		t.Errorf("Stat = %q", changes.Stat)
+	}
+
+	// The worktree is left untouched.
+	status := strings.Fields(repo.Git("-C", wtPath, "status", "--porcelain"))
+	if !slices.Equal(status, []string{"M", "README.md", "??", "untracked.txt"}) {
+		t.Errorf("status = %q, want README.md modified and untracked.txt untracked", status)
+	}
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// historyFile is the name of the switch history inside StateDir.
+const historyFile = "history.json"
+
+// maxHistory is the number of worktrees kept in the switch history.
+const maxHistory = 100
+
+// HistoryEntry is a worktree that was switched to with git wt.
+type HistoryEntry struct {
+	Path       string    `json:"path"`
+	SwitchedAt time.Time `json:"switched_at"`
+	Count      int       `json:"count,omitempty"` // number of switches to the worktree
+}
+
+// Frecency scores how often and how recently the worktree was switched to:
+// the number of switches, weighted by the age of the latest one (as in
+// zoxide). Worktrees only left (see RecordSwitch) count as one switch.
+func (e HistoryEntry) Frecency(now time.Time) float64 {
+	count := float64(max(e.Count, 1))
+	switch age := now.Sub(e.SwitchedAt); {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
+	}
+}
+
+// SwitchHistory returns the worktrees switched to with git wt, most recent
+// first. Each worktree appears once, with the time of its latest switch.
+func SwitchHistory(ctx context.Context) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	if err := readState(ctx, historyFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RecordSwitch records a switch from the worktree at from to the worktree at
+// to in the history. from is the location the switch was made from (see
+// CurrentLocation), or empty if unknown. It is kept right behind to, so that
+// PreviousWorktree finds it even if it was entered without git wt.
+func RecordSwitch(ctx context.Context, from, to string) error {
+	entries, err := SwitchHistory(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	to = filepath.Clean(to)
+	count := 1
+	for _, e := range entries {
+		if e.Path == to {
+			count += e.Count
+			break
+		}
+	}
+	updated := []HistoryEntry{{Path: to, SwitchedAt: now, Count: count}}
+	if from != "" {
+		from = filepath.Clean(from)
+		if from != to {
+			// Keep the time of the last switch to from, if any.
+			prev := HistoryEntry{Path: from, SwitchedAt: now}
+			for _, e := range entries {
+				if e.Path == from {
+					prev = e
+					break
+				}
+			}
+			updated = append(updated, prev)
+		}
+	}
+	for _, e := range entries {
+		if e.Path != to && e.Path != from && len(updated) < maxHistory {
+			updated = append(updated, e)
+		}
+	}
+	return writeState(ctx, historyFile, updated)
+}
+
+// PreviousWorktree returns the worktree of the n-th previous switch (1 for
+// the last one, like @{-1} in git), not counting the worktree at current.
+// Entries of worktrees that no longer exist are pruned from the history. It
+// returns nil if the history holds fewer than n other worktrees.
+func PreviousWorktree(ctx context.Context, current string, n int) (*Worktree, error) {
+	entries, err := SwitchHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := ListWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]Worktree)
+	for _, wt := range worktrees {
+		if !wt.Prunable {
+			existing[resolvePath(wt.Path)] = wt
+		}
+	}
+
+	var (
+		kept     []HistoryEntry
+		previous *Worktree
+	)
+	if current != "" {
+		current = resolvePath(current)
+	}
+	for _, e := range entries {
+		wt, ok := existing[resolvePath(e.Path)]
+		if !ok {
+			continue
+		}
+		kept = append(kept, e)
+		if resolvePath(e.Path) == current || previous != nil {
+			continue
+		}
+		if n--; n == 0 {
+			previous = &wt
+		}
+	}
+	if len(kept) != len(entries) {
+		if err := writeState(ctx, historyFile, kept); err != nil {
+			return nil, err
+		}
+	}
+	return previous, nil
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestRecordSwitch(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	a := filepath.Join(repo.ParentDir(), "a")
+	b := filepath.Join(repo.ParentDir(), "b")
+	for _, p := range []string{a, b, a + "/"} {
+		if err := RecordSwitch(t.Context(), "", p); err != nil {
+			t.Fatalf("RecordSwitch failed: %v", err)
+		}
+	}
+
+	entries, err := SwitchHistory(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Path != a || entries[1].Path != b {
+		t.Fatalf("expected history [a, b] (most recent first, deduplicated), got %+v", entries)
+	}
+	if entries[0].SwitchedAt.Before(entries[1].SwitchedAt) {
+		t.Errorf("latest switch should be first, got %+v", entries)
+	}
+	if entries[0].Count != 2 || entries[1].Count != 1 {
+		t.Errorf("expected switch counts [2, 1], got %+v", entries)
+	}
+
+	for i := range maxHistory + 5 {
+		if err := RecordSwitch(t.Context(), "", filepath.Join(repo.ParentDir(), fmt.Sprintf("wt%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err = SwitchHistory(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxHistory {
+		t.Errorf("history should be capped at %d entries, got %d", maxHistory, len(entries))
+	}
+}
+
+func TestRecordSwitch_From(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	a := filepath.Join(repo.ParentDir(), "a")
+	b := filepath.Join(repo.ParentDir(), "b")
+	c := filepath.Join(repo.ParentDir(), "c")
+	if err := RecordSwitch(t.Context(), "", a); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := SwitchHistory(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	switchedToA := entries[0].SwitchedAt
+
+	// b is entered without git wt, then left for c.
+	if err := RecordSwitch(t.Context(), b, c); err != nil {
+		t.Fatal(err)
+	}
+	// Leaving a for b keeps the time of the last switch to a.
+	if err := RecordSwitch(t.Context(), a, b); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = SwitchHistory(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, filepath.Base(e.Path))
+	}
+	if fmt.Sprint(got) != "[b a c]" {
+		t.Fatalf("expected history [b a c], got %v", got)
+	}
+	if !entries[1].SwitchedAt.Equal(switchedToA) {
+		t.Errorf("switch time of a should be kept, got %v, want %v", entries[1].SwitchedAt, switchedToA)
+	}
+}
+
+func TestPreviousWorktree(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	a := filepath.Join(repo.ParentDir(), "a")
+	b := filepath.Join(repo.ParentDir(), "b")
+	gone := filepath.Join(repo.ParentDir(), "gone")
+	repo.Git("worktree", "add", "-b", "a", a)
+	repo.Git("worktree", "add", "-b", "b", b)
+	for _, p := range []string{a, gone, b, repo.Root} {
+		if err := RecordSwitch(t.Context(), "", p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		current string
+		n       int
+		want    string
+	}{
+		{repo.Root, 1, b},
+		{repo.Root, 2, a},
+		{repo.Root, 3, ""},
+		{b, 1, repo.Root},
+		{b, 2, a},
+		{"", 1, repo.Root},
+	}
+	for _, tt := range tests {
+		wt, err := PreviousWorktree(t.Context(), tt.current, tt.n)
+		if err != nil {
+			t.Fatalf("PreviousWorktree(%q, %d) failed: %v", tt.current, tt.n, err)
+		}
+		var got string
+		if wt != nil {
+			got = wt.Path
+		}
+		if got != tt.want {
+			t.Errorf("PreviousWorktree(%q, %d) = %q, want %q", tt.current, tt.n, got, tt.want)
+		}
+	}
+
+	// The entry of the missing worktree has been pruned.
+	entries, err := SwitchHistory(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Path == gone {
+			t.Errorf("history should not contain the missing worktree %q, got %+v", gone, entries)
+		}
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries after pruning, got %+v", entries)
+	}
+}
+
+func TestHistoryEntry_Frecency(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		entry HistoryEntry
+		want  float64
+	}{
+		{"recent", HistoryEntry{SwitchedAt: now.Add(-time.Minute), Count: 3}, 12},
+		{"today", HistoryEntry{SwitchedAt: now.Add(-3 * time.Hour), Count: 3}, 6},
+		{"this_week", HistoryEntry{SwitchedAt: now.Add(-72 * time.Hour), Count: 3}, 1.5},
+		{"older", HistoryEntry{SwitchedAt: now.Add(-30 * 24 * time.Hour), Count: 3}, 0.75},
+		{"left_only", HistoryEntry{SwitchedAt: now.Add(-time.Minute)}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Frecency(now); got != tt.want {
+				t.Errorf("Frecency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,24 +2,173 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/k1LoW/exec"
 )
 
-// RunHooks executes the configured hooks in the given directory.
-// Hook stdout/stderr are written to the provided writer.
-// If a hook fails, it stops immediately and returns the error.
-func RunHooks(ctx context.Context, hooks []string, dir string, w io.Writer) error {
+// Events hooks and the remover run for, exported as GIT_WT_EVENT.
+const (
+	HookEventPreCreate  = "precreate"
+	HookEventCreate     = "create"
+	HookEventSwitch     = "switch"
+	HookEventMove       = "move"
+	HookEventDelete     = "delete"
+	HookEventPostDelete = "postdelete"
+)
+
+// HookEnv describes the worktree hooks and the remover run for. It is
+// exported to them as GIT_WT_* environment variables (see Environ). All of
+// the variables are set, to empty strings if they do not apply, so that
+// values are not inherited from an enclosing git wt.
+type HookEnv struct {
+	Event      string // one of the HookEvent* constants
+	Path       string // worktree directory
+	Branch     string // branch of the worktree, empty if detached
+	Name       string // worktree directory relative to basedir
+	MainRoot   string // root of the main working tree
+	SourcePath string // worktree git wt was run in, which files are copied from (precreate, create and switch; empty from a bare root)
+	StartPoint string // start-point of a new branch (precreate and create, empty if not given)
+	NewBranch  bool   // whether the branch is created along with the worktree (precreate and create)
+	OldPath    string // worktree directory before a move (move only)
+	OldBranch  string // branch before a move (move only)
+}
+
+// Environ returns the environment of the current process with the GIT_WT_*
+// variables of e added.
+func (e HookEnv) Environ() []string {
+	newBranch := ""
+	if e.Event == HookEventPreCreate || e.Event == HookEventCreate {
+		newBranch = "0"
+		if e.NewBranch {
+			newBranch = "1"
+		}
+	}
+	return append(os.Environ(),
+		"GIT_WT_EVENT="+e.Event,
+		"GIT_WT_PATH="+e.Path,
+		"GIT_WT_BRANCH="+e.Branch,
+		"GIT_WT_NAME="+e.Name,
+		"GIT_WT_MAIN_ROOT="+e.MainRoot,
+		"GIT_WT_SOURCE_PATH="+e.SourcePath,
+		"GIT_WT_START_POINT="+e.StartPoint,
+		"GIT_WT_NEW_BRANCH="+newBranch,
+		"GIT_WT_OLD_PATH="+e.OldPath,
+		"GIT_WT_OLD_BRANCH="+e.OldBranch,
+	)
+}
+
+// RunHooks executes the configured hooks in the given directory, with the
+// variables of env.
+// Hook stdout/stderr are written to the provided writer. Each run is also
+// recorded in the log directory of the worktree env.Path (see HookRuns).
+// A hook running longer than timeout (if positive) is killed along with its
+// process group. If a hook fails, it is retried as its failure policy says
+// (see parseHook); if it still fails, it stops immediately and returns the
+// error, unless the policy is to warn and go on with the next hook.
+func RunHooks(ctx context.Context, hooks []string, dir string, env HookEnv, timeout time.Duration, w io.Writer) error {
+	environ := env.Environ()
 	for _, hook := range hooks {
-		cmd := exec.CommandContext(ctx, "sh", "-c", hook)
-		cmd.Dir = dir
-		cmd.Stdout = w
-		cmd.Stderr = w
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("hook %q failed: %w", hook, err)
+		command, policy, err := parseHook(hook)
+		if err != nil {
+			return err
+		}
+		err = runRecordedHook(ctx, command, dir, env, environ, timeout, w)
+		for attempt := 1; err != nil && attempt <= policy.retries && ctx.Err() == nil; attempt++ {
+			fmt.Fprintf(w, "hook %q failed: %v, retrying (%d/%d)\n", command, err, attempt, policy.retries)
+			err = runRecordedHook(ctx, command, dir, env, environ, timeout, w)
+		}
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("hook %q failed: %w", command, err)
+		if policy.warn && ctx.Err() == nil {
+			fmt.Fprintf(w, "warning: %v\n", err)
+			continue
 		}
+		return err
 	}
 	return nil
 }
+
+// runHook runs command with sh in dir, killing its process group if it runs
+// longer than timeout (if positive).
+func runHook(ctx context.Context, command, dir string, environ []string, timeout time.Duration, w io.Writer) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = environ
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if timeout > 0 {
+		// Do not wait for processes that left the process group but still
+		// hold the output open.
+		cmd.WaitDelay = time.Second
+	}
+	err := cmd.Run()
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// hookPolicy says what to do when a hook fails.
+type hookPolicy struct {
+	retries int  // how many more times to run the hook
+	warn    bool // warn and go on instead of failing
+}
+
+// parseHook splits the failure policy off hook. The policy is an optional
+// prefix of comma-separated items in brackets: "abort" (the default) to fail,
+// "warn" to only warn, and "retry=N" to run the hook up to N more times
+// first, e.g. "[retry=2,warn] npm install". Brackets not directly followed by
+// a letter, as in "[ -f .env ] || cp .env.example .env", are left to the
+// shell.
+func parseHook(hook string) (string, hookPolicy, error) {
+	s := strings.TrimLeft(hook, " \t")
+	if len(s) < 2 || s[0] != '[' || !isASCIILetter(s[1]) {
+		return hook, hookPolicy{}, nil
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return hook, hookPolicy{}, nil
+	}
+	var p hookPolicy
+	for item := range strings.SplitSeq(s[1:end], ",") {
+		item = strings.TrimSpace(item)
+		name, value, _ := strings.Cut(item, "=")
+		switch {
+		case item == "abort":
+			p.warn = false
+		case item == "warn":
+			p.warn = true
+		case name == "retry":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return "", hookPolicy{}, fmt.Errorf("invalid retry count %q in hook %q", value, hook)
+			}
+			p.retries = n
+		default:
+			return "", hookPolicy{}, fmt.Errorf("unknown failure policy %q in hook %q (supported: abort, warn, retry=N)", item, hook)
+		}
+	}
+	command := strings.TrimLeft(s[end+1:], " \t")
+	if strings.HasPrefix(command, "&") {
+		return "", hookPolicy{}, fmt.Errorf("\"&\" must come before the failure policy in hook %q", hook)
+	}
+	return command, p, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,332 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook modes of wt.hookmode.
+const (
+	HookModeSerial     = "serial"     // hooks run one after another (default)
+	HookModeParallel   = "parallel"   // hooks run concurrently, and git wt waits for all of them
+	HookModeBackground = "background" // hooks run detached, after git wt exits
+)
+
+// backgroundHooksFile is the name of the journal of background hooks inside
+// StateDir.
+const backgroundHooksFile = "background_hooks.json"
+
+// backgroundHookExpiry is how long a background hook is waited for. A hook
+// whose process still seems to be running after that is given up on, as its
+// process ID has most likely been reused by another process.
+const backgroundHookExpiry = 24 * time.Hour
+
+// hookLogDir is the directory of the hook logs inside StateDir.
+const hookLogDir = "logs"
+
+// HookModes returns the modes accepted by wt.hookmode.
+func HookModes() []string {
+	return []string{HookModeSerial, HookModeParallel, HookModeBackground}
+}
+
+// BackgroundHook is a hook started in the background. It stays in the journal
+// until CheckBackgroundHooks finds it finished.
+type BackgroundHook struct {
+	Path      string    `json:"path"` // worktree the hook was run for
+	Hook      string    `json:"hook"`
+	Log       string    `json:"log"` // file the output of the hook is written to
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// exitFile returns the file the exit code of the hook is written to.
+func (h BackgroundHook) exitFile() string {
+	return strings.TrimSuffix(h.Log, ".log") + ".exit"
+}
+
+// exitFile returns the file the exit code of the hook is written to if it
+// runs in the background.
+func (r HookRun) exitFile() string {
+	return BackgroundHook{Log: r.Log}.exitFile()
+}
+
+// BackgroundHookResult is a background hook that has finished.
+type BackgroundHookResult struct {
+	BackgroundHook
+	ExitCode int  // -1 if the hook was killed before it could record its exit code, or expired
+	Expired  bool // the hook was given up on after backgroundHookExpiry
+}
+
+// SplitHooks splits hooks into the ones to run in the foreground and the ones
+// to run in the background: those prefixed with "&" (with the prefix
+// removed), or all of them in background mode.
+func SplitHooks(hooks []string, mode string) (foreground, background []string) {
+	for _, hook := range hooks {
+		if h, ok := strings.CutPrefix(strings.TrimSpace(hook), "&"); ok {
+			background = append(background, strings.TrimSpace(h))
+			continue
+		}
+		if mode == HookModeBackground {
+			background = append(background, hook)
+			continue
+		}
+		foreground = append(foreground, hook)
+	}
+	return foreground, background
+}
+
+// RunHooksParallel executes hooks concurrently in the given directory, with
+// the variables of env, like RunHooks. The output of each hook is written to
+// w in one piece when it finishes, so that the output of different hooks is
+// not mixed. All hooks run to completion; the failures are returned together.
+func RunHooksParallel(ctx context.Context, hooks []string, dir string, env HookEnv, timeout time.Duration, w io.Writer) error {
+	var (
+		mu   sync.Mutex
+		errs = make([]error, len(hooks))
+		wg   sync.WaitGroup
+	)
+	for i, hook := range hooks {
+		wg.Go(func() {
+			var out bytes.Buffer
+			errs[i] = RunHooks(ctx, []string{hook}, dir, env, timeout, &out)
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write(out.Bytes())
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// StartBackgroundHooks starts hooks in the given directory, with the
+// variables of env, detached from git wt so that they keep running after it
+// exits. The output of each hook is written to a log file in the log
+// directory of the worktree env.Path (see HookRuns), and the hooks are
+// recorded in the journal for CheckBackgroundHooks. Hooks are retried as
+// their failure policies say, but there is no timeout, as nothing is left to
+// enforce it.
+func StartBackgroundHooks(ctx context.Context, hooks []string, dir string, env HookEnv) ([]BackgroundHook, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	commands := make([]string, len(hooks))
+	policies := make([]hookPolicy, len(hooks))
+	for i, hook := range hooks {
+		var err error
+		if commands[i], policies[i], err = parseHook(hook); err != nil {
+			return nil, err
+		}
+	}
+	environ := env.Environ()
+	var started []BackgroundHook
+	for i, hook := range commands {
+		h, err := startBackgroundHook(ctx, hook, policies[i].retries, dir, env, environ)
+		if err == nil {
+			started = append(started, h)
+			continue
+		}
+		err = fmt.Errorf("failed to start hook %q: %w", hook, err)
+		if len(started) > 0 {
+			// Keep track of the hooks already running.
+			err = errors.Join(err, recordBackgroundHooks(ctx, started))
+		}
+		return started, err
+	}
+	if err := recordBackgroundHooks(ctx, started); err != nil {
+		return started, err
+	}
+	return started, nil
+}
+
+// recordBackgroundHooks adds hooks to the journal.
+func recordBackgroundHooks(ctx context.Context, hooks []BackgroundHook) error {
+	var journal []BackgroundHook
+	return updateState(ctx, backgroundHooksFile, &journal, func() error {
+		journal = append(journal, hooks...)
+		return nil
+	})
+}
+
+// startBackgroundHook records a run of hook for env (see HookRuns) and starts
+// it with startDetached.
+func startBackgroundHook(ctx context.Context, hook string, retries int, dir string, env HookEnv, environ []string) (BackgroundHook, error) {
+	run, log, err := newHookRun(ctx, env, hook, true)
+	if err != nil {
+		return BackgroundHook{}, err
+	}
+	defer log.Close()
+	pid, err := startDetached(hook, retries, dir, environ, log, run.exitFile())
+	if err != nil {
+		_ = run.finish(err)
+		return BackgroundHook{}, err
+	}
+	return BackgroundHook{Path: run.Path, Hook: hook, Log: run.Log, PID: pid, StartedAt: run.StartedAt}, nil
+}
+
+// startDetached runs hook with sh in dir, up to retries more times while it
+// fails, in a session of its own and with its output written to log. When
+// the hook is done, its exit code is written to exitFile. It returns the
+// process ID of the shell.
+func startDetached(hook string, retries int, dir string, environ []string, log *os.File, exitFile string) (int, error) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		return 0, err
+	}
+	defer null.Close()
+
+	// The exit code is written to a temporary file first, so that it is never
+	// read half-written.
+	const script = `n=0
+while :; do
+	sh -c "$1"; c=$?
+	if [ $c -eq 0 ] || [ $n -ge "$3" ]; then break; fi
+	n=$((n + 1)); echo "exit status $c, retrying ($n/$3)"
+done
+echo $c > "$2.tmp" && mv "$2.tmp" "$2"`
+	cmd := osexec.Command("sh", "-c", script, "git-wt-hook", hook, exitFile, strconv.Itoa(retries)) //#nosec G204
+	cmd.Dir = dir
+	cmd.Env = environ
+	cmd.Stdin = null
+	cmd.Stdout = log
+	cmd.Stderr = log
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	// Not waited for: the hook outlives git wt.
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// CheckBackgroundHooks returns the background hooks of the worktree at path
+// (of all worktrees if path is empty) that have finished since the last check,
+// and removes them from the journal. Hooks still running are kept, unless
+// they were started more than backgroundHookExpiry ago.
+func CheckBackgroundHooks(ctx context.Context, path string) ([]BackgroundHookResult, error) {
+	// Most of the time there is nothing to check, and no need to lock.
+	if journal, err := readBackgroundHooks(ctx); err != nil || len(journal) == 0 {
+		return nil, err
+	}
+	if path != "" {
+		path = filepath.Clean(path)
+	}
+	var (
+		journal  []BackgroundHook
+		finished []BackgroundHookResult
+	)
+	err := updateState(ctx, backgroundHooksFile, &journal, func() error {
+		running := []BackgroundHook{}
+		for _, h := range journal {
+			if path != "" && h.Path != path {
+				running = append(running, h)
+				continue
+			}
+			code, ok := backgroundHookExitCode(h)
+			switch {
+			case ok:
+				finished = append(finished, BackgroundHookResult{BackgroundHook: h, ExitCode: code})
+			case time.Since(h.StartedAt) > backgroundHookExpiry:
+				finished = append(finished, BackgroundHookResult{BackgroundHook: h, ExitCode: -1, Expired: true})
+			default:
+				running = append(running, h)
+			}
+		}
+		journal = running
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range finished {
+		finishBackgroundRun(r)
+		_ = os.Remove(r.exitFile())
+	}
+	return finished, nil
+}
+
+// backgroundHookExitCode returns the exit code of h, and whether h has
+// finished. A hook that is gone without an exit code was killed.
+func backgroundHookExitCode(h BackgroundHook) (int, bool) {
+	b, err := os.ReadFile(h.exitFile())
+	if err == nil {
+		if code, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil {
+			return code, true
+		}
+	}
+	if processAlive(h.PID) {
+		return 0, false
+	}
+	// The hook may have finished between reading the exit file and
+	// looking for the process.
+	if b, err := os.ReadFile(h.exitFile()); err == nil {
+		if code, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil {
+			return code, true
+		}
+	}
+	return -1, true
+}
+
+// finishBackgroundRun records the end of the run of the finished background
+// hook r (see HookRuns). It ended when it wrote its exit code; killed and
+// expired hooks are left without an end time.
+func finishBackgroundRun(r BackgroundHookResult) {
+	run := HookRun{Log: r.Log}
+	b, err := os.ReadFile(run.metaFile())
+	if err != nil || json.Unmarshal(b, &run) != nil {
+		return
+	}
+	run.ExitCode = r.ExitCode
+	switch {
+	case r.Expired:
+		run.Error = "expired"
+	case r.ExitCode < 0:
+		run.Error = "killed"
+	default:
+		if fi, err := os.Stat(r.exitFile()); err == nil {
+			run.EndedAt = fi.ModTime()
+		}
+	}
+	_ = run.save()
+}
+
+// readBackgroundHooks returns the background hooks in the journal.
+func readBackgroundHooks(ctx context.Context) ([]BackgroundHook, error) {
+	var hooks []BackgroundHook
+	if err := readState(ctx, backgroundHooksFile, &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// HookLogDir returns the directory holding the hook logs of the worktree at
+// path, inside StateDir. It is named after the worktree directory, with a
+// hash of the full path to tell apart worktrees of the same name.
+func HookLogDir(ctx context.Context, path string) (string, error) {
+	return hookLogDirIn(ctx, "", path)
+}
+
+// hookLogDirIn is HookLogDir with StateDir looked up from repoDir (the
+// current directory if empty).
+func hookLogDirIn(ctx context.Context, repoDir, path string) (string, error) {
+	dir, err := stateDirIn(ctx, repoDir)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Clean(path)
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(dir, hookLogDir, filepath.Base(path)+"-"+hex.EncodeToString(sum[:4])), nil
+}
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestSplitHooks(t *testing.T) {
+	hooks := []string{"npm install", "&go generate ./...", "  & make docs", "echo a && echo b"}
+
+	tests := []struct {
+		mode           string
+		wantForeground []string
+		wantBackground []string
+	}{
+		{
+			mode:           HookModeSerial,
+			wantForeground: []string{"npm install", "echo a && echo b"},
+			wantBackground: []string{"go generate ./...", "make docs"},
+		},
+		{
+			mode:           HookModeParallel,
+			wantForeground: []string{"npm install", "echo a && echo b"},
+			wantBackground: []string{"go generate ./...", "make docs"},
+		},
+		{
+			mode:           HookModeBackground,
+			wantForeground: nil,
+			wantBackground: []string{"npm install", "go generate ./...", "make docs", "echo a && echo b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			foreground, background := SplitHooks(hooks, tt.mode)
+			if !slices.Equal(foreground, tt.wantForeground) {
+				t.Errorf("foreground = %q, want %q", foreground, tt.wantForeground)
+			}
+			if !slices.Equal(background, tt.wantBackground) {
+				t.Errorf("background = %q, want %q", background, tt.wantBackground)
+			}
+		})
+	}
+}
+
+func TestRunHooksParallel(t *testing.T) {
+	dir := t.TempDir()
+	// Each hook waits for the other, so they only succeed if run concurrently.
+	wait := `touch %s; for i in $(seq 50); do test -e %s && exit 0; sleep 0.1; done; exit 1`
+	hooks := []string{
+		fmt.Sprintf(wait, "a", "b"),
+		fmt.Sprintf(wait, "b", "a"),
+		"echo one; echo two",
+	}
+	var out bytes.Buffer
+	if err := RunHooksParallel(t.Context(), hooks, dir, HookEnv{}, 0, &out); err != nil {
+		t.Fatalf("RunHooksParallel failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "one\ntwo\n") {
+		t.Errorf("the output of a hook should be written in one piece, got %q", out.String())
+	}
+
+	err := RunHooksParallel(t.Context(), []string{"exit 1", "touch done", "exit 2"}, dir, HookEnv{}, 0, &out)
+	if err == nil {
+		t.Fatal("RunHooksParallel should fail when a hook fails")
+	}
+	for _, s := range []string{`"exit 1"`, `"exit 2"`} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("error should report hook %s, got: %v", s, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "done")); err != nil {
+		t.Errorf("all hooks should run despite failures: %v", err)
+	}
+}
+
+func TestBackgroundHooks(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	wtPath := filepath.Join(repo.Root, ".wt", "bg")
+	if err := os.MkdirAll(wtPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	release := filepath.Join(t.TempDir(), "release")
+	env := HookEnv{Event: HookEventCreate, Path: wtPath, Branch: "bg"}
+	hooks := []string{
+		`echo "ok in $PWD for $GIT_WT_BRANCH"`,
+		`echo failing >&2; while ! test -e ` + release + `; do sleep 0.05; done; exit 3`,
+	}
+	started, err := StartBackgroundHooks(t.Context(), hooks, wtPath, env)
+	if err != nil {
+		t.Fatalf("StartBackgroundHooks failed: %v", err)
+	}
+	if len(started) != 2 {
+		t.Fatalf("expected 2 started hooks, got %d", len(started))
+	}
+	logDir, err := HookLogDir(t.Context(), wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range started {
+		if filepath.Dir(h.Log) != logDir {
+			t.Errorf("log %s should be in %s", h.Log, logDir)
+		}
+	}
+
+	// check waits until n hooks have finished in total and returns them.
+	check := func(path string, n int) []BackgroundHookResult {
+		t.Helper()
+		var results []BackgroundHookResult
+		for deadline := time.Now().Add(10 * time.Second); len(results) < n && time.Now().Before(deadline); {
+			r, err := CheckBackgroundHooks(t.Context(), path)
+			if err != nil {
+				t.Fatalf("CheckBackgroundHooks failed: %v", err)
+			}
+			results = append(results, r...)
+			time.Sleep(50 * time.Millisecond)
+		}
+		if len(results) != n {
+			t.Fatalf("expected %d finished hooks, got %+v", n, results)
+		}
+		return results
+	}
+
+	// Hooks of other worktrees are not reported.
+	if r, err := CheckBackgroundHooks(t.Context(), filepath.Join(repo.Root, ".wt", "other")); err != nil || len(r) != 0 {
+		t.Fatalf("CheckBackgroundHooks(other) = %+v, %v, want nothing", r, err)
+	}
+
+	results := check(wtPath, 1)
+	if results[0].Hook != hooks[0] || results[0].ExitCode != 0 {
+		t.Errorf("expected the first hook to succeed, got %+v", results[0])
+	}
+	b, err := os.ReadFile(results[0].Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ok in " + wtPath + " for bg\n"; string(b) != want {
+		t.Errorf("log = %q, want %q", b, want)
+	}
+
+	if err := os.WriteFile(release, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	results = check("", 1)
+	if results[0].Hook != hooks[1] || results[0].ExitCode != 3 {
+		t.Errorf("expected the second hook to fail with exit code 3, got %+v", results[0])
+	}
+
+	// Finished hooks are reported only once.
+	if r, err := CheckBackgroundHooks(t.Context(), ""); err != nil || len(r) != 0 {
+		t.Errorf("CheckBackgroundHooks = %+v, %v, want nothing", r, err)
+	}
+
+	runs, err := HookRuns(t.Context(), wtPath)
+	if err != nil {
+		t.Fatalf("HookRuns failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 recorded runs, got %+v", runs)
+	}
+	for _, r := range runs {
+		if !r.Background || r.Running() || r.EndedAt.IsZero() {
+			t.Errorf("expected a finished background run, got %+v", r)
+		}
+	}
+	codes := map[string]int{runs[0].Hook: runs[0].ExitCode, runs[1].Hook: runs[1].ExitCode}
+	if codes[hooks[0]] != 0 || codes[hooks[1]] != 3 {
+		t.Errorf("recorded exit codes = %v, want 0 and 3", codes)
+	}
+}
+
+func TestStartBackgroundHooksConcurrently(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	// Worktrees created at the same time must not drop each other's hooks
+	// from the journal.
+	const n = 8
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			wtPath := filepath.Join(repo.Root, ".wt", fmt.Sprintf("bg-%d", i))
+			if err := os.MkdirAll(wtPath, 0755); err != nil {
+				t.Error(err)
+				return
+			}
+			env := HookEnv{Event: HookEventCreate, Path: wtPath, Branch: fmt.Sprintf("bg-%d", i)}
+			if _, err := StartBackgroundHooks(t.Context(), []string{"true"}, wtPath, env); err != nil {
+				t.Errorf("StartBackgroundHooks failed: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	journal, err := readBackgroundHooks(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journal) != n {
+		t.Errorf("expected %d hooks in the journal, got %d", n, len(journal))
+	}
+}
+
+func TestCheckBackgroundHooksExpired(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	// Both hooks seem to be running, as the process of the test is.
+	logDir := t.TempDir()
+	old := BackgroundHook{Path: filepath.Join(repo.Root, ".wt", "old"), Hook: "sleep 1000", Log: filepath.Join(logDir, "old.log"), PID: os.Getpid(), StartedAt: time.Now().Add(-backgroundHookExpiry - time.Hour)}
+	recent := BackgroundHook{Path: filepath.Join(repo.Root, ".wt", "recent"), Hook: "sleep 1000", Log: filepath.Join(logDir, "recent.log"), PID: os.Getpid(), StartedAt: time.Now()}
+	if err := recordBackgroundHooks(t.Context(), []BackgroundHook{old, recent}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := CheckBackgroundHooks(t.Context(), "")
+	if err != nil {
+		t.Fatalf("CheckBackgroundHooks failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != old.Path || !results[0].Expired || results[0].ExitCode != -1 {
+		t.Errorf("expected only the old hook to expire, got %+v", results)
+	}
+	journal, err := readBackgroundHooks(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journal) != 1 || journal[0].Path != recent.Path {
+		t.Errorf("expected the recent hook to stay in the journal, got %+v", journal)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package git
+
+import (
+	"errors"
+	osexec "os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in a new session, so that it is neither killed along
+// with the process group of git wt nor attached to its terminal.
+func detach(cmd *osexec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether the process pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
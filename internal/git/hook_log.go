@@ -0,0 +1,208 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxHookRuns is the number of hook runs kept in the log directory of a
+// worktree.
+const maxHookRuns = 50
+
+// HookRun is a run of a hook recorded in the log directory of its worktree
+// (see HookLogDir): the metadata is kept in a JSON file next to the log.
+type HookRun struct {
+	Event      string    `json:"event"`
+	Hook       string    `json:"hook"`
+	Path       string    `json:"path"` // worktree the hook was run for
+	Background bool      `json:"background,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at,omitzero"` // zero while the hook is running
+	ExitCode   int       `json:"exit_code"`         // -1 if the hook did not exit by itself (see Error)
+	Error      string    `json:"error,omitempty"`   // e.g., "timed out after 1m0s", "killed", "expired"
+	Log        string    `json:"log"`               // file holding the combined output
+}
+
+// Running reports whether the hook has not finished yet.
+func (r HookRun) Running() bool {
+	return r.EndedAt.IsZero() && r.Error == ""
+}
+
+// metaFile returns the file the metadata of the run is written to.
+func (r HookRun) metaFile() string {
+	return strings.TrimSuffix(r.Log, ".log") + ".json"
+}
+
+// save writes the metadata of the run.
+func (r HookRun) save() error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.metaFile() + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.metaFile())
+}
+
+// finish records the end of the run, which failed with err if not nil.
+func (r *HookRun) finish(err error) error {
+	r.EndedAt = time.Now()
+	r.ExitCode, r.Error = 0, ""
+	if err != nil {
+		r.ExitCode, r.Error = -1, err.Error()
+		var exitErr *osexec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			r.ExitCode, r.Error = exitErr.ExitCode(), ""
+		}
+	}
+	return r.save()
+}
+
+// newHookRun records the start of a run of hook for env in the log directory
+// of the worktree env.Path and returns it along with its log, opened for
+// writing. The oldest runs are removed to keep at most maxHookRuns. The log
+// directory is looked up from env.MainRoot if set, as the current directory
+// may be a worktree that was just deleted.
+func newHookRun(ctx context.Context, env HookEnv, hook string, background bool) (*HookRun, *os.File, error) {
+	dir, err := hookLogDirIn(ctx, env.MainRoot, env.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	pruneHookRuns(dir, maxHookRuns-1)
+
+	// Log names start with the time, so that they sort in the order of the
+	// runs.
+	now := time.Now()
+	log, err := os.CreateTemp(dir, fmt.Sprintf("%s-%s-*.log", now.Format("20060102-150405.000"), env.Event))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create hook log: %w", err)
+	}
+	run := &HookRun{
+		Event:      env.Event,
+		Hook:       hook,
+		Path:       filepath.Clean(env.Path),
+		Background: background,
+		StartedAt:  now,
+		Log:        log.Name(),
+	}
+	if err := run.save(); err != nil {
+		_ = log.Close()
+		return nil, nil, fmt.Errorf("failed to record hook run: %w", err)
+	}
+	return run, log, nil
+}
+
+// pruneHookRuns removes the logs and metadata of all but the latest keep runs
+// in dir. Files that cannot be removed are left for the next time.
+func pruneHookRuns(dir string, keep int) {
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil || len(logs) <= keep {
+		return
+	}
+	slices.Sort(logs)
+	for _, log := range logs[:len(logs)-keep] {
+		_ = os.Remove(strings.TrimSuffix(log, ".log") + ".json")
+		_ = os.Remove(log)
+	}
+}
+
+// HookRuns returns the hook runs recorded for the worktree at path, oldest
+// first. Runs are kept after the worktree is deleted.
+func HookRuns(ctx context.Context, path string) ([]HookRun, error) {
+	dir, err := HookLogDir(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var runs []HookRun
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // pruned meanwhile
+			}
+			return nil, fmt.Errorf("failed to read hook run: %w", err)
+		}
+		var r HookRun
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", f, err)
+		}
+		runs = append(runs, r)
+	}
+	slices.SortStableFunc(runs, func(a, b HookRun) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return runs, nil
+}
+
+// runRecordedHook runs command like runHook and records the run in the log
+// directory of the worktree env.Path. The output goes to the log and is
+// copied to w as it comes. If the run cannot be recorded, the hook still runs
+// with a warning.
+func runRecordedHook(ctx context.Context, command, dir string, env HookEnv, environ []string, timeout time.Duration, w io.Writer) error {
+	if env.Path == "" {
+		return runHook(ctx, command, dir, environ, timeout, w)
+	}
+	run, log, err := newHookRun(ctx, env, command, false)
+	if err != nil {
+		fmt.Fprintf(w, "warning: %v\n", err)
+		return runHook(ctx, command, dir, environ, timeout, w)
+	}
+	// The hook writes to the log file itself rather than to a pipe, so that
+	// processes it leaves running are not waited for and can keep writing.
+	stop := follow(log.Name(), w)
+	err = runHook(ctx, command, dir, environ, timeout, log)
+	stop()
+	_ = log.Close()
+	if ferr := run.finish(err); ferr != nil {
+		fmt.Fprintf(w, "warning: failed to record hook run: %v\n", ferr)
+	}
+	return err
+}
+
+// follow copies what is written to the file at path to w until the returned
+// function is called, which copies the rest and returns.
+func follow(path string, w io.Writer) func() {
+	f, err := os.Open(path)
+	if err != nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer f.Close()
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			_, _ = io.Copy(w, f)
+			select {
+			case <-done:
+				_, _ = io.Copy(w, f)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestHookRuns(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	wtPath := filepath.Join(repo.Root, ".wt", "logs")
+	if err := os.MkdirAll(wtPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	env := HookEnv{Event: HookEventCreate, Path: wtPath, Branch: "logs"}
+
+	var out bytes.Buffer
+	hooks := []string{"echo out; echo err >&2", "[warn] echo failing; exit 3", "[warn] sleep 5"}
+	if err := RunHooks(t.Context(), hooks, wtPath, env, time.Second, &out); err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "out\nerr\n") || !strings.Contains(out.String(), "failing\n") {
+		t.Errorf("hook output should still be written, got %q", out.String())
+	}
+
+	runs, err := HookRuns(t.Context(), wtPath)
+	if err != nil {
+		t.Fatalf("HookRuns failed: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 recorded runs, got %+v", runs)
+	}
+	tests := []struct {
+		hook     string
+		output   string
+		exitCode int
+		errMsg   string
+	}{
+		{hook: "echo out; echo err >&2", output: "out\nerr\n", exitCode: 0},
+		{hook: "echo failing; exit 3", output: "failing\n", exitCode: 3},
+		{hook: "sleep 5", exitCode: -1, errMsg: "timed out after 1s"},
+	}
+	for i, tt := range tests {
+		r := runs[i]
+		if r.Hook != tt.hook || r.Event != HookEventCreate || r.Path != wtPath || r.Background {
+			t.Errorf("run %d = %+v, want hook %q", i, r, tt.hook)
+		}
+		if r.Running() || r.EndedAt.Before(r.StartedAt) {
+			t.Errorf("run %d should have finished: %+v", i, r)
+		}
+		if r.ExitCode != tt.exitCode || r.Error != tt.errMsg {
+			t.Errorf("run %d: exit code %d, error %q, want %d, %q", i, r.ExitCode, r.Error, tt.exitCode, tt.errMsg)
+		}
+		b, err := os.ReadFile(r.Log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.output {
+			t.Errorf("run %d: log = %q, want %q", i, b, tt.output)
+		}
+	}
+
+	// Runs of other worktrees are kept apart.
+	if runs, err := HookRuns(t.Context(), filepath.Join(repo.Root, ".wt", "other")); err != nil || len(runs) != 0 {
+		t.Errorf("HookRuns(other) = %+v, %v, want nothing", runs, err)
+	}
+}
+
+func TestHookRunsFromDeletedCwd(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	// The hook runs after the current directory, a worktree, was deleted.
+	wtPath := filepath.Join(repo.Root, ".wt", "gone")
+	if err := os.MkdirAll(wtPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(wtPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(wtPath); err != nil {
+		t.Fatal(err)
+	}
+	env := HookEnv{Event: HookEventPostDelete, Path: wtPath, MainRoot: repo.Root}
+
+	var out bytes.Buffer
+	if err := RunHooks(t.Context(), []string{"echo post"}, repo.Root, env, 0, &out); err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	if out.String() != "post\n" {
+		t.Errorf("output = %q, want only the hook output", out.String())
+	}
+
+	if err := os.Chdir(repo.Root); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := HookRuns(t.Context(), wtPath)
+	if err != nil {
+		t.Fatalf("HookRuns failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Event != HookEventPostDelete || runs[0].Hook != "echo post" {
+		t.Errorf("runs = %+v, want the postdelete run", runs)
+	}
+}
+
+func TestPruneHookRuns(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20260101-000000.000-create-1", "20260102-000000.000-create-2", "20260103-000000.000-delete-3"} {
+		for _, ext := range []string{".log", ".json"} {
+			if err := os.WriteFile(filepath.Join(dir, name+ext), nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	pruneHookRuns(dir, 2)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := "20260102-000000.000-create-2.json 20260102-000000.000-create-2.log 20260103-000000.000-delete-3.json 20260103-000000.000-delete-3.log"
+	if strings.Join(got, " ") != want {
+		t.Errorf("remaining files = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHookEnv_Environ(t *testing.T) {
+	t.Setenv("GIT_WT_START_POINT", "inherited")
+
+	tests := []struct {
+		name string
+		env  HookEnv
+		want map[string]string
+	}{
+		{
+			name: "create",
+			env:  HookEnv{Event: HookEventCreate, Path: "/repo/.wt/x", Branch: "x", Name: "x", MainRoot: "/repo", SourcePath: "/repo", StartPoint: "main", NewBranch: true},
+			want: map[string]string{
+				"GIT_WT_EVENT":       "create",
+				"GIT_WT_PATH":        "/repo/.wt/x",
+				"GIT_WT_BRANCH":      "x",
+				"GIT_WT_NAME":        "x",
+				"GIT_WT_MAIN_ROOT":   "/repo",
+				"GIT_WT_SOURCE_PATH": "/repo",
+				"GIT_WT_START_POINT": "main",
+				"GIT_WT_NEW_BRANCH":  "1",
+			},
+		},
+		{
+			name: "create_existing_branch",
+			env:  HookEnv{Event: HookEventCreate, Path: "/repo/.wt/x", Branch: "x"},
+			want: map[string]string{
+				"GIT_WT_START_POINT": "",
+				"GIT_WT_NEW_BRANCH":  "0",
+			},
+		},
+		{
+			name: "delete",
+			env:  HookEnv{Event: HookEventDelete, Path: "/repo/.wt/x"},
+			want: map[string]string{
+				"GIT_WT_EVENT":       "delete",
+				"GIT_WT_SOURCE_PATH": "",
+				"GIT_WT_START_POINT": "",
+				"GIT_WT_NEW_BRANCH":  "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// As in exec.Cmd, the last value of a variable takes effect.
+			got := map[string]string{}
+			for _, kv := range tt.env.Environ() {
+				k, v, _ := strings.Cut(kv, "=")
+				got[k] = v
+			}
+			for k, want := range tt.want {
+				if v, ok := got[k]; !ok || v != want {
+					t.Errorf("%s = %q (set: %v), want %q", k, v, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHook(t *testing.T) {
+	tests := []struct {
+		hook        string
+		wantCommand string
+		wantPolicy  hookPolicy
+		wantErr     bool
+	}{
+		{hook: "npm install", wantCommand: "npm install"},
+		{hook: "[warn] npm install", wantCommand: "npm install", wantPolicy: hookPolicy{warn: true}},
+		{hook: "[retry=2] npm install", wantCommand: "npm install", wantPolicy: hookPolicy{retries: 2}},
+		{hook: " [retry=3, warn]npm ci", wantCommand: "npm ci", wantPolicy: hookPolicy{retries: 3, warn: true}},
+		{hook: "[warn,abort] make", wantCommand: "make"},
+		// Left to the shell
+		{hook: "[ -f .env ] || cp .env.example .env", wantCommand: "[ -f .env ] || cp .env.example .env"},
+		{hook: "[[ -f .env ]] || touch .env", wantCommand: "[[ -f .env ]] || touch .env"},
+		{hook: "[warn", wantCommand: "[warn"},
+		// Invalid policies
+		{hook: "[retry=x] make", wantErr: true},
+		{hook: "[retry=-1] make", wantErr: true},
+		{hook: "[ignore] make", wantErr: true},
+		{hook: "[warn] &make", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hook, func(t *testing.T) {
+			command, policy, err := parseHook(tt.hook)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseHook(%q) should fail, got %q, %+v", tt.hook, command, policy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHook(%q) failed: %v", tt.hook, err)
+			}
+			if command != tt.wantCommand || policy != tt.wantPolicy {
+				t.Errorf("parseHook(%q) = %q, %+v, want %q, %+v", tt.hook, command, policy, tt.wantCommand, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	t.Run("warn", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		err := RunHooks(t.Context(), []string{"[warn] exit 3", "touch next"}, dir, HookEnv{}, 0, &out)
+		if err != nil {
+			t.Fatalf("RunHooks should not fail for a hook that only warns: %v", err)
+		}
+		if !strings.Contains(out.String(), `warning: hook "exit 3" failed`) {
+			t.Errorf("output should contain a warning, got %q", out.String())
+		}
+		if _, err := os.Stat(filepath.Join(dir, "next")); err != nil {
+			t.Errorf("the next hook should run: %v", err)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		// Fails twice, then succeeds.
+		hook := "[retry=2] echo x >> count; test $(wc -l < count) -ge 3"
+		if err := RunHooks(t.Context(), []string{hook}, dir, HookEnv{}, 0, &out); err != nil {
+			t.Fatalf("RunHooks failed: %v\n%s", err, out.String())
+		}
+		if n := strings.Count(out.String(), "retrying"); n != 2 {
+			t.Errorf("expected 2 retries, got %d:\n%s", n, out.String())
+		}
+
+		if err := RunHooks(t.Context(), []string{"[retry=1] exit 1", "touch next"}, dir, HookEnv{}, 0, &out); err == nil {
+			t.Error("RunHooks should fail when all attempts fail")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "next")); err == nil {
+			t.Error("hooks after a failure should not run")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		// The child left running in the background is killed with the hook.
+		hook := "(sleep 2; touch escaped) & sleep 30"
+		start := time.Now()
+		err := RunHooks(t.Context(), []string{hook}, dir, HookEnv{}, 200*time.Millisecond, &out)
+		if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+			t.Fatalf("RunHooks should time out, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("RunHooks took %s despite the timeout", elapsed)
+		}
+		time.Sleep(2500 * time.Millisecond)
+		if _, err := os.Stat(filepath.Join(dir, "escaped")); err == nil {
+			t.Error("the process group of the hook should be killed")
+		}
+	})
+}
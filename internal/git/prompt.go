@@ -0,0 +1,184 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Prompt is the status of the current worktree shown by 'git wt --prompt'.
+// As a prompt runs before every command line, it is gathered from the files
+// under the git directory with a single git process; Dirty and OtherDirty
+// run 'git status' and are methods, so that prompt templates only pay for
+// them when they use them.
+type Prompt struct {
+	Name   string // worktree directory relative to basedir (directory name outside basedir, empty for the main working tree)
+	Branch string // branch, or the abbreviated HEAD commit if detached
+	Path   string // root of the worktree
+	Main   bool   // the main working tree
+
+	ctx    context.Context
+	others []string // roots of the other worktrees
+
+	dirtyOnce  sync.Once
+	dirty      bool
+	othersOnce sync.Once
+	otherDirty int
+}
+
+// LoadPrompt returns the prompt status of the current worktree with basedir
+// baseDir (as configured, e.g. ".wt"). It returns nil, without an error,
+// outside a working tree and in repositories without linked worktrees, where
+// a worktree prompt has nothing to tell.
+func LoadPrompt(ctx context.Context, baseDir string) (*Prompt, error) {
+	cmd, err := gitCommand(ctx, "rev-parse", "--path-format=absolute", "--git-dir", "--git-common-dir", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		// Not in a repository, or in a bare repository or .git directory.
+		return nil, nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 {
+		return nil, nil
+	}
+	gitDir, gitCommonDir, top := lines[0], lines[1], lines[2]
+
+	others, err := otherWorktreeRoots(gitCommonDir, top)
+	if err != nil {
+		return nil, err
+	}
+	p := &Prompt{
+		Path:   top,
+		Main:   gitDir == gitCommonDir,
+		ctx:    ctx,
+		others: others,
+	}
+	if p.Main && len(others) == 0 {
+		return nil, nil
+	}
+
+	p.Branch, err = headBranch(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Main {
+		p.Name = filepath.Base(top)
+		if base, err := expandBaseDirAt(baseDir, mainRootOf(gitCommonDir)); err == nil {
+			if rel, err := filepath.Rel(base, top); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				p.Name = filepath.ToSlash(rel)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Dirty reports whether the current worktree has modified or untracked files.
+// A worktree whose status cannot be determined is reported clean.
+func (p *Prompt) Dirty() bool {
+	p.dirtyOnce.Do(func() {
+		p.dirty, _ = hasChanges(p.ctx, p.Path)
+	})
+	return p.dirty
+}
+
+// OtherDirty returns the number of other worktrees with modified or untracked
+// files. Worktrees whose status cannot be determined are not counted.
+func (p *Prompt) OtherDirty() int {
+	p.othersOnce.Do(func() {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for _, path := range p.others {
+			wg.Go(func() {
+				if dirty, err := hasChanges(p.ctx, path); err == nil && dirty {
+					mu.Lock()
+					p.otherDirty++
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+	})
+	return p.otherDirty
+}
+
+// hasChanges reports whether the worktree at path has modified or untracked
+// files. Unlike GetWorktreeStatus, it does not enumerate untracked
+// directories, and it does not refresh the index, so that it neither slows
+// down nor interferes with git commands running concurrently.
+func hasChanges(ctx context.Context, path string) (bool, error) {
+	cmd, err := gitCommand(ctx, "status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return false, err
+	}
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	out, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// otherWorktreeRoots returns the roots of the worktrees of the repository
+// with git-common-dir gitCommonDir, other than current, from the worktree
+// administrative files. Worktrees whose directory is missing are left out.
+func otherWorktreeRoots(gitCommonDir, current string) ([]string, error) {
+	var roots []string
+	if filepath.Base(gitCommonDir) == ".git" {
+		// The main working tree; bare repositories have none.
+		roots = append(roots, filepath.Dir(gitCommonDir))
+	}
+	entries, err := os.ReadDir(filepath.Join(gitCommonDir, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read worktrees: %w", err)
+	}
+	for _, e := range entries {
+		// gitdir holds the path of the .git file in the worktree.
+		b, err := os.ReadFile(filepath.Join(gitCommonDir, "worktrees", e.Name(), "gitdir"))
+		if err != nil {
+			continue
+		}
+		dotGit := strings.TrimSpace(string(b))
+		if !filepath.IsAbs(dotGit) {
+			dotGit = filepath.Join(gitCommonDir, "worktrees", e.Name(), dotGit)
+		}
+		roots = append(roots, filepath.Dir(filepath.Clean(dotGit)))
+	}
+
+	others := roots[:0]
+	for _, root := range roots {
+		if SamePath(root, current) {
+			continue
+		}
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		others = append(others, root)
+	}
+	return others, nil
+}
+
+// headBranch returns the branch checked out in the worktree with git-dir
+// gitDir, or the abbreviated HEAD commit if HEAD is detached.
+func headBranch(gitDir string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	head := strings.TrimSpace(string(b))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/"), nil
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head, nil
+}
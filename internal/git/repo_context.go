@@ -123,10 +123,17 @@ func IsBareRoot(ctx context.Context) (bool, error) {
 // git-dir points to the .git directory (or worktrees/X subdirectory for linked worktrees).
 // git-common-dir points to the shared .git directory of the main repository.
 func gitDirs(ctx context.Context) (gitDir, gitCommonDir string, err error) {
+	return gitDirsIn(ctx, "")
+}
+
+// gitDirsIn is gitDirs for the repository at dir (the current directory if
+// empty).
+func gitDirsIn(ctx context.Context, dir string) (gitDir, gitCommonDir string, err error) {
 	cmd, err := gitCommand(ctx, "rev-parse", "--path-format=absolute", "--git-dir", "--git-common-dir")
 	if err != nil {
 		return "", "", err
 	}
+	cmd.Dir = dir
 	out, err := cmd.Output()
 	if err != nil {
 		return "", "", err
@@ -164,10 +171,16 @@ func MainRepoRoot(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return mainRootOf(gitCommonDir), nil
+}
+
+// mainRootOf returns the main repository root for git-common-dir
+// gitCommonDir, as MainRepoRoot does.
+func mainRootOf(gitCommonDir string) string {
 	if filepath.Base(gitCommonDir) == ".git" {
-		return filepath.Dir(gitCommonDir), nil
+		return filepath.Dir(gitCommonDir)
 	}
-	return gitCommonDir, nil
+	return gitCommonDir
 }
 
 // RepoName returns the name of the current git repository (directory name).
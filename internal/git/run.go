@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	osexec "os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/k1LoW/exec"
+)
+
+// RunCommand runs the command args in dir and returns its exit code. A single
+// argument is run as a shell command (like hooks), so that it may use pipes
+// and &&; several arguments are run as a program and its arguments. err is
+// set only if the command could not be run at all. As with hooks, the command
+// runs in its own process group without stdin, and the whole group is killed
+// when ctx is canceled.
+func RunCommand(ctx context.Context, dir string, args []string, stdout, stderr io.Writer) (int, error) {
+	var cmd *exec.Cmd
+	if len(args) == 1 {
+		cmd = exec.CommandContext(ctx, "sh", "-c", args[0])
+	} else {
+		cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+	}
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, err
+}
+
+// RunForegroundCommand runs the command args in dir like RunCommand, but in
+// the foreground: it inherits stdin and stays in the process group of git wt,
+// so that interactive commands can read from the terminal and receive Ctrl-C
+// themselves. Meanwhile, git wt ignores SIGINT and passes SIGTERM on. A
+// command killed by a signal exits with 128 plus the signal number, as in
+// shells.
+func RunForegroundCommand(ctx context.Context, dir string, args []string, stdout, stderr io.Writer) (int, error) {
+	var cmd *osexec.Cmd
+	if len(args) == 1 {
+		cmd = osexec.CommandContext(ctx, "sh", "-c", args[0])
+	} else {
+		cmd = osexec.CommandContext(ctx, args[0], args[1:]...) //#nosec G204
+	}
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGTERM {
+					_ = cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *osexec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+	return exitErr.ExitCode(), nil
+}
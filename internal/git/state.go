@@ -0,0 +1,142 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A state file is locked by creating "<name>.lock" next to it. Waiting for
+// the lock gives up after stateLockTimeout, and a lock older than
+// staleStateLock is taken over, as it was left by a git wt that was killed.
+const (
+	stateLockTimeout = 10 * time.Second
+	staleStateLock   = time.Minute
+)
+
+// StateDir returns the directory where git-wt keeps its own state (e.g., the
+// trash journal), shared by all worktrees of the repository. It is the "wt"
+// directory inside git-common-dir and is created if it does not exist.
+func StateDir(ctx context.Context) (string, error) {
+	return stateDirIn(ctx, "")
+}
+
+// stateDirIn is StateDir for the repository at repoDir (the current directory
+// if empty), e.g., the main working tree when the current worktree may have
+// been deleted.
+func stateDirIn(ctx context.Context, repoDir string) (string, error) {
+	_, gitCommonDir, err := gitDirsIn(ctx, repoDir)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(gitCommonDir, "wt")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return dir, nil
+}
+
+// readState decodes the JSON state file name in StateDir into v. A missing
+// file leaves v untouched.
+func readState(ctx context.Context, name string, v any) error {
+	dir, err := StateDir(ctx)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeState replaces the JSON state file name in StateDir with v atomically.
+func writeState(ctx context.Context, name string, v any) error {
+	dir, err := StateDir(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// updateState reads the JSON state file name in StateDir into v, calls fn to
+// change it, and writes v back unless fn fails. The file is locked meanwhile,
+// so that concurrent git wt processes do not lose each other's changes.
+func updateState(ctx context.Context, name string, v any, fn func() error) error {
+	unlock, err := lockState(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := readState(ctx, name, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return writeState(ctx, name, v)
+}
+
+// lockState takes the lock of the state file name in StateDir, waiting for
+// another git wt to release it, and returns the function releasing it.
+func lockState(ctx context.Context, name string) (func(), error) {
+	dir, err := StateDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lock := filepath.Join(dir, name+".lock")
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Join(dir, name), err)
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleStateLock {
+			_ = os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s (remove it if no git wt is running)", lock)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
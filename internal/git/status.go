@@ -0,0 +1,91 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// WorktreeStatus holds the working tree and upstream state of a worktree.
+type WorktreeStatus struct {
+	Modified  int    // tracked files with staged or unstaged changes (including conflicts)
+	Untracked int    // untracked files (not ignored)
+	Ahead     int    // commits on the branch that are not on its upstream
+	Behind    int    // commits on the upstream that are not on the branch
+	Upstream  string // upstream branch (e.g., origin/main), empty if not set
+}
+
+// Dirty reports whether the worktree has modified or untracked files.
+func (s *WorktreeStatus) Dirty() bool {
+	return s.Modified > 0 || s.Untracked > 0
+}
+
+// GetWorktreeStatus returns the status of the worktree at path.
+// It spawns a single 'git status --porcelain=v2 --branch' process.
+func GetWorktreeStatus(ctx context.Context, path string) (*WorktreeStatus, error) {
+	cmd, err := gitCommand(ctx, "status", "--porcelain=v2", "--branch", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = path
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseStatusPorcelainV2(string(out))
+}
+
+// parseStatusPorcelainV2 parses the output of 'git status --porcelain=v2 --branch'.
+func parseStatusPorcelainV2(out string) (*WorktreeStatus, error) {
+	st := &WorktreeStatus{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# branch.upstream "):
+			st.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// Format: "# branch.ab +<ahead> -<behind>"
+			if _, err := fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &st.Ahead, &st.Behind); err != nil {
+				return nil, fmt.Errorf("failed to parse ahead/behind from %q: %w", line, err)
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			st.Modified++
+		case strings.HasPrefix(line, "? "):
+			st.Untracked++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// WorktreeStatuses returns the status of each worktree, in the same order as
+// worktrees. Statuses are gathered concurrently so that listing stays fast
+// with many worktrees. Bare entries, and worktrees whose status cannot be
+// determined (e.g., the directory has been removed), get a nil status.
+func WorktreeStatuses(ctx context.Context, worktrees []Worktree) []*WorktreeStatus {
+	statuses := make([]*WorktreeStatus, len(worktrees))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		if wt.Bare {
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			st, err := GetWorktreeStatus(ctx, wt.Path)
+			if err != nil {
+				return
+			}
+			statuses[i] = st
+		})
+	}
+	wg.Wait()
+	return statuses
+}
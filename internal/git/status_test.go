@@ -0,0 +1,120 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestGetWorktreeStatus(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.CreateFile("a.txt", "a")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	st, err := GetWorktreeStatus(t.Context(), repo.Root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Dirty() {
+		t.Errorf("expected clean worktree, got %+v", st)
+	}
+	if st.Upstream != "" {
+		t.Errorf("expected no upstream, got %q", st.Upstream)
+	}
+
+	repo.CreateFile("README.md", "# Modified")
+	repo.CreateFile("a.txt", "staged")
+	repo.Git("add", "a.txt")
+	repo.CreateFile("untracked1.txt", "u")
+	repo.CreateFile("dir/untracked2.txt", "u")
+
+	st, err = GetWorktreeStatus(t.Context(), repo.Root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Modified != 2 {
+		t.Errorf("Modified = %d, want 2", st.Modified)
+	}
+	if st.Untracked != 2 {
+		t.Errorf("Untracked = %d, want 2", st.Untracked)
+	}
+	if !st.Dirty() {
+		t.Error("expected dirty worktree")
+	}
+}
+
+func TestGetWorktreeStatus_Upstream(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	// Use a bare clone as "origin" so the branch has an upstream
+	originDir := filepath.Join(repo.ParentDir(), "origin.git")
+	repo.Git("clone", "--bare", repo.Root, originDir)
+	repo.Git("remote", "add", "origin", originDir)
+	repo.Git("fetch", "origin")
+	repo.Git("branch", "--set-upstream-to=origin/main", "main")
+
+	repo.CreateFile("ahead1.txt", "1")
+	repo.Commit("ahead 1")
+	repo.CreateFile("ahead2.txt", "2")
+	repo.Commit("ahead 2")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	st, err := GetWorktreeStatus(t.Context(), repo.Root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Upstream != "origin/main" {
+		t.Errorf("Upstream = %q, want %q", st.Upstream, "origin/main")
+	}
+	if st.Ahead != 2 || st.Behind != 0 {
+		t.Errorf("Ahead/Behind = %d/%d, want 2/0", st.Ahead, st.Behind)
+	}
+}
+
+func TestWorktreeStatuses(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	wtPath := filepath.Join(repo.ParentDir(), "wt-feature")
+	repo.Git("worktree", "add", "-b", "feature", wtPath)
+	if err := os.WriteFile(filepath.Join(wtPath, "new.txt"), []byte("new"), 0600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	restore := repo.Chdir()
+	defer restore()
+
+	worktrees := []Worktree{
+		{Path: repo.Root, Branch: "main"},
+		{Path: wtPath, Branch: "feature"},
+		{Path: filepath.Join(repo.ParentDir(), "missing"), Branch: "missing"},
+		{Path: repo.Root, Bare: true},
+	}
+	statuses := WorktreeStatuses(t.Context(), worktrees)
+	if len(statuses) != len(worktrees) {
+		t.Fatalf("expected %d statuses, got %d", len(worktrees), len(statuses))
+	}
+	if statuses[0] == nil || statuses[0].Dirty() {
+		t.Errorf("main worktree should be clean, got %+v", statuses[0])
+	}
+	if statuses[1] == nil || statuses[1].Untracked != 1 {
+		t.Errorf("feature worktree should have 1 untracked file, got %+v", statuses[1])
+	}
+	if statuses[2] != nil {
+		t.Errorf("missing worktree should have nil status, got %+v", statuses[2])
+	}
+	if statuses[3] != nil {
+		t.Errorf("bare entry should have nil status, got %+v", statuses[3])
+	}
+}
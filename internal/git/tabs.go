@@ -0,0 +1,67 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// tabsFile is the name of the journal of multiplexer tabs inside StateDir.
+const tabsFile = "tabs.json"
+
+// tab is a multiplexer tab git wt opened for a worktree.
+type tab struct {
+	Session string `json:"session"`
+	Path    string `json:"path"` // worktree the tab was opened for
+	Name    string `json:"name"`
+}
+
+// TabJournal records the tabs git wt opens in multiplexers whose tabs cannot
+// be tagged with their worktree (zellij), so that only those are focused and
+// closed later, not tabs of the user that happen to have the same name.
+type TabJournal struct{}
+
+// Tab returns the name of the tab opened for the worktree at path in session,
+// or an empty string if there is none.
+func (TabJournal) Tab(ctx context.Context, session, path string) (string, error) {
+	var tabs []tab
+	if err := readState(ctx, tabsFile, &tabs); err != nil {
+		return "", err
+	}
+	path = filepath.Clean(path)
+	for _, t := range tabs {
+		if t.Session == session && t.Path == path {
+			return t.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// SetTab records the tab name opened for the worktree at path in session.
+func (TabJournal) SetTab(ctx context.Context, session, path, name string) error {
+	var tabs []tab
+	return updateState(ctx, tabsFile, &tabs, func() error {
+		tabs = append(forgetTab(tabs, session, path), tab{Session: session, Path: filepath.Clean(path), Name: name})
+		return nil
+	})
+}
+
+// DeleteTab forgets the tab opened for the worktree at path in session.
+func (TabJournal) DeleteTab(ctx context.Context, session, path string) error {
+	var tabs []tab
+	return updateState(ctx, tabsFile, &tabs, func() error {
+		tabs = forgetTab(tabs, session, path)
+		return nil
+	})
+}
+
+// forgetTab returns tabs without the one of the worktree at path in session.
+func forgetTab(tabs []tab, session, path string) []tab {
+	path = filepath.Clean(path)
+	kept := []tab{}
+	for _, t := range tabs {
+		if t.Session != session || t.Path != path {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestTabJournal(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	var j TabJournal
+	path := filepath.Join(repo.Root, ".wt", "feature")
+	if err := j.SetTab(t.Context(), "s1", path, "feature"); err != nil {
+		t.Fatalf("SetTab failed: %v", err)
+	}
+	if err := j.SetTab(t.Context(), "s2", path, "other"); err != nil {
+		t.Fatalf("SetTab failed: %v", err)
+	}
+
+	tests := []struct {
+		session string
+		path    string
+		want    string
+	}{
+		{"s1", path, "feature"},
+		{"s1", path + "/", "feature"},
+		{"s2", path, "other"},
+		{"s3", path, ""},
+		{"s1", filepath.Join(repo.Root, ".wt", "else"), ""},
+	}
+	for _, tt := range tests {
+		got, err := j.Tab(t.Context(), tt.session, tt.path)
+		if err != nil {
+			t.Fatalf("Tab failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Tab(%q, %q) = %q, want %q", tt.session, tt.path, got, tt.want)
+		}
+	}
+
+	if err := j.DeleteTab(t.Context(), "s1", path); err != nil {
+		t.Fatalf("DeleteTab failed: %v", err)
+	}
+	if got, _ := j.Tab(t.Context(), "s1", path); got != "" {
+		t.Errorf("Tab after DeleteTab = %q, want none", got)
+	}
+	if got, _ := j.Tab(t.Context(), "s2", path); got != "other" {
+		t.Errorf("tabs of other sessions should be kept, got %q", got)
+	}
+}
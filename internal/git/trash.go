@@ -0,0 +1,368 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// trashRefPrefix is the ref namespace that keeps the commits of deleted
+// worktrees reachable (and safe from gc) until they are restored or expired.
+const trashRefPrefix = "refs/wt-trash/"
+
+// trashJournalFile is the name of the trash journal inside StateDir.
+const trashJournalFile = "trash.json"
+
+// trashIdentity is the author and committer of the snapshot commits holding
+// uncommitted changes, so that snapshots work without a configured identity.
+var trashIdentity = []string{
+	"GIT_AUTHOR_NAME=git-wt",
+	"GIT_AUTHOR_EMAIL=git-wt@localhost",
+	"GIT_COMMITTER_NAME=git-wt",
+	"GIT_COMMITTER_EMAIL=git-wt@localhost",
+}
+
+// TrashEntry is a snapshot of a deleted worktree recorded in the trash journal.
+type TrashEntry struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Branch    string    `json:"branch,omitempty"`
+	Head      string    `json:"head"`
+	Changes   string    `json:"changes,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// HeadRef returns the ref that keeps the branch tip of the entry.
+func (e *TrashEntry) HeadRef() string {
+	return trashRefPrefix + e.ID + "/head"
+}
+
+// ChangesRef returns the ref that keeps the uncommitted changes of the entry.
+func (e *TrashEntry) ChangesRef() string {
+	return trashRefPrefix + e.ID + "/changes"
+}
+
+// TrashWorktree snapshots a worktree before it is deleted: its HEAD is kept
+// under refs/wt-trash/<id>/head and, if the worktree has modified or
+// untracked files, a commit of the working tree on top of HEAD is kept under
+// refs/wt-trash/<id>/changes. The entry is recorded in the trash journal.
+// name is the worktree name used to restore it (e.g., "feature").
+func TrashWorktree(ctx context.Context, wt *Worktree, name string) (*TrashEntry, error) {
+	head, err := revParse(ctx, wt.Path, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD of %q: %w", wt.Path, err)
+	}
+	changes, err := snapshotChanges(ctx, wt.Path, head, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry TrashEntry
+	err = updateTrash(ctx, func(entries *[]TrashEntry) error {
+		now := time.Now()
+		entry = TrashEntry{
+			ID:        newTrashID(*entries, now, head),
+			Name:      name,
+			Path:      wt.Path,
+			Head:      head,
+			Changes:   changes,
+			DeletedAt: now,
+		}
+		if wt.Branch != DetachedMarker {
+			entry.Branch = wt.Branch
+		}
+
+		if err := updateRef(ctx, entry.HeadRef(), head); err != nil {
+			return err
+		}
+		if changes != "" {
+			if err := updateRef(ctx, entry.ChangesRef(), changes); err != nil {
+				return err
+			}
+		}
+		*entries = append(*entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListTrash returns the trash entries, oldest first.
+func ListTrash(ctx context.Context) ([]TrashEntry, error) {
+	var entries []TrashEntry
+	if err := readState(ctx, trashJournalFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindTrashEntry returns the trash entry with the given ID or, failing that,
+// the most recently deleted entry whose name or branch matches query.
+// Returns nil if no entry matches.
+func FindTrashEntry(ctx context.Context, query string) (*TrashEntry, error) {
+	entries, err := ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == query {
+			return &entries[i], nil
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Name == query || entries[i].Branch == query {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RestoreTrashEntry recreates the worktree of a trash entry at its original
+// path: the branch is recreated at the saved tip (or reused if it still
+// points there), uncommitted changes are restored as unstaged changes, and
+// the entry is removed from the trash.
+func RestoreTrashEntry(ctx context.Context, e *TrashEntry) error {
+	if _, err := os.Stat(e.Path); err == nil {
+		return fmt.Errorf("path %q already exists", e.Path)
+	}
+
+	target := e.Head
+	if e.Branch != "" {
+		exists, err := LocalBranchExists(ctx, e.Branch)
+		if err != nil {
+			return err
+		}
+		if exists {
+			tip, err := revParse(ctx, "", "refs/heads/"+e.Branch)
+			if err != nil {
+				return err
+			}
+			if tip != e.Head {
+				return fmt.Errorf("branch %q already exists and points to a different commit; rename or delete it first", e.Branch)
+			}
+		} else {
+			cmd, err := gitCommand(ctx, "branch", e.Branch, e.Head)
+			if err != nil {
+				return err
+			}
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to recreate branch %q: %w: %s", e.Branch, err, strings.TrimSpace(string(out)))
+			}
+		}
+		target = e.Branch
+	}
+
+	if err := AddWorktree(ctx, e.Path, target, CopyOptions{}); err != nil {
+		return fmt.Errorf("failed to recreate worktree: %w", err)
+	}
+
+	if e.Changes != "" {
+		// Restore the working tree only, so that the index keeps matching
+		// HEAD and restored changes show up as unstaged.
+		cmd, err := gitCommand(ctx, "restore", "--source="+e.Changes, "--worktree", "--", ".")
+		if err != nil {
+			return err
+		}
+		cmd.Dir = e.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to restore uncommitted changes: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	return DropTrashEntry(ctx, e.ID)
+}
+
+// DropTrashEntry removes a trash entry and its refs.
+func DropTrashEntry(ctx context.Context, id string) error {
+	return updateTrash(ctx, func(entries *[]TrashEntry) error {
+		kept := (*entries)[:0]
+		for _, e := range *entries {
+			if e.ID != id {
+				kept = append(kept, e)
+				continue
+			}
+			if err := deleteRef(ctx, e.HeadRef()); err != nil {
+				return err
+			}
+			if e.Changes != "" {
+				if err := deleteRef(ctx, e.ChangesRef()); err != nil {
+					return err
+				}
+			}
+		}
+		*entries = kept
+		return nil
+	})
+}
+
+// ExpireTrash removes the trash entries deleted before the given time and
+// returns them.
+func ExpireTrash(ctx context.Context, before time.Time) ([]TrashEntry, error) {
+	entries, err := ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var expired []TrashEntry
+	for _, e := range entries {
+		if !e.DeletedAt.Before(before) {
+			continue
+		}
+		if err := DropTrashEntry(ctx, e.ID); err != nil {
+			return expired, err
+		}
+		expired = append(expired, e)
+	}
+	return expired, nil
+}
+
+// snapshotChanges records the working tree of dir (including untracked, but
+// not ignored, files) as a commit on top of head, using a temporary index so
+// that the worktree's own index is left untouched. The temporary index starts
+// as a copy of the worktree's index, so that git only hashes files that
+// changed since. It returns an empty string when the working tree matches
+// head.
+func snapshotChanges(ctx context.Context, dir, head, name string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "git-wt-trash-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	index := filepath.Join(tmpDir, "index")
+	seeded := copyIndex(ctx, dir, index) == nil
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+index)
+	run := func(args ...string) (string, error) {
+		cmd, err := gitCommand(ctx, args...)
+		if err != nil {
+			return "", err
+		}
+		cmd.Dir = dir
+		cmd.Env = env
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	if !seeded {
+		if _, err := run("read-tree", head); err != nil {
+			return "", fmt.Errorf("failed to snapshot %q: %w", dir, err)
+		}
+	}
+	if _, err := run("add", "-A"); err != nil {
+		return "", fmt.Errorf("failed to snapshot %q: %w", dir, err)
+	}
+	tree, err := run("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot %q: %w", dir, err)
+	}
+	headTree, err := revParse(ctx, dir, head+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if tree == headTree {
+		return "", nil
+	}
+
+	env = append(env, trashIdentity...)
+	commit, err := run("commit-tree", tree, "-p", head, "-m", fmt.Sprintf("git-wt: uncommitted changes of %s", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot %q: %w", dir, err)
+	}
+	return commit, nil
+}
+
+// copyIndex copies the index of the worktree at dir to dst.
+func copyIndex(ctx context.Context, dir, dst string) error {
+	cmd, err := gitCommand(ctx, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return err
+	}
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(strings.TrimSpace(string(out)))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0600)
+}
+
+// newTrashID returns a unique, ref-safe ID for a new trash entry.
+func newTrashID(entries []TrashEntry, now time.Time, head string) string {
+	base := now.UTC().Format("20060102T150405") + "-" + head[:min(7, len(head))]
+	id := base
+	for n := 2; ; n++ {
+		taken := false
+		for _, e := range entries {
+			if e.ID == id {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// updateTrash changes the trash journal with fn, holding its lock (see
+// updateState).
+func updateTrash(ctx context.Context, fn func(entries *[]TrashEntry) error) error {
+	var entries []TrashEntry
+	return updateState(ctx, trashJournalFile, &entries, func() error {
+		if err := fn(&entries); err != nil {
+			return err
+		}
+		if entries == nil {
+			entries = []TrashEntry{}
+		}
+		return nil
+	})
+}
+
+// revParse resolves rev to an object name, running in dir if not empty.
+func revParse(ctx context.Context, dir, rev string) (string, error) {
+	cmd, err := gitCommand(ctx, "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func updateRef(ctx context.Context, ref, value string) error {
+	cmd, err := gitCommand(ctx, "update-ref", ref, value)
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %w: %s", ref, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func deleteRef(ctx context.Context, ref string) error {
+	cmd, err := gitCommand(ctx, "update-ref", "-d", ref)
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete %s: %w: %s", ref, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
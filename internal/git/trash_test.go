@@ -0,0 +1,163 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/git-wt/testutil"
+)
+
+func TestTrashWorktree_Restore(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	wtPath := filepath.Join(repo.ParentDir(), "worktree-feature")
+	repo.Git("worktree", "add", "-b", "feature", wtPath)
+	if err := os.WriteFile(filepath.Join(wtPath, "README.md"), []byte("# Modified"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "new.txt"), []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "staged.txt"), []byte("staged"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	repo.Git("-C", wtPath, "add", "staged.txt")
+
+	restore := repo.Chdir()
+	defer restore()
+
+	wt, err := FindWorktreeByBranch(t.Context(), "feature")
+	if err != nil || wt == nil {
+		t.Fatalf("failed to find worktree: %v", err)
+	}
+	entry, err := TrashWorktree(t.Context(), wt, "feature")
+	if err != nil {
+		t.Fatalf("TrashWorktree failed: %v", err)
+	}
+	if entry.Branch != "feature" || entry.Path != wtPath || entry.Changes == "" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if got := repo.Git("rev-parse", entry.HeadRef()); got != entry.Head {
+		t.Errorf("%s = %q, want %q", entry.HeadRef(), got, entry.Head)
+	}
+
+	// The worktree's own index must be left untouched.
+	if got := repo.Git("-C", wtPath, "diff", "--cached", "--name-only"); got != "staged.txt" {
+		t.Errorf("snapshot should not change the index, got staged: %q", got)
+	}
+
+	repo.Git("worktree", "remove", "--force", wtPath)
+	repo.Git("branch", "-D", "feature")
+
+	found, err := FindTrashEntry(t.Context(), "feature")
+	if err != nil || found == nil {
+		t.Fatalf("FindTrashEntry failed: %v", err)
+	}
+	if err := RestoreTrashEntry(t.Context(), found); err != nil {
+		t.Fatalf("RestoreTrashEntry failed: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(wtPath, "README.md"))
+	if err != nil || string(b) != "# Modified" {
+		t.Errorf("modified file not restored: %q, %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(wtPath, "new.txt")); err != nil {
+		t.Errorf("untracked file not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wtPath, "staged.txt")); err != nil {
+		t.Errorf("staged file not restored: %v", err)
+	}
+	if got := repo.Git("-C", wtPath, "rev-parse", "--abbrev-ref", "HEAD"); got != "feature" {
+		t.Errorf("restored worktree is on %q, want feature", got)
+	}
+
+	entries, err := ListTrash(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("restored entry should be removed from the trash, got %+v", entries)
+	}
+	if refs := repo.Git("for-each-ref", trashRefPrefix); refs != "" {
+		t.Errorf("trash refs should be deleted, got %q", refs)
+	}
+}
+
+func TestTrashWorktree_Clean(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	wtPath := filepath.Join(repo.ParentDir(), "worktree-clean")
+	repo.Git("worktree", "add", "-b", "clean", wtPath)
+
+	restore := repo.Chdir()
+	defer restore()
+
+	wt, err := FindWorktreeByBranch(t.Context(), "clean")
+	if err != nil || wt == nil {
+		t.Fatalf("failed to find worktree: %v", err)
+	}
+	entry, err := TrashWorktree(t.Context(), wt, "clean")
+	if err != nil {
+		t.Fatalf("TrashWorktree failed: %v", err)
+	}
+	if entry.Changes != "" {
+		t.Errorf("clean worktree should have no changes snapshot, got %q", entry.Changes)
+	}
+
+	// Restoring refuses to overwrite an existing directory.
+	if err := RestoreTrashEntry(t.Context(), entry); err == nil {
+		t.Error("RestoreTrashEntry should fail while the worktree still exists")
+	}
+}
+
+func TestExpireTrash(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	for _, name := range []string{"a", "b"} {
+		repo.Git("worktree", "add", "-b", name, filepath.Join(repo.ParentDir(), name))
+	}
+
+	restore := repo.Chdir()
+	defer restore()
+
+	for _, name := range []string{"a", "b"} {
+		wt, err := FindWorktreeByBranch(t.Context(), name)
+		if err != nil || wt == nil {
+			t.Fatalf("failed to find worktree: %v", err)
+		}
+		if _, err := TrashWorktree(t.Context(), wt, name); err != nil {
+			t.Fatalf("TrashWorktree failed: %v", err)
+		}
+	}
+
+	expired, err := ExpireTrash(t.Context(), time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("recent entries should not expire, got %+v", expired)
+	}
+
+	expired, err = ExpireTrash(t.Context(), time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 2 {
+		t.Errorf("expected 2 expired entries, got %+v", expired)
+	}
+	entries, err := ListTrash(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("trash should be empty, got %+v", entries)
+	}
+}
@@ -18,10 +18,14 @@ const DetachedMarker = "[detached]"
 
 // Worktree represents a git worktree.
 type Worktree struct {
-	Path   string
-	Branch string
-	Head   string
-	Bare   bool
+	Path           string
+	Branch         string
+	Head           string
+	Bare           bool
+	Locked         bool
+	LockReason     string // empty if locked without a reason
+	Prunable       bool   // the worktree directory is missing and the entry can be pruned
+	PrunableReason string
 }
 
 // ListWorktrees returns a list of all worktrees.
@@ -68,6 +72,16 @@ func ListWorktrees(ctx context.Context) ([]Worktree, error) {
 			current.Bare = true
 		case line == "detached":
 			current.Branch = DetachedMarker
+		case line == "locked":
+			current.Locked = true
+		case strings.HasPrefix(line, "locked "):
+			current.Locked = true
+			current.LockReason = strings.TrimPrefix(line, "locked ")
+		case line == "prunable":
+			current.Prunable = true
+		case strings.HasPrefix(line, "prunable "):
+			current.Prunable = true
+			current.PrunableReason = strings.TrimPrefix(line, "prunable ")
 		}
 	}
 
@@ -253,6 +267,18 @@ func IsBareEntry(ctx context.Context, query string) (bool, error) {
 	return false, nil
 }
 
+// SamePath reports whether a and b refer to the same directory, resolving
+// symlinks (e.g., macOS /var vs /private/var) when possible.
+func SamePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
 // WorktreeDirName returns the directory name of a worktree (relative path from base dir).
 func WorktreeDirName(ctx context.Context, wt *Worktree) (string, error) {
 	cfg, err := LoadConfig(ctx)
@@ -418,10 +444,12 @@ func initBaseDir(baseDir string) error {
 }
 
 // RunRemover executes a custom remover command to remove a worktree directory.
-// The worktree path is passed safely as a positional argument via sh -c.
-func RunRemover(ctx context.Context, remover string, wtPath string, dir string, w io.Writer) error {
+// The worktree path is passed safely as a positional argument via sh -c, and
+// env is exported as for hooks.
+func RunRemover(ctx context.Context, remover string, wtPath string, dir string, env HookEnv, w io.Writer) error {
 	cmd := exec.CommandContext(ctx, "sh", "-c", remover+` "$1"`, "--", wtPath)
 	cmd.Dir = dir
+	cmd.Env = env.Environ()
 	cmd.Stdout = w
 	cmd.Stderr = w
 	if err := cmd.Run(); err != nil {
@@ -460,8 +488,8 @@ func RemoveWorktree(ctx context.Context, path string, force bool) error {
 
 // MoveWorktree moves a worktree directory from oldPath to newPath using
 // 'git worktree move'. The parent directory of newPath is created if needed.
-// If force is true, '--force' is passed to allow moving worktrees with
-// uncommitted or untracked changes.
+// If force is true, '--force' is passed twice to allow moving worktrees with
+// uncommitted or untracked changes as well as locked worktrees.
 func MoveWorktree(ctx context.Context, oldPath, newPath string, force bool) error {
 	parentDir := filepath.Dir(newPath)
 	if err := os.MkdirAll(parentDir, 0755); err != nil {
@@ -473,7 +501,8 @@ func MoveWorktree(ctx context.Context, oldPath, newPath string, force bool) erro
 
 	args := []string{"worktree", "move"}
 	if force {
-		args = append(args, "--force")
+		// A single --force is not enough for locked worktrees.
+		args = append(args, "--force", "--force")
 	}
 	args = append(args, oldPath, newPath)
 
@@ -70,6 +70,52 @@ func TestListWorktrees_Multiple(t *testing.T) {
 	}
 }
 
+func TestListWorktrees_LockedAndPrunable(t *testing.T) {
+	repo := testutil.NewTestRepo(t)
+	repo.CreateFile("README.md", "# Test")
+	repo.Commit("initial commit")
+
+	lockedPath := filepath.Join(repo.ParentDir(), "wt-locked")
+	repo.Git("worktree", "add", "-b", "locked", lockedPath)
+	repo.Git("worktree", "lock", "--reason", "on removable drive", lockedPath)
+
+	noReasonPath := filepath.Join(repo.ParentDir(), "wt-locked-noreason")
+	repo.Git("worktree", "add", "-b", "locked-noreason", noReasonPath)
+	repo.Git("worktree", "lock", noReasonPath)
+
+	prunablePath := filepath.Join(repo.ParentDir(), "wt-prunable")
+	repo.Git("worktree", "add", "-b", "prunable", prunablePath)
+	if err := os.RemoveAll(prunablePath); err != nil {
+		t.Fatalf("failed to remove worktree directory: %v", err)
+	}
+
+	restore := repo.Chdir()
+	defer restore()
+
+	worktrees, err := ListWorktrees(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byBranch := make(map[string]Worktree)
+	for _, wt := range worktrees {
+		byBranch[wt.Branch] = wt
+	}
+
+	if wt := byBranch["main"]; wt.Locked || wt.Prunable {
+		t.Errorf("main worktree should be neither locked nor prunable, got %+v", wt)
+	}
+	if wt := byBranch["locked"]; !wt.Locked || wt.LockReason != "on removable drive" {
+		t.Errorf("expected locked worktree with reason, got %+v", wt)
+	}
+	if wt := byBranch["locked-noreason"]; !wt.Locked || wt.LockReason != "" {
+		t.Errorf("expected locked worktree without reason, got %+v", wt)
+	}
+	if wt := byBranch["prunable"]; !wt.Prunable || wt.PrunableReason == "" {
+		t.Errorf("expected prunable worktree with reason, got %+v", wt)
+	}
+}
+
 func TestCurrentWorktree(t *testing.T) {
 	repo := testutil.NewTestRepo(t)
 	repo.CreateFile("README.md", "# Test")
@@ -0,0 +1,72 @@
+// Package multiplexer opens worktrees in windows of the terminal multiplexer
+// (tmux or zellij) git wt runs in.
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/k1LoW/exec"
+)
+
+// Multiplexer opens worktrees in windows (tmux) or tabs (zellij) of the
+// current session.
+type Multiplexer interface {
+	// Name returns the name of the multiplexer, e.g., "tmux".
+	Name() string
+	// Open focuses the window of the worktree at path, or creates one named
+	// name and started in dir. It reports whether a window was created.
+	Open(ctx context.Context, name, path, dir string) (bool, error)
+	// Close closes the windows git wt opened for the worktree at path (named
+	// name). It reports whether there were any.
+	Close(ctx context.Context, name, path string) (bool, error)
+}
+
+// TabStore records the tabs git wt opened, by session and worktree path, for
+// multiplexers whose tabs cannot be tagged with their worktree (zellij).
+type TabStore interface {
+	// Tab returns the name of the tab opened for the worktree at path in
+	// session, or an empty string if there is none.
+	Tab(ctx context.Context, session, path string) (string, error)
+	SetTab(ctx context.Context, session, path, name string) error
+	DeleteTab(ctx context.Context, session, path string) error
+}
+
+// Supported returns the names accepted by New.
+func Supported() []string {
+	return []string{"tmux", "zellij"}
+}
+
+// New returns the multiplexer called name, which keeps track of its tabs in
+// tabs if needed. ok is false if git wt is not running inside a session of it
+// (or name is empty or "none").
+func New(name string, tabs TabStore) (m Multiplexer, ok bool, err error) {
+	switch name {
+	case "", "none":
+		return nil, false, nil
+	case "tmux":
+		return tmux{}, os.Getenv("TMUX") != "", nil
+	case "zellij":
+		return zellij{tabs: tabs, session: os.Getenv("ZELLIJ_SESSION_NAME")}, os.Getenv("ZELLIJ") != "", nil
+	default:
+		return nil, false, fmt.Errorf("unsupported multiplexer %q (supported: %s)", name, strings.Join(Supported(), ", "))
+	}
+}
+
+// run runs a multiplexer command and returns its trimmed stdout. Errors
+// include the command's stderr.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,66 @@
+package multiplexer
+
+import (
+	"context"
+	"strings"
+)
+
+// tmuxPathOption is the window option holding the path of the worktree a
+// window was opened for. Windows are found by it rather than by name, as
+// windows can be renamed.
+const tmuxPathOption = "@git-wt-path"
+
+type tmux struct{}
+
+func (tmux) Name() string {
+	return "tmux"
+}
+
+func (t tmux) Open(ctx context.Context, name, path, dir string) (bool, error) {
+	ids, err := t.windows(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) > 0 {
+		_, err := run(ctx, "tmux", "select-window", "-t", ids[0])
+		return false, err
+	}
+	id, err := run(ctx, "tmux", "new-window", "-P", "-F", "#{window_id}", "-n", name, "-c", dir)
+	if err != nil {
+		return false, err
+	}
+	if _, err := run(ctx, "tmux", "set-option", "-w", "-t", id, tmuxPathOption, path); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+func (t tmux) Close(ctx context.Context, _, path string) (bool, error) {
+	ids, err := t.windows(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if _, err := run(ctx, "tmux", "kill-window", "-t", id); err != nil {
+			return true, err
+		}
+	}
+	return len(ids) > 0, nil
+}
+
+// windows returns the IDs of the windows of the current session that were
+// opened for the worktree at path.
+func (tmux) windows(ctx context.Context, path string) ([]string, error) {
+	out, err := run(ctx, "tmux", "list-windows", "-F", "#{window_id}\t#{"+tmuxPathOption+"}")
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, line := range strings.Split(out, "\n") {
+		id, p, ok := strings.Cut(line, "\t")
+		if ok && p == path {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
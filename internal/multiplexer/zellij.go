@@ -0,0 +1,84 @@
+package multiplexer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// zellij has no per-tab metadata, so the tabs git wt opens are recorded in a
+// TabStore and found by name. Tabs of the user that have the same name are
+// left alone, and so are tabs git wt opened that were renamed since.
+type zellij struct {
+	tabs    TabStore
+	session string // ZELLIJ_SESSION_NAME
+}
+
+func (zellij) Name() string {
+	return "zellij"
+}
+
+func (z zellij) Open(ctx context.Context, name, path, dir string) (bool, error) {
+	recorded, err := z.tabs.Tab(ctx, z.session, path)
+	if err != nil {
+		return false, err
+	}
+	if recorded != "" {
+		n, err := z.countTabs(ctx, recorded)
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			_, err := run(ctx, "zellij", "action", "go-to-tab-name", recorded)
+			return false, err
+		}
+	}
+	if _, err := run(ctx, "zellij", "action", "new-tab", "--name", name, "--cwd", dir); err != nil {
+		return false, err
+	}
+	if err := z.tabs.SetTab(ctx, z.session, path, name); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+func (z zellij) Close(ctx context.Context, _, path string) (bool, error) {
+	recorded, err := z.tabs.Tab(ctx, z.session, path)
+	if err != nil || recorded == "" {
+		return false, err
+	}
+	// Forget the tab first, as closing the tab git wt runs in ends it.
+	if err := z.tabs.DeleteTab(ctx, z.session, path); err != nil {
+		return false, err
+	}
+	n, err := z.countTabs(ctx, recorded)
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if n > 1 {
+		return false, fmt.Errorf("%d tabs are named %q, not closing any", n, recorded)
+	}
+	// close-tab closes the focused tab.
+	if _, err := run(ctx, "zellij", "action", "go-to-tab-name", recorded); err != nil {
+		return false, err
+	}
+	if _, err := run(ctx, "zellij", "action", "close-tab"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// countTabs returns the number of tabs of the current session named name.
+func (zellij) countTabs(ctx context.Context, name string) (int, error) {
+	out, err := run(ctx, "zellij", "action", "query-tab-names")
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, tab := range strings.Split(out, "\n") {
+		if tab == name {
+			n++
+		}
+	}
+	return n, nil
+}
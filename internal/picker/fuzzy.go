@@ -0,0 +1,100 @@
+package picker
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Match reports whether the runes of query appear in text in order, and
+// scores the match (higher is better). Matching is case-insensitive unless
+// query contains an upper-case letter. Consecutive runes and runes at the
+// start of a word (after '/', '-', '_', '.' or a space) score higher, so
+// "fb" prefers "feat/bar" over "fooba".
+func Match(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	orig := []rune(text)
+	q := []rune(query)
+	t := slices.Clone(orig)
+	if !slices.ContainsFunc(q, unicode.IsUpper) {
+		for i := range q {
+			q[i] = unicode.ToLower(q[i])
+		}
+		for i := range t {
+			t[i] = unicode.ToLower(t[i])
+		}
+	}
+
+	best, found := 0, false
+	for start := range t {
+		if t[start] != q[0] {
+			continue
+		}
+		score, ok := matchFrom(q, t, orig, start)
+		if ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// matchFrom greedily matches q against t starting at t[start] == q[0].
+// orig is the text before case folding, used to find word boundaries.
+func matchFrom(q, t, orig []rune, start int) (int, bool) {
+	score := 0
+	prev := -1
+	qi := 0
+	for ti := start; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		switch {
+		case ti == 0 || isSeparator(orig[ti-1]):
+			score += 8
+		case unicode.IsUpper(orig[ti]) && unicode.IsLower(orig[ti-1]):
+			score += 6 // camelCase boundary
+		}
+		if prev >= 0 {
+			if ti == prev+1 {
+				score += 5
+			} else {
+				score -= min(ti-prev-1, 3)
+			}
+		}
+		prev = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func isSeparator(r rune) bool {
+	switch r {
+	case '/', '-', '_', '.', ' ':
+		return true
+	}
+	return false
+}
+
+// Filter returns the indices of the items matching query, best match first.
+// Items with the same score keep their original order.
+func Filter(query string, items []Item) []int {
+	type scored struct {
+		index, score int
+	}
+	var matches []scored
+	for i, item := range items {
+		if score, ok := Match(query, item.Text); ok {
+			matches = append(matches, scored{i, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		return b.score - a.score
+	})
+	indices := make([]int, len(matches))
+	for i, m := range matches {
+		indices[i] = m.index
+	}
+	return indices
+}
@@ -0,0 +1,239 @@
+// Package picker implements a small interactive fuzzy finder for the
+// terminal, used by 'git wt -i' to choose a worktree or branch.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Item is an entry of the picker.
+type Item struct {
+	Text string // matched against the query
+	Desc string // shown dimmed after Text
+}
+
+// ErrCanceled is returned by Pick when the user cancels the selection
+// (Esc, Ctrl-C, Ctrl-G, or Ctrl-D on an empty query).
+var ErrCanceled = errors.New("selection canceled")
+
+const (
+	keyRune = iota
+	keyEnter
+	keyCancel
+	keyEOF // Ctrl-D: cancels on an empty query
+	keyUp
+	keyDown
+	keyBackspace
+	keyClearLine
+	keyDeleteWord
+)
+
+type key struct {
+	kind int
+	r    rune
+}
+
+// Pick lets the user choose one of items with a fuzzy query and returns its
+// index. Keys are read from the terminal in and the picker is drawn on the
+// alternate screen of the terminal out, so stdout stays free for the result.
+// Both terminals are restored before Pick returns.
+func Pick(in, out *os.File, prompt string, items []Item) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("nothing to pick from")
+	}
+	restore, err := makeRaw(in, out)
+	if err != nil {
+		return -1, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer restore()
+
+	width, height, err := termSize(out)
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	fmt.Fprint(out, "\x1b[?1049h")
+	defer fmt.Fprint(out, "\x1b[?1049l")
+	return run(in, out, prompt, items, width, height)
+}
+
+// run is the event loop of Pick on an already prepared terminal of the
+// given size.
+func run(r io.Reader, w io.Writer, prompt string, items []Item, width, height int) (int, error) {
+	var (
+		query   []rune
+		matches = Filter("", items)
+		cursor  int // index into matches
+		offset  int // first visible match
+		rows    = max(height-2, 1)
+		buf     = make([]byte, 256)
+	)
+	for {
+		if cursor < offset {
+			offset = cursor
+		} else if cursor >= offset+rows {
+			offset = cursor - rows + 1
+		}
+		render(w, prompt, string(query), items, matches, cursor, offset, rows, width)
+
+		n, err := r.Read(buf)
+		if n == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				return -1, ErrCanceled
+			}
+			return -1, err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			switch k.kind {
+			case keyEnter:
+				if len(matches) > 0 {
+					return matches[cursor], nil
+				}
+				continue
+			case keyCancel:
+				return -1, ErrCanceled
+			case keyEOF:
+				if len(query) == 0 {
+					return -1, ErrCanceled
+				}
+				continue
+			case keyUp:
+				if cursor > 0 {
+					cursor--
+				}
+				continue
+			case keyDown:
+				if cursor < len(matches)-1 {
+					cursor++
+				}
+				continue
+			case keyBackspace:
+				if len(query) > 0 {
+					query = query[:len(query)-1]
+				}
+			case keyClearLine:
+				query = query[:0]
+			case keyDeleteWord:
+				for len(query) > 0 && query[len(query)-1] == ' ' {
+					query = query[:len(query)-1]
+				}
+				for len(query) > 0 && query[len(query)-1] != ' ' {
+					query = query[:len(query)-1]
+				}
+			case keyRune:
+				query = append(query, k.r)
+			}
+			matches = Filter(string(query), items)
+			cursor, offset = 0, 0
+		}
+	}
+}
+
+// parseKeys decodes the bytes of one read from a raw terminal into keys.
+// A lone Esc at the end of a read is treated as a cancel; unknown escape
+// sequences are ignored.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				return append(keys, key{kind: keyCancel})
+			}
+			n := escapeLen(b)
+			switch string(b[:n]) {
+			case "\x1b[A", "\x1bOA":
+				keys = append(keys, key{kind: keyUp})
+			case "\x1b[B", "\x1bOB":
+				keys = append(keys, key{kind: keyDown})
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{kind: keyEnter})
+		case c == 0x03 || c == 0x07: // Ctrl-C, Ctrl-G
+			keys = append(keys, key{kind: keyCancel})
+		case c == 0x04: // Ctrl-D
+			keys = append(keys, key{kind: keyEOF})
+		case c == 0x10 || c == 0x0b: // Ctrl-P, Ctrl-K
+			keys = append(keys, key{kind: keyUp})
+		case c == 0x0e: // Ctrl-N
+			keys = append(keys, key{kind: keyDown})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+		case c == 0x15: // Ctrl-U
+			keys = append(keys, key{kind: keyClearLine})
+		case c == 0x17: // Ctrl-W
+			keys = append(keys, key{kind: keyDeleteWord})
+		case c < 0x20:
+			// Other control characters are ignored.
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				keys = append(keys, key{kind: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLen returns the length of the escape sequence at the start of b,
+// which starts with Esc and has at least two bytes.
+func escapeLen(b []byte) int {
+	switch b[1] {
+	case '[':
+		// CSI: parameters and intermediates, then a final byte in 0x40-0x7e.
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(b)
+	case 'O':
+		return min(3, len(b))
+	}
+	return 1 // Esc followed by something else: drop the Esc only
+}
+
+func render(w io.Writer, prompt, query string, items []Item, matches []int, cursor, offset, rows, width int) {
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+	sb.WriteString(truncate(prompt+query, width))
+	sb.WriteString("\x1b[K\r\n")
+	fmt.Fprintf(&sb, "\x1b[2m  %d/%d\x1b[0m\x1b[K\r\n", len(matches), len(items))
+	for i := offset; i < len(matches) && i < offset+rows; i++ {
+		item := items[matches[i]]
+		marker := "  "
+		if i == cursor {
+			marker = "\x1b[1m> "
+		}
+		line := truncate(item.Text, width-2)
+		sb.WriteString(marker + line + "\x1b[0m")
+		if rest := width - 2 - utf8.RuneCountInString(line) - 2; item.Desc != "" && rest > 0 {
+			sb.WriteString("  \x1b[2m" + truncate(item.Desc, rest) + "\x1b[0m")
+		}
+		sb.WriteString("\x1b[K\r\n")
+	}
+	sb.WriteString("\x1b[J")
+	fmt.Fprintf(&sb, "\x1b[1;%dH", min(utf8.RuneCountInString(prompt+query), width-1)+1)
+	_, _ = io.WriteString(w, sb.String())
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
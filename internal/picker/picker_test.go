@@ -0,0 +1,100 @@
+package picker
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  bool
+	}{
+		{"", "anything", true},
+		{"fb", "feat/bar", true},
+		{"fb", "fooba", true},
+		{"FB", "feat/bar", false},
+		{"FB", "Feat/Bar", true},
+		{"bf", "feat/bar", false},
+		{"feature", "feat", false},
+	}
+	for _, tt := range tests {
+		if _, got := Match(tt.query, tt.text); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []Item{{Text: "fooba"}, {Text: "main"}, {Text: "feat/bar"}, {Text: "fix-bug"}}
+	got := Filter("fb", items)
+	// Word starts win over scattered matches; ties keep the original order.
+	want := []int{2, 3, 0}
+	if len(got) != len(want) {
+		t.Fatalf("Filter = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Filter = %v, want %v", got, want)
+		}
+	}
+
+	if got := Filter("", items); len(got) != len(items) || got[0] != 0 {
+		t.Errorf("empty query should keep all items in order, got %v", got)
+	}
+}
+
+// keyReader returns one chunk per Read, like a terminal delivering key
+// presses one at a time.
+type keyReader struct {
+	chunks []string
+}
+
+func (r *keyReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestRun(t *testing.T) {
+	items := []Item{{Text: "main"}, {Text: "feature", Desc: "[worktree]"}, {Text: "fix"}}
+	tests := []struct {
+		name    string
+		keys    []string
+		want    int
+		wantErr error
+	}{
+		{"enter selects first", []string{"\r"}, 0, nil},
+		{"query", []string{"f", "e", "\r"}, 1, nil},
+		{"arrow keys", []string{"\x1b[B", "\x1b[B", "\x1b[A", "\r"}, 1, nil},
+		{"ctrl-n and ctrl-p", []string{"\x0e\x0e\x10\r"}, 1, nil},
+		{"backspace", []string{"fe", "\x7f\x7f", "fi", "\r"}, 2, nil},
+		{"ctrl-u clears query", []string{"xyz\x15", "\r"}, 0, nil},
+		{"enter without matches is ignored", []string{"xyz", "\r", "\x15\r"}, 0, nil},
+		{"esc cancels", []string{"\x1b"}, -1, ErrCanceled},
+		{"ctrl-c cancels", []string{"fe\x03"}, -1, ErrCanceled},
+		{"ctrl-d cancels on empty query", []string{"\x04"}, -1, ErrCanceled},
+		{"eof cancels", nil, -1, ErrCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := run(&keyReader{chunks: tt.keys}, &out, "> ", items, 40, 10)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("run() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("run() = %d, want %d", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "feature") {
+				t.Errorf("items should be drawn, got %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !windows
+
+package picker
+
+import (
+	"errors"
+	"os"
+)
+
+func makeRaw(_, _ *os.File) (func(), error) {
+	return nil, errors.New("interactive mode is not supported on this platform")
+}
+
+func termSize(_ *os.File) (int, int, error) {
+	return 0, 0, errors.New("interactive mode is not supported on this platform")
+}
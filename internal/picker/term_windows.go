@@ -0,0 +1,43 @@
+package picker
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// makeRaw disables echo and line input on the console in, enables virtual
+// terminal sequences on both consoles and returns a function restoring
+// their previous modes.
+func makeRaw(in, out *os.File) (func(), error) {
+	hin := windows.Handle(in.Fd())
+	hout := windows.Handle(out.Fd())
+	var inMode, outMode uint32
+	if err := windows.GetConsoleMode(hin, &inMode); err != nil {
+		return nil, err
+	}
+	if err := windows.GetConsoleMode(hout, &outMode); err != nil {
+		return nil, err
+	}
+	raw := inMode&^(windows.ENABLE_ECHO_INPUT|windows.ENABLE_PROCESSED_INPUT|windows.ENABLE_LINE_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(hin, raw); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(hout, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		_ = windows.SetConsoleMode(hin, inMode)
+		return nil, err
+	}
+	return func() {
+		_ = windows.SetConsoleMode(hin, inMode)
+		_ = windows.SetConsoleMode(hout, outMode)
+	}, nil
+}
+
+// termSize returns the width and height of the console window out.
+func termSize(out *os.File) (int, int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(out.Fd()), &info); err != nil {
+		return 0, 0, err
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, nil
+}